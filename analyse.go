@@ -0,0 +1,140 @@
+package syn
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/dlclark/regexp2"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+// Analyse holds the patterns that text in a lexer's language is likely to match, so that the lexer can be found
+// for text whose filename is unknown or doesn't match, such as text read from standard input. It is defined by the
+// analyse element of a lexer definition, as in Chroma:
+//
+//	<analyse first="true">
+//	  <regex pattern="^diff --git " score="1.0"/>
+//	</analyse>
+type Analyse struct {
+	Regexes []AnalyseRegex `json:"regexes"`
+	// First makes the score of text that of the first of Regexes it matches, rather than the sum of the scores of
+	// all of those it matches.
+	First bool `json:"first,omitempty"`
+}
+
+// AnalyseRegex is a pattern of an Analyse and the score, from 0 to 1, given to text that matches it. The pattern
+// is compiled without options, so it must use (?m) for ^ and $ to match at the start and end of each line.
+type AnalyseRegex struct {
+	Pattern string  `json:"pattern"`
+	Score   float32 `json:"score"`
+}
+
+func analyseFromConfig(a *config.Analyse) *Analyse {
+	if a == nil {
+		return nil
+	}
+	out := &Analyse{First: a.First}
+	for _, r := range a.Regexes {
+		out.Regexes = append(out.Regexes, AnalyseRegex{Pattern: r.Pattern, Score: r.Score})
+	}
+	return out
+}
+
+func (a *Analyse) toConfig() *config.Analyse {
+	if a == nil {
+		return nil
+	}
+	out := &config.Analyse{First: a.First}
+	for _, r := range a.Regexes {
+		out.Regexes = append(out.Regexes, config.AnalyseRegex{Pattern: r.Pattern, Score: r.Score})
+	}
+	return out
+}
+
+// compileAnalyse compiles the patterns of a, each abandoning a match that takes longer than timeout.
+func compileAnalyse(a *config.Analyse, timeout time.Duration) ([]*regexp2.Regexp, error) {
+	if a == nil {
+		return nil, nil
+	}
+	res := make([]*regexp2.Regexp, len(a.Regexes))
+	for i, r := range a.Regexes {
+		re, compileErr := regexp2.Compile(r.Pattern, regexp2.None)
+		if compileErr != nil {
+			return nil, fmt.Errorf("analyse pattern %d: %w", i, compileErr)
+		}
+		re.MatchTimeout = timeout
+		res[i] = re
+	}
+	return res, nil
+}
+
+// analyseRegexps caches the compiled patterns of the Analyse of lexers that weren't built from a definition,
+// such as the placeholders registered by RegisterLazy, by pattern. A pattern that doesn't compile is stored as
+// nil.
+var analyseRegexps sync.Map
+
+// analyseRegexp returns the compiled pattern i of the lexer's Analyse, or nil if it doesn't compile.
+func (l *Lexer) analyseRegexp(i int, pattern string) *regexp2.Regexp {
+	if i < len(l.analyse) {
+		return l.analyse[i]
+	}
+	if re, ok := analyseRegexps.Load(pattern); ok {
+		return re.(*regexp2.Regexp)
+	}
+	re, compileErr := regexp2.Compile(pattern, regexp2.None)
+	if compileErr != nil {
+		re = nil
+	} else {
+		re.MatchTimeout = DefaultMatchTimeout
+	}
+	analyseRegexps.Store(pattern, re)
+	return re
+}
+
+// AnalyseText returns how likely it is that text is in the lexer's language, from 0 to 1, using the patterns of
+// its Analyse. It returns 0 for a lexer without an Analyse. A pattern that takes longer than the lexer's match
+// timeout to match is taken not to match.
+func (l *Lexer) AnalyseText(text string) float32 {
+	a := l.cfg().Config.Analyse
+	if a == nil {
+		return 0
+	}
+	var score float32
+	for i, r := range a.Regexes {
+		re := l.analyseRegexp(i, r.Pattern)
+		if re == nil {
+			continue
+		}
+		if matched, matchErr := re.MatchString(text); matchErr != nil || !matched {
+			continue
+		}
+		if a.First {
+			return float32(math.Min(float64(r.Score), 1))
+		}
+		score += r.Score
+	}
+	return float32(math.Min(float64(score), 1))
+}
+
+// Analyse returns the lexer that gives text the highest score with Lexer.AnalyseText, choosing by priority
+// between lexers that give the same score, or nil if none gives it a score above 0. It is used to find a lexer
+// for text that Match finds none for.
+func (l *LexerRegistry) Analyse(text string) *Lexer {
+	l.mu.RLock()
+	var best *Lexer
+	var bestScore float32
+	for _, lexer := range l.Lexers {
+		score := lexer.AnalyseText(text)
+		if score <= 0 {
+			continue
+		}
+		if score > bestScore || score == bestScore && prioritisedLexers([]*Lexer{lexer, best}).Less(0, 1) {
+			best, bestScore = lexer, score
+		}
+	}
+	l.mu.RUnlock()
+	return l.load(best)
+}
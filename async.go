@@ -0,0 +1,59 @@
+package syn
+
+import (
+	"context"
+	"fmt"
+)
+
+// AsyncToken is a value delivered by the channel returned from Async. Either Token is set, or, for the
+// last value sent before the channel is closed, Err may be set if tokenizing failed.
+type AsyncToken struct {
+	Token Token
+	Err   error
+}
+
+// Async drives the Iterator it on a new goroutine and delivers the tokens it produces over a channel with
+// a buffer of size bufSize. This allows a program such as a GUI to receive tokens in its event loop
+// without blocking while the regular expressions are executed.
+//
+// The channel is closed when the end of the input is reached, when it returns an error, or when ctx is
+// done. The final EOFType token is not sent. The Iterator must not be used by the caller until the
+// channel is closed.
+func Async(ctx context.Context, it Iterator, bufSize int) <-chan AsyncToken {
+	ch := make(chan AsyncToken, bufSize)
+
+	go func() {
+		defer close(ch)
+
+		send := func(v AsyncToken) bool {
+			select {
+			case ch <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				send(AsyncToken{Err: fmt.Errorf("syn.Async: tokenizing failed: %v", r)})
+			}
+		}()
+
+		for ctx.Err() == nil {
+			tok, nextErr := it.Next()
+			if nextErr != nil {
+				send(AsyncToken{Err: nextErr})
+				return
+			}
+			if tok.Type == EOFType {
+				return
+			}
+			if !send(AsyncToken{Token: tok}) {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,60 @@
+package syn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsync(t *testing.T) {
+	prog := "int x = 1;\nint y = 2;\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+	expected := mylog.Check2(lex.TokensAll([]rune(prog)))
+
+	var tokens []Token
+	for v := range Async(context.Background(), lex.Tokenise([]rune(prog)), 2) {
+		assert.Nil(v.Err)
+		tokens = append(tokens, v.Token)
+	}
+	assert.Equal(expected, tokens)
+}
+
+func TestAsyncCancel(t *testing.T) {
+	prog := "int x = 1;\nint y = 2;\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Async(ctx, lex.Tokenise([]rune(prog)), 0)
+
+	v := <-ch
+	assert.Nil(v.Err)
+	cancel()
+
+	count := 0
+	for range ch {
+		count++
+	}
+	// At most one more token may have been sent before the cancellation was noticed
+	assert.LessOrEqual(count, 1)
+}
+
+func TestAsyncError(t *testing.T) {
+	assert := assert.New(t)
+
+	var values []AsyncToken
+	for v := range Async(context.Background(), brokenLexer().Tokenise([]rune("a (b")), 2) {
+		values = append(values, v)
+	}
+	if assert.Len(values, 2) {
+		assert.Equal(Name, values[0].Token.Type)
+		assert.Error(values[1].Err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package bench holds a corpus of representative source files in several languages, in testdata/corpus,
+// and the benchmarks of the lexers on it. The benchmarks report the tokens lexed per second and the allocations
+// made per token along with the usual measurements, so that a change that slows down the iterator, the
+// coalescer or the regular expressions shows up as a change for the languages that use them:
+//
+//	go test ./bench -run '^$' -bench . -count 6 > new.txt
+//
+// compared with the results from before the change using benchstat.
+package bench
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"testing"
+
+	"github.com/jeffwilliams/syn"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+//go:embed testdata/corpus
+var corpus embed.FS
+
+// Sample is a file of the corpus.
+type Sample struct {
+	// Name is the name of the file in testdata/corpus.
+	Name string
+	// Lexer is the lexer matching the file's name.
+	Lexer *syn.Lexer
+	Text  []rune
+}
+
+// Language returns the name of the sample's lexer, which the benchmarks are named after.
+func (s Sample) Language() string {
+	return s.Lexer.Config().Name
+}
+
+// Corpus returns the files of the corpus, sorted by name. It returns an error if a file matches no lexer.
+func Corpus() ([]Sample, error) {
+	entries, readErr := fs.ReadDir(corpus, "testdata/corpus")
+	if readErr != nil {
+		return nil, readErr
+	}
+	samples := make([]Sample, 0, len(entries))
+	for _, e := range entries {
+		lexer := lexers.Match(e.Name())
+		if lexer == nil {
+			return nil, fmt.Errorf("bench: no lexer for corpus file %s", e.Name())
+		}
+		data, fileErr := fs.ReadFile(corpus, path.Join("testdata/corpus", e.Name()))
+		if fileErr != nil {
+			return nil, fileErr
+		}
+		samples = append(samples, Sample{Name: e.Name(), Lexer: lexer, Text: []rune(string(data))})
+	}
+	return samples, nil
+}
+
+// Lex benchmarks lexing the whole text of s with opts. Along with the time and allocations per operation and the
+// throughput in bytes, it reports the tokens lexed per second and the allocations per token.
+func Lex(b *testing.B, s Sample, opts ...syn.Option) {
+	b.Helper()
+	tokens, lexErr := s.Lexer.TokensAll(s.Text, opts...)
+	if lexErr != nil {
+		b.Fatalf("%s: %v", s.Name, lexErr)
+	}
+	allocs := testing.AllocsPerRun(1, func() { s.Lexer.TokensAll(s.Text, opts...) })
+	b.SetBytes(int64(len(string(s.Text))))
+	b.ReportAllocs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, lexErr = s.Lexer.TokensAll(s.Text, opts...); lexErr != nil {
+			b.Fatal(lexErr)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(len(tokens))*float64(b.N)/b.Elapsed().Seconds(), "tokens/s")
+	b.ReportMetric(allocs/float64(len(tokens)), "allocs/token")
+}
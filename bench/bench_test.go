@@ -0,0 +1,51 @@
+//go:build !syn_minimal
+
+package bench
+
+import (
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+)
+
+func TestCorpus(t *testing.T) {
+	samples := mylog.Check2(Corpus())
+	assert.NotEmpty(t, samples)
+	for _, s := range samples {
+		tokens, lexErr := s.Lexer.TokensAll(s.Text)
+		assert.NoError(t, lexErr, s.Name)
+		var text []rune
+		for _, tok := range tokens {
+			assert.NotEqual(t, syn.Error, tok.Type, "%s: error token %q at %d", s.Name, string(tok.Value), tok.Start)
+			text = append(text, tok.Value...)
+		}
+		assert.Equal(t, string(s.Text), string(text), s.Name)
+	}
+}
+
+// BenchmarkLex lexes each file of the corpus with the default options.
+func BenchmarkLex(b *testing.B) {
+	benchmarkCorpus(b)
+}
+
+// BenchmarkOffsetsOnly lexes each file of the corpus without the values of the tokens.
+func BenchmarkOffsetsOnly(b *testing.B) {
+	benchmarkCorpus(b, syn.WithOffsetsOnly(true))
+}
+
+// BenchmarkEOLTokens lexes each file of the corpus with the line ends in tokens of their own.
+func BenchmarkEOLTokens(b *testing.B) {
+	benchmarkCorpus(b, syn.WithEOLTokens(true))
+}
+
+func benchmarkCorpus(b *testing.B, opts ...syn.Option) {
+	samples := mylog.Check2(Corpus())
+	for _, s := range samples {
+		b.Run(s.Language(), func(b *testing.B) {
+			Lex(b, s, opts...)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+#!/usr/bin/env bash
+# Back up the directories given in the configuration to a dated archive, keeping the last few.
+set -euo pipefail
+
+readonly CONFIG="${BACKUP_CONFIG:-/etc/backup.conf}"
+readonly DEST="${BACKUP_DEST:-/var/backups}"
+KEEP=7
+VERBOSE=0
+
+usage() {
+	cat <<USAGE
+usage: ${0##*/} [-k count] [-v] [dir ...]
+  -k count  keep the latest count archives (default $KEEP)
+  -v        list the files archived
+USAGE
+	exit 2
+}
+
+log() {
+	printf '%s %s\n' "$(date +%FT%T)" "$*" >&2
+}
+
+while getopts 'k:vh' opt; do
+	case "$opt" in
+	k) KEEP=$OPTARG ;;
+	v) VERBOSE=1 ;;
+	*) usage ;;
+	esac
+done
+shift $((OPTIND - 1))
+
+dirs=("$@")
+if [[ ${#dirs[@]} -eq 0 && -r $CONFIG ]]; then
+	while IFS= read -r line; do
+		[[ -z $line || $line == \#* ]] && continue
+		dirs+=("$line")
+	done <"$CONFIG"
+fi
+if ((${#dirs[@]} == 0)); then
+	log "nothing to back up"
+	exit 1
+fi
+
+mkdir -p -- "$DEST"
+archive="$DEST/backup-$(hostname -s)-$(date +%Y%m%d-%H%M%S).tar.gz"
+tmp=$(mktemp "$archive.XXXXXX")
+trap 'rm -f -- "$tmp"' EXIT
+
+flags=(-czf "$tmp")
+((VERBOSE)) && flags+=(-v)
+for d in "${dirs[@]}"; do
+	if [ ! -d "$d" ]; then
+		log "skipping $d: not a directory"
+		continue
+	fi
+done
+
+if tar "${flags[@]}" -- "${dirs[@]}" 2>"$tmp.err"; then
+	mv -- "$tmp" "$archive"
+	log "wrote $archive ($(du -h "$archive" | cut -f1))"
+else
+	status=$?
+	log "tar failed with status $status: $(<"$tmp.err")"
+	exit "$status"
+fi
+rm -f -- "$tmp.err"
+
+# Remove all but the newest $KEEP archives.
+ls -1t "$DEST"/backup-*.tar.gz 2>/dev/null | tail -n +$((KEEP + 1)) | xargs -r rm -f --
+log "kept $(ls "$DEST"/backup-*.tar.gz | wc -l) archives"
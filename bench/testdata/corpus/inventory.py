@@ -0,0 +1,111 @@
+#!/usr/bin/env python3
+"""Track an inventory of parts and report what needs reordering."""
+
+from __future__ import annotations
+
+import argparse
+import csv
+import json
+import logging
+from dataclasses import dataclass, field
+from decimal import Decimal
+from pathlib import Path
+from typing import Iterable, Iterator
+
+log = logging.getLogger(__name__)
+
+REORDER_MARGIN = 0.15
+UNITS = {"ea": 1, "dz": 12, "gr": 144}
+
+
+class InventoryError(Exception):
+    """Raised when the inventory file is malformed."""
+
+
+@dataclass(order=True)
+class Part:
+    sku: str
+    name: str = field(compare=False)
+    quantity: int = 0
+    minimum: int = 0
+    price: Decimal = Decimal("0.00")
+    tags: list[str] = field(default_factory=list, compare=False)
+
+    @property
+    def value(self) -> Decimal:
+        return self.price * self.quantity
+
+    def needs_reorder(self, margin: float = REORDER_MARGIN) -> bool:
+        return self.quantity < self.minimum * (1 + margin)
+
+    def __str__(self) -> str:
+        return f"{self.sku:<10} {self.name!r:30} {self.quantity:>6} @ {self.price:.2f}"
+
+
+def read_parts(path: Path) -> Iterator[Part]:
+    with path.open(newline="", encoding="utf-8") as f:
+        for lineno, row in enumerate(csv.DictReader(f), start=2):
+            try:
+                unit = UNITS[row.get("unit", "ea")]
+                yield Part(
+                    sku=row["sku"].strip(),
+                    name=row["name"],
+                    quantity=int(row["quantity"]) * unit,
+                    minimum=int(row.get("minimum") or 0),
+                    price=Decimal(row["price"]),
+                    tags=[t for t in row.get("tags", "").split(";") if t],
+                )
+            except (KeyError, ValueError) as e:
+                raise InventoryError(f"{path}:{lineno}: {e}") from e
+
+
+def summarize(parts: Iterable[Part]) -> dict[str, object]:
+    parts = sorted(parts)
+    low = [p for p in parts if p.needs_reorder()]
+    total = sum((p.value for p in parts), Decimal(0))
+    by_tag: dict[str, int] = {}
+    for p in parts:
+        for tag in p.tags:
+            by_tag[tag] = by_tag.get(tag, 0) + p.quantity
+    return {
+        "count": len(parts),
+        "value": str(total),
+        "reorder": [p.sku for p in low],
+        "tags": dict(sorted(by_tag.items(), key=lambda kv: -kv[1])),
+    }
+
+
+async def notify(client, summary: dict[str, object]) -> None:
+    if not summary["reorder"]:
+        return
+    async with client.session() as s:
+        await s.post("/alerts", json={"text": "reorder: " + ", ".join(summary["reorder"])})
+
+
+def main(argv: list[str] | None = None) -> int:
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("inventory", type=Path)
+    parser.add_argument("-v", "--verbose", action="store_true")
+    parser.add_argument("--json", action="store_true", help="print the summary as JSON")
+    args = parser.parse_args(argv)
+    logging.basicConfig(level=logging.DEBUG if args.verbose else logging.WARNING)
+
+    try:
+        parts = list(read_parts(args.inventory))
+    except (OSError, InventoryError) as e:
+        log.error("%s", e)
+        return 1
+
+    summary = summarize(parts)
+    if args.json:
+        print(json.dumps(summary, indent=2))
+    else:
+        for p in parts:
+            marker = "*" if p.needs_reorder() else " "
+            print(marker, p)
+        print(f"\n{summary['count']} parts worth ${summary['value']}")
+    return 0 if not summary["reorder"] else 2
+
+
+if __name__ == "__main__":
+    raise SystemExit(main())
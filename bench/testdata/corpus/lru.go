@@ -0,0 +1,124 @@
+// Package lru implements a least recently used cache of fixed capacity.
+package lru
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrCapacity is returned by New if the capacity is not positive.
+var ErrCapacity = errors.New("lru: capacity must be positive")
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Cache is a cache holding at most a fixed number of values. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[K]*list.Element
+	// OnEvict, if set, is called with each value removed to make room for a new one.
+	OnEvict func(key K, value V)
+
+	hits, misses uint64
+}
+
+// New returns an empty cache holding at most capacity values.
+func New[K comparable, V any](capacity int) (*Cache[K, V], error) {
+	if capacity <= 0 {
+		return nil, ErrCapacity
+	}
+	return &Cache[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[K]*list.Element, capacity),
+	}, nil
+}
+
+// Get returns the value for key and whether it was found, marking it as the most recently used.
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, found := c.items[key]; found {
+		c.order.MoveToFront(e)
+		c.hits++
+		return e.Value.(*entry[K, V]).value, true
+	}
+	c.misses++
+	return value, false
+}
+
+// Put adds or replaces the value for key, evicting the least recently used value if the cache is full.
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, found := c.items[key]; found {
+		e.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		old := c.order.Remove(oldest).(*entry[K, V])
+		delete(c.items, old.key)
+		if c.OnEvict != nil {
+			c.OnEvict(old.key, old.value)
+		}
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key, value})
+}
+
+// Len returns the number of values in the cache.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// String describes the cache and its hit rate.
+func (c *Cache[K, V]) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rate := 0.0
+	if total := c.hits + c.misses; total > 0 {
+		rate = float64(c.hits) / float64(total) * 100
+	}
+	return fmt.Sprintf("lru.Cache{len: %d, cap: %d, hits: %.1f%%}", c.order.Len(), c.capacity, rate)
+}
+
+// Keys returns the keys in the cache from the most to the least recently used.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]K, 0, c.order.Len())
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*entry[K, V]).key)
+	}
+	return keys
+}
+
+const (
+	kib = 1 << (10 * (iota + 1))
+	mib
+	gib
+)
+
+func sizeLabel(n int64) string {
+	switch {
+	case n >= gib:
+		return fmt.Sprintf("%.2f GiB", float64(n)/gib)
+	case n >= mib:
+		return fmt.Sprintf("%.2f MiB", float64(n)/mib)
+	case n >= kib:
+		return fmt.Sprintf("%.2f KiB", float64(n)/kib)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+var _ = sizeLabel(0x1F_FFFF) + `raw
+string` + "\té\n" + string('x')
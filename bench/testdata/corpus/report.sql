@@ -0,0 +1,53 @@
+-- Monthly revenue report by region, with the change from the previous month.
+CREATE TABLE IF NOT EXISTS orders (
+    id          BIGSERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers (id) ON DELETE CASCADE,
+    region      VARCHAR(32) NOT NULL DEFAULT 'unknown',
+    placed_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    total       NUMERIC(12, 2) NOT NULL CHECK (total >= 0),
+    status      TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS orders_region_placed ON orders (region, placed_at);
+
+/* Revenue per region and month, excluding cancelled orders. */
+WITH monthly AS (
+    SELECT region,
+           date_trunc('month', placed_at) AS month,
+           count(*)                       AS orders,
+           sum(total)                     AS revenue
+    FROM orders
+    WHERE status NOT IN ('cancelled', 'refunded')
+      AND placed_at >= now() - INTERVAL '13 months'
+    GROUP BY region, date_trunc('month', placed_at)
+),
+ranked AS (
+    SELECT m.*,
+           lag(revenue) OVER (PARTITION BY region ORDER BY month) AS previous,
+           rank() OVER (PARTITION BY month ORDER BY revenue DESC) AS position
+    FROM monthly AS m
+)
+SELECT region,
+       to_char(month, 'YYYY-MM') AS month,
+       orders,
+       revenue,
+       CASE
+           WHEN previous IS NULL OR previous = 0 THEN NULL
+           ELSE round((revenue - previous) / previous * 100, 1)
+       END AS change_pct,
+       position
+FROM ranked
+WHERE month < date_trunc('month', now())
+ORDER BY month DESC, position ASC
+LIMIT 100;
+
+UPDATE orders
+SET status = 'archived'
+WHERE placed_at < now() - INTERVAL '2 years'
+  AND status = 'delivered';
+
+INSERT INTO audit_log (action, detail, created_at)
+VALUES ('archive', 'orders older than 2 years', now()),
+       ('report', E'monthly\trevenue', now());
+
+DELETE FROM sessions WHERE expires_at < current_timestamp;
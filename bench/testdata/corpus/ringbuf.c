@@ -0,0 +1,115 @@
+/*
+ * ringbuf.c - a single producer, single consumer ring buffer of bytes.
+ */
+#include <errno.h>
+#include <stdatomic.h>
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#define RB_MIN_CAPACITY 64u
+#define RB_IS_POW2(n) (((n) & ((n) - 1)) == 0)
+#define RB_MASK(rb, i) ((i) & ((rb)->capacity - 1))
+
+typedef struct ringbuf {
+	size_t capacity;
+	_Atomic size_t head; /* next byte to write */
+	_Atomic size_t tail; /* next byte to read */
+	unsigned char *data;
+} ringbuf_t;
+
+static size_t next_pow2(size_t n)
+{
+	size_t p = RB_MIN_CAPACITY;
+	while (p < n)
+		p <<= 1;
+	return p;
+}
+
+ringbuf_t *rb_new(size_t capacity)
+{
+	ringbuf_t *rb = calloc(1, sizeof(*rb));
+	if (rb == NULL)
+		return NULL;
+	rb->capacity = next_pow2(capacity);
+	rb->data = malloc(rb->capacity);
+	if (rb->data == NULL) {
+		free(rb);
+		errno = ENOMEM;
+		return NULL;
+	}
+	atomic_init(&rb->head, 0);
+	atomic_init(&rb->tail, 0);
+	return rb;
+}
+
+void rb_free(ringbuf_t *rb)
+{
+	if (rb != NULL) {
+		free(rb->data);
+		free(rb);
+	}
+}
+
+size_t rb_len(const ringbuf_t *rb)
+{
+	return atomic_load_explicit(&rb->head, memory_order_acquire) -
+	       atomic_load_explicit(&rb->tail, memory_order_acquire);
+}
+
+size_t rb_write(ringbuf_t *rb, const void *src, size_t n)
+{
+	const unsigned char *p = src;
+	size_t head = atomic_load_explicit(&rb->head, memory_order_relaxed);
+	size_t tail = atomic_load_explicit(&rb->tail, memory_order_acquire);
+	size_t space = rb->capacity - (head - tail);
+	size_t i;
+
+	if (n > space)
+		n = space;
+	for (i = 0; i < n; i++)
+		rb->data[RB_MASK(rb, head + i)] = p[i];
+	atomic_store_explicit(&rb->head, head + n, memory_order_release);
+	return n;
+}
+
+size_t rb_read(ringbuf_t *rb, void *dst, size_t n)
+{
+	unsigned char *p = dst;
+	size_t tail = atomic_load_explicit(&rb->tail, memory_order_relaxed);
+	size_t head = atomic_load_explicit(&rb->head, memory_order_acquire);
+	size_t avail = head - tail, first;
+
+	if (n > avail)
+		n = avail;
+	first = rb->capacity - RB_MASK(rb, tail);
+	if (first > n)
+		first = n;
+	memcpy(p, rb->data + RB_MASK(rb, tail), first);
+	memcpy(p + first, rb->data, n - first);
+	atomic_store_explicit(&rb->tail, tail + n, memory_order_release);
+	return n;
+}
+
+#ifdef RINGBUF_MAIN
+int main(int argc, char **argv)
+{
+	ringbuf_t *rb = rb_new(argc > 1 ? strtoul(argv[1], NULL, 0) : 100);
+	char buf[32];
+	size_t n;
+
+	if (!rb) {
+		perror("rb_new");
+		return 1;
+	}
+	printf("capacity %zu, pow2 %d\n", rb->capacity, RB_IS_POW2(rb->capacity));
+	rb_write(rb, "hello, ring\n", 12);
+	n = rb_read(rb, buf, sizeof buf - 1);
+	buf[n] = '\0';
+	fputs(buf, stdout);
+	printf("%c %lu %#x %.3f\n", 'a', 42UL, 0xffu, 1e-3);
+	rb_free(rb);
+	return 0;
+}
+#endif
@@ -0,0 +1,102 @@
+// A small observable store with undo, in the style of a Redux reducer.
+'use strict';
+
+const MAX_HISTORY = 100;
+
+export class Store {
+  _state;
+  _reducer;
+  _listeners = new Set();
+  _past = [];
+  _future = [];
+
+  constructor(reducer, initialState = {}) {
+    this._reducer = reducer;
+    this._state = Object.freeze({ ...initialState });
+  }
+
+  get state() {
+    return this._state;
+  }
+
+  subscribe(listener) {
+    this._listeners.add(listener);
+    return () => this._listeners.delete(listener);
+  }
+
+  dispatch(action) {
+    if (typeof action === 'function') {
+      return action(this.dispatch.bind(this), () => this._state);
+    }
+    if (!action || typeof action.type !== 'string') {
+      throw new TypeError(`invalid action: ${JSON.stringify(action)}`);
+    }
+    const next = this._reducer(this._state, action);
+    if (next === this._state) return action;
+    this._past.push(this._state);
+    if (this._past.length > MAX_HISTORY) this._past.shift();
+    this._future.length = 0;
+    this._set(next);
+    return action;
+  }
+
+  undo() {
+    if (this._past.length === 0) return false;
+    this._future.push(this._state);
+    this._set(this._past.pop());
+    return true;
+  }
+
+  redo() {
+    if (!this._future.length) return false;
+    this._past.push(this._state);
+    this._set(this._future.pop());
+    return true;
+  }
+
+  _set(state) {
+    const prev = this._state;
+    this._state = Object.freeze(state);
+    for (const listener of [...this._listeners]) {
+      try {
+        listener(this._state, prev);
+      } catch (err) {
+        console.error('listener failed:', err?.message ?? err);
+      }
+    }
+  }
+}
+
+export function todos(state = { items: [], filter: 'all' }, action) {
+  switch (action.type) {
+    case 'add':
+      return { ...state, items: [...state.items, { id: Date.now(), text: action.text, done: false }] };
+    case 'toggle':
+      return {
+        ...state,
+        items: state.items.map((t) => (t.id === action.id ? { ...t, done: !t.done } : t)),
+      };
+    case 'remove':
+      return { ...state, items: state.items.filter(({ id }) => id !== action.id) };
+    case 'filter':
+      return /^(all|open|done)$/.test(action.filter) ? { ...state, filter: action.filter } : state;
+    default:
+      return state;
+  }
+}
+
+export const fetchTodos = (url) => async (dispatch) => {
+  const res = await fetch(url, { headers: { Accept: 'application/json' } });
+  if (!res.ok) throw new Error(`HTTP ${res.status}`);
+  for (const { title } of await res.json()) {
+    dispatch({ type: 'add', text: title });
+  }
+};
+
+const store = new Store(todos);
+const unsubscribe = store.subscribe((s) => console.log(`${s.items.length} items, filter=${s.filter}`));
+store.dispatch({ type: 'add', text: 'write benchmarks' });
+store.dispatch({ type: 'filter', filter: 'open' });
+store.undo();
+unsubscribe();
+console.log(0x1f, 1_000_000, 3.14e-2, 10n, null, undefined, NaN);
@@ -0,0 +1,108 @@
+//! A tokenizer for a small arithmetic language.
+
+use std::fmt;
+use std::iter::Peekable;
+use std::str::Chars;
+
+#[derive(Debug, Clone, PartialEq)]
+pub enum Token<'a> {
+    Number(f64),
+    Ident(&'a str),
+    Op(char),
+    LParen,
+    RParen,
+}
+
+#[derive(Debug)]
+pub struct LexError {
+    pub offset: usize,
+    pub found: char,
+}
+
+impl fmt::Display for LexError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "unexpected {:?} at offset {}", self.found, self.offset)
+    }
+}
+
+impl std::error::Error for LexError {}
+
+pub struct Lexer<'a> {
+    src: &'a str,
+    chars: Peekable<Chars<'a>>,
+    pos: usize,
+}
+
+impl<'a> Lexer<'a> {
+    pub fn new(src: &'a str) -> Self {
+        Lexer { src, chars: src.chars().peekable(), pos: 0 }
+    }
+
+    fn bump(&mut self) -> Option<char> {
+        let c = self.chars.next()?;
+        self.pos += c.len_utf8();
+        Some(c)
+    }
+
+    fn take_while<F: Fn(char) -> bool>(&mut self, pred: F) -> &'a str {
+        let start = self.pos;
+        while let Some(&c) = self.chars.peek() {
+            if !pred(c) {
+                break;
+            }
+            self.bump();
+        }
+        &self.src[start..self.pos]
+    }
+}
+
+impl<'a> Iterator for Lexer<'a> {
+    type Item = Result<Token<'a>, LexError>;
+
+    fn next(&mut self) -> Option<Self::Item> {
+        self.take_while(char::is_whitespace);
+        let &c = self.chars.peek()?;
+        let tok = match c {
+            '0'..='9' | '.' => {
+                let text = self.take_while(|c| c.is_ascii_digit() || c == '.');
+                match text.parse() {
+                    Ok(n) => Token::Number(n),
+                    Err(_) => return Some(Err(LexError { offset: self.pos, found: c })),
+                }
+            }
+            c if c.is_alphabetic() || c == '_' => Token::Ident(self.take_while(|c| c.is_alphanumeric() || c == '_')),
+            '+' | '-' | '*' | '/' | '^' => {
+                self.bump();
+                Token::Op(c)
+            }
+            '(' => {
+                self.bump();
+                Token::LParen
+            }
+            ')' => {
+                self.bump();
+                Token::RParen
+            }
+            other => return Some(Err(LexError { offset: self.pos, found: other })),
+        };
+        Some(Ok(tok))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn lexes_expression() {
+        let toks: Result<Vec<_>, _> = Lexer::new("2 * (x_1 + 3.5)").collect();
+        assert_eq!(toks.unwrap().len(), 7);
+    }
+
+    #[test]
+    fn reports_bad_char() {
+        let err = Lexer::new("1 $ 2").find_map(Result::err).unwrap();
+        assert_eq!(err.offset, 2);
+        println!("{err} {}", 0xffu8 as u32 + 1_000);
+    }
+}
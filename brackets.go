@@ -0,0 +1,71 @@
+package syn
+
+// BracketDepth is the Meta of the bracket tokens produced by the filter returned by NewBracketDepthFilter. It
+// is the number of unclosed brackets before the bracket, so matching brackets have the same depth. A closing
+// bracket that doesn't match the innermost unclosed bracket has the depth -1.
+type BracketDepth int
+
+// NewBracketDepthFilter returns a Filter that splits each bracket (, ), [, ], { and } out of the Punctuation
+// tokens into a token of its own, and sets the Meta of the token to its BracketDepth. Renderers can use this
+// to color brackets by their depth without parsing the text again.
+func NewBracketDepthFilter() Filter {
+	return &bracketDepthFilter{}
+}
+
+type bracketDepthFilter struct {
+	// open holds the brackets that have not yet been closed.
+	open []rune
+}
+
+func (f *bracketDepthFilter) Filter(tok Token) []Token {
+	if !tok.Type.InCategory(Punctuation) {
+		return []Token{tok}
+	}
+
+	var toks []Token
+	piece := func(start, end int, meta any) {
+		toks = append(toks, Token{
+			Type:  tok.Type,
+			Value: tok.Value[start:end],
+			Start: tok.Start + start,
+			End:   tok.Start + end,
+			Meta:  meta,
+		})
+	}
+
+	last := 0
+	for i, c := range tok.Value {
+		var depth BracketDepth
+		switch c {
+		case '(', '[', '{':
+			depth = BracketDepth(len(f.open))
+			f.open = append(f.open, c)
+		case ')', ']', '}':
+			depth = -1
+			if n := len(f.open); n > 0 && f.open[n-1] == openingBrackets[c] {
+				f.open = f.open[:n-1]
+				depth = BracketDepth(n - 1)
+			}
+		default:
+			continue
+		}
+
+		if i > last {
+			piece(last, i, tok.Meta)
+		}
+		piece(i, i+1, depth)
+		last = i + 1
+	}
+
+	if last == 0 {
+		return []Token{tok}
+	}
+	if last < len(tok.Value) {
+		piece(last, len(tok.Value), tok.Meta)
+	}
+	return toks
+}
+
+func (f *bracketDepthFilter) Clone() Filter {
+	return &bracketDepthFilter{open: append([]rune(nil), f.open...)}
+}
@@ -0,0 +1,125 @@
+// Package chromacompat adapts syn lexers and iterators to the interfaces of Chroma
+// (github.com/alecthomas/chroma/v2), and Chroma's to syn's, so that an application can move from one to the
+// other gradually and use Chroma's formatters and styles with syn lexers.
+package chromacompat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// TokenType returns the Chroma token type corresponding to the syn token type t. The token types of syn
+// have the same values as Chroma's, except for those that Chroma doesn't have, such as TextEOL, which are
+// mapped to the closest type Chroma has.
+func TokenType(t syn.TokenType) chroma.TokenType {
+	switch t {
+	case syn.TextEOL:
+		return chroma.TextWhitespace
+	case syn.TextLink:
+		return chroma.GenericUnderline
+	case syn.LiteralColor:
+		return chroma.LiteralOther
+	case syn.LiteralStringFormat:
+		return chroma.LiteralStringInterpol
+	case syn.CommentDoc:
+		return chroma.CommentSpecial
+	}
+	return chroma.TokenType(t)
+}
+
+// SynTokenType returns the syn token type corresponding to the Chroma token type t.
+func SynTokenType(t chroma.TokenType) syn.TokenType {
+	return syn.TokenType(t)
+}
+
+// Lexer wraps the syn Lexer l so that it implements chroma.Lexer.
+func Lexer(l *syn.Lexer) chroma.Lexer {
+	return &lexer{lexer: l}
+}
+
+type lexer struct {
+	lexer    *syn.Lexer
+	analyser func(text string) float32
+}
+
+func (l *lexer) Config() *chroma.Config {
+	c := l.lexer.Config()
+	return &chroma.Config{
+		Name:      c.Name,
+		Aliases:   c.Aliases,
+		Filenames: c.Filenames,
+		MimeTypes: c.MimeTypes,
+		Priority:  c.Priority,
+	}
+}
+
+// Tokenise lexes text with the syn lexer. Since a syn lexer can only begin lexing in its root state,
+// options.State must be empty or "root". The text is lexed as the tokens are read, so an error lexing it ends
+// the iterator early; see IteratorErr.
+func (l *lexer) Tokenise(options *chroma.TokeniseOptions, text string) (chroma.Iterator, error) {
+	if options != nil {
+		if options.State != "" && options.State != "root" {
+			return nil, fmt.Errorf("chromacompat: can't start lexing in state %q; only the root state is supported", options.State)
+		}
+		if options.EnsureLF {
+			text = strings.ReplaceAll(text, "\r\n", "\n")
+			text = strings.ReplaceAll(text, "\r", "\n")
+		}
+	}
+
+	return Iterator(l.lexer.Tokenise([]rune(text))), nil
+}
+
+// SetRegistry does nothing: the lexers that a syn lexer refers to are looked up in its own LexerRegistry.
+func (l *lexer) SetRegistry(registry *chroma.LexerRegistry) chroma.Lexer {
+	return l
+}
+
+func (l *lexer) SetAnalyser(analyser func(text string) float32) chroma.Lexer {
+	l.analyser = analyser
+	return l
+}
+
+// AnalyseText uses the analyser set by SetAnalyser, or if there is none the patterns of the syn lexer's Analyse.
+func (l *lexer) AnalyseText(text string) float32 {
+	if l.analyser == nil {
+		return l.lexer.AnalyseText(text)
+	}
+	return l.analyser(text)
+}
+
+// Iterator wraps the syn Iterator it as a chroma.Iterator, which can be passed to a Chroma formatter. If it
+// returns an error the chroma.Iterator ends there; use IteratorErr to find out whether it did.
+func Iterator(it syn.Iterator) chroma.Iterator {
+	iter, _ := IteratorErr(it)
+	return iter
+}
+
+// IteratorErr is like Iterator, but also returns a function that returns the error that ended the
+// chroma.Iterator, or nil if it reached the end of the text or hasn't ended yet.
+func IteratorErr(it syn.Iterator) (chroma.Iterator, func() error) {
+	done := false
+	var lexErr error
+	iter := func() chroma.Token {
+		if done {
+			return chroma.EOF
+		}
+
+		tok, nextErr := it.Next()
+		if nextErr != nil {
+			done = true
+			lexErr = nextErr
+			return chroma.EOF
+		}
+		if tok.Type == syn.EOFType {
+			done = true
+			return chroma.EOF
+		}
+		return chroma.Token{Type: TokenType(tok.Type), Value: string(tok.Value)}
+	}
+	return iter, func() error { return lexErr }
+}
@@ -0,0 +1,109 @@
+package chromacompat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+)
+
+func TestRoundTrip(t *testing.T) {
+	prog := []rune("int x = 1;\n/* é */\n")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(syn.NewLexerFromXMLFile("../lexers/embedded/c.xml"))
+	expected := mylog.Check2(lex.TokensAll(prog))
+
+	cl := Lexer(lex)
+	assert.Equal("C", cl.Config().Name)
+
+	it := mylog.Check2(Tokenise(cl, prog))
+	tokens := mylog.Check2(it.Collect())
+	assert.Equal(expected, tokens)
+
+	it = mylog.Check2(Tokenise(cl, prog))
+	tok := mylog.Check2(it.Peek(1))
+	assert.Equal(expected[1], tok)
+	state := it.State()
+	mylog.Check2(it.Next())
+	mylog.Check2(it.Next())
+	assert.Nil(it.Rewind(1))
+	tok = mylog.Check2(it.Next())
+	assert.Equal(expected[1], tok)
+	it.SetState(state)
+	tok = mylog.Check2(it.Next())
+	assert.Equal(expected[0], tok)
+}
+
+func TestTokenType(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chroma.Keyword, TokenType(syn.Keyword))
+	assert.Equal(chroma.CommentSpecial, TokenType(syn.CommentDoc))
+	assert.Equal(chroma.Comment, TokenType(syn.CommentDoc).Category())
+	assert.Equal(chroma.TextWhitespace, TokenType(syn.TextEOL))
+}
+
+func TestTokeniseState(t *testing.T) {
+	lex := mylog.Check2(syn.NewLexerFromXMLFile("../lexers/embedded/c.xml"))
+
+	_, tokeniseErr := Lexer(lex).Tokenise(&chroma.TokeniseOptions{State: "string"}, "x")
+	assert.NotNil(t, tokeniseErr)
+}
+
+const brokenLexer = `<lexer>
+  <config>
+    <name>broken</name>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern="\(">
+        <token type="Punctuation"/>
+        <push state="missing"/>
+      </rule>
+      <rule pattern="\w+">
+        <token type="Keyword"/>
+      </rule>
+      <rule pattern="\s+">
+        <token type="Text"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>`
+
+func TestIteratorErr(t *testing.T) {
+	assert := assert.New(t)
+
+	lex := mylog.Check2(syn.NewLexerFromXML(strings.NewReader(brokenLexer)))
+
+	it, itErr := IteratorErr(lex.Tokenise([]rune("a (b")))
+	assert.Equal(chroma.Token{Type: chroma.Keyword, Value: "a"}, it())
+	assert.NoError(itErr())
+	assert.Equal(chroma.EOF, it())
+	assert.EqualError(itErr(), "syn.iterator: a rule refers to a state missing that doesn't exist")
+	assert.Equal(chroma.EOF, it())
+
+	cit := mylog.Check2(Lexer(lex).Tokenise(nil, "b (c"))
+	assert.Equal([]chroma.Token{{Type: chroma.Keyword, Value: "b"}}, cit.Tokens())
+}
+
+func TestTokeniseError(t *testing.T) {
+	lex := mylog.Check2(syn.NewLexerFromXMLFile("../lexers/embedded/c.xml"))
+
+	_, tokeniseErr := Tokenise(&stateLexer{Lexer(lex)}, []rune("x"))
+	assert.EqualError(t, tokeniseErr, `chromacompat: can't start lexing in state "string"; only the root state is supported`)
+}
+
+// stateLexer is a chroma.Lexer that starts lexing in the state "string".
+type stateLexer struct {
+	chroma.Lexer
+}
+
+func (l *stateLexer) Tokenise(options *chroma.TokeniseOptions, text string) (chroma.Iterator, error) {
+	return l.Lexer.Tokenise(&chroma.TokeniseOptions{State: "string"}, text)
+}
@@ -0,0 +1,180 @@
+package chromacompat
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+	chromalexers "github.com/alecthomas/chroma/v2/lexers"
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+	"github.com/jeffwilliams/syn/bench"
+	"github.com/jeffwilliams/syn/internal/golden"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+var compare = flag.Bool("compare", false, "compare the tokens of the syn lexers with Chroma's on the shared corpus")
+
+// TestCompareWithChroma lexes the benchmark corpus and the golden files of the lexers with both syn and Chroma and
+// reports, for each language, where the token streams first differ. Since most of the grammars were ported from
+// Chroma, a difference is often a bug in how syn builds a lexer from its rules, such as in the handling of
+// includes, bygroups or combined states, though some are improvements made to syn's grammars. The syn lexers are
+// used without their default filters, which refine the tokens in ways Chroma doesn't. It is only run when asked
+// for:
+//
+//	go test ./chromacompat -run CompareWithChroma -compare
+func TestCompareWithChroma(t *testing.T) {
+	if !*compare {
+		t.Skip("run with -compare to compare the lexers with Chroma's")
+	}
+
+	unfiltered := unfilteredRegistry()
+	for _, s := range compareCorpus(t) {
+		t.Run(s.Name, func(t *testing.T) {
+			name := s.Language()
+			if l := unfiltered.Get(name); l != nil {
+				s.Lexer = l
+			}
+			cl := chromalexers.Get(name)
+			if cl == nil || cl.Config().Name != name {
+				t.Skipf("Chroma has no lexer called %s", name)
+			}
+			text := string(s.Text)
+			synTokens := mylog.Check2(s.Lexer.TokensAll(s.Text))
+			it := mylog.Check2(cl.Tokenise(nil, text))
+			if d := firstDivergence(text, normalizeSyn(synTokens), normalizeChroma(it.Tokens())); d != "" {
+				t.Errorf("%s: %s", name, d)
+			}
+		})
+	}
+}
+
+// compareCorpus returns the files of the benchmark corpus and the source files of the golden files of the lexers.
+func compareCorpus(t *testing.T) []bench.Sample {
+	samples := mylog.Check2(bench.Corpus())
+	for _, dump := range mylog.Check2(golden.Fixtures("../lexers/testdata")) {
+		source := strings.TrimSuffix(dump, golden.Suffix)
+		l := lexers.Match(filepath.Base(source))
+		if l == nil {
+			continue
+		}
+		data := mylog.Check2(os.ReadFile(source))
+		samples = append(samples, bench.Sample{Name: filepath.Base(source), Lexer: l, Text: []rune(string(data))})
+	}
+	return samples
+}
+
+// unfilteredRegistry returns a registry of the lexers defined in the lexers package, without their default filters.
+func unfilteredRegistry() *syn.LexerRegistry {
+	reg := syn.NewLexerRegistry()
+	fsys := lexers.Definitions()
+	for _, path := range mylog.Check2(fs.Glob(fsys, "*.xml")) {
+		m := mylog.Check2(syn.LexerMetadataFromXMLFS(fsys, path))
+		reg.RegisterLazy(m.LexerConfig(), func() (*syn.Lexer, error) {
+			return syn.NewLexerFromXMLFS(fsys, path)
+		})
+	}
+	return reg
+}
+
+// comparedToken is a token in the form in which the tokens of syn and Chroma are compared.
+type comparedToken struct {
+	Type  chroma.TokenType
+	Value string
+}
+
+func (t comparedToken) String() string {
+	return fmt.Sprintf("%s %q", t.Type, t.Value)
+}
+
+// normalizeSyn converts syn tokens to the form they are compared in. The types that Chroma doesn't have are
+// converted to the closest Chroma types.
+func normalizeSyn(tokens []syn.Token) []comparedToken {
+	converted := make([]comparedToken, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.Type != syn.EOFType {
+			converted = append(converted, comparedToken{TokenType(tok.Type), string(tok.Value)})
+		}
+	}
+	return coalesceCompared(converted)
+}
+
+func normalizeChroma(tokens []chroma.Token) []comparedToken {
+	converted := make([]comparedToken, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.Type != chroma.EOFType {
+			converted = append(converted, comparedToken{tok.Type, tok.Value})
+		}
+	}
+	return coalesceCompared(converted)
+}
+
+// coalesceCompared merges adjacent tokens of the same type and drops empty tokens, since syn and Chroma split
+// text into tokens of the same type differently.
+func coalesceCompared(tokens []comparedToken) []comparedToken {
+	var merged []comparedToken
+	for _, tok := range tokens {
+		switch {
+		case tok.Value == "":
+		case len(merged) > 0 && merged[len(merged)-1].Type == tok.Type:
+			merged[len(merged)-1].Value += tok.Value
+		default:
+			merged = append(merged, tok)
+		}
+	}
+	return merged
+}
+
+// firstDivergence returns a description of where the tokens of syn and Chroma for text first differ, or "" if they
+// are the same.
+func firstDivergence(text string, synTokens, chromaTokens []comparedToken) string {
+	offset := 0
+	for i := 0; i < len(synTokens) || i < len(chromaTokens); i++ {
+		var s, c comparedToken
+		if i < len(synTokens) {
+			s = synTokens[i]
+		}
+		if i < len(chromaTokens) {
+			c = chromaTokens[i]
+		}
+		if s != c {
+			line := strings.Count(text[:min(offset, len(text))], "\n") + 1
+			return fmt.Sprintf("token %d, on line %d, differs: syn has %s and Chroma %s", i, line, describe(s, i < len(synTokens)), describe(c, i < len(chromaTokens)))
+		}
+		offset += len(s.Value)
+	}
+	return ""
+}
+
+func describe(tok comparedToken, ok bool) string {
+	if !ok {
+		return "no more tokens"
+	}
+	return tok.String()
+}
+
+func TestFirstDivergence(t *testing.T) {
+	text := "a = 1\nb\n"
+	same := []comparedToken{{chroma.Name, "a"}, {chroma.Text, " = "}, {chroma.LiteralNumber, "1"}, {chroma.Text, "\nb\n"}}
+	assert.Equal(t, "", firstDivergence(text, same, same))
+
+	other := []comparedToken{{chroma.Name, "a"}, {chroma.Text, " = "}, {chroma.LiteralNumber, "1"}, {chroma.Text, "\n"}, {chroma.Name, "b"}}
+	assert.Equal(t, `token 3, on line 1, differs: syn has Text "\nb\n" and Chroma Text "\n"`, firstDivergence(text, same, other))
+	assert.Equal(t, `token 4, on line 2, differs: syn has no more tokens and Chroma Name "b"`, firstDivergence(text, other[:4], other))
+
+	assert.Equal(t, []comparedToken{{chroma.Text, "a b"}, {chroma.Name, "c"}},
+		coalesceCompared([]comparedToken{{chroma.Text, "a"}, {chroma.Name, ""}, {chroma.Text, " b"}, {chroma.Name, "c"}}))
+	assert.Equal(t, []comparedToken{{chroma.LiteralStringInterpol, "{x}"}, {chroma.TextWhitespace, "\n"}},
+		normalizeSyn([]syn.Token{
+			{Type: syn.LiteralStringFormat, Value: []rune("{x}")},
+			{Type: syn.TextEOL, Value: []rune("\n")},
+			{Type: syn.EOFType},
+		}))
+}
@@ -0,0 +1,149 @@
+package chromacompat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alecthomas/chroma/v2"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// Tokenise lexes text with the Chroma lexer l and returns a syn Iterator over the tokens.
+func Tokenise(l chroma.Lexer, text []rune) (syn.Iterator, error) {
+	it, tokeniseErr := l.Tokenise(nil, string(text))
+	if tokeniseErr != nil {
+		return nil, tokeniseErr
+	}
+	return NewIterator(it), nil
+}
+
+// iteratorAdapter implements syn.Iterator by reading tokens from a Chroma iterator.
+type iteratorAdapter struct {
+	it chroma.Iterator
+	// tokens holds the tokens read from it so far.
+	tokens []syn.Token
+	// next is the index in tokens of the token the next call to Next returns.
+	next   int
+	offset int
+	eof    bool
+}
+
+// NewIterator wraps the Chroma iterator it so that it implements syn.Iterator. Since Chroma tokens don't
+// record their position, the Start and End of each token are computed from the lengths of the tokens before
+// it, so it must produce the tokens of a text from its beginning.
+//
+// Chroma iterators can't be moved back, so the tokens read are kept to implement Rewind and SetState.
+func NewIterator(it chroma.Iterator) syn.Iterator {
+	return &iteratorAdapter{it: it}
+}
+
+// fill reads tokens from the Chroma iterator until there are more than n, or the end is reached. It
+// returns false if there are not.
+func (a *iteratorAdapter) fill(n int) bool {
+	for len(a.tokens) <= n && !a.eof {
+		tok := a.it()
+		if tok == chroma.EOF {
+			a.eof = true
+			break
+		}
+
+		value := []rune(tok.Value)
+		if len(value) == 0 {
+			continue
+		}
+		a.tokens = append(a.tokens, syn.Token{
+			Type:  SynTokenType(tok.Type),
+			Value: value,
+			Start: a.offset,
+			End:   a.offset + len(value),
+		})
+		a.offset += len(value)
+	}
+	return len(a.tokens) > n
+}
+
+func (a *iteratorAdapter) eofToken() syn.Token {
+	return syn.Token{Type: syn.EOFType, Start: a.offset, End: a.offset}
+}
+
+func (a *iteratorAdapter) Next() (syn.Token, error) {
+	if !a.fill(a.next) {
+		return a.eofToken(), nil
+	}
+	a.next++
+	return a.tokens[a.next-1], nil
+}
+
+func (a *iteratorAdapter) Peek(n int) (syn.Token, error) {
+	if n < 0 {
+		return syn.Token{}, fmt.Errorf("chromacompat.Iterator.Peek: invalid negative position %d", n)
+	}
+	if !a.fill(a.next + n) {
+		return a.eofToken(), nil
+	}
+	return a.tokens[a.next+n], nil
+}
+
+func (a *iteratorAdapter) Rewind(n int) error {
+	if n < 0 || n > a.next || n > syn.MaxRewind {
+		return fmt.Errorf("chromacompat.Iterator.Rewind: can't rewind %d tokens; only %d can be rewound", n, min(a.next, syn.MaxRewind))
+	}
+	a.next -= n
+	return nil
+}
+
+// Diagnostics returns nil, since Chroma doesn't report the problems it finds while lexing.
+func (a *iteratorAdapter) Diagnostics() []syn.Diagnostic {
+	return nil
+}
+
+func (a *iteratorAdapter) Collect() ([]syn.Token, error) {
+	tokens := []syn.Token{}
+	for a.fill(a.next) {
+		tokens = append(tokens, a.tokens[a.next])
+		a.next++
+	}
+	return tokens, nil
+}
+
+// State returns the state of the iterator, which is the position of the next token.
+func (a *iteratorAdapter) State() syn.IteratorState {
+	index := a.offset
+	if a.fill(a.next) {
+		index = a.tokens[a.next].Start
+	}
+	return &iteratorState{index: index}
+}
+
+func (a *iteratorAdapter) SetState(s syn.IteratorState) {
+	state := s.(*iteratorState)
+
+	for !a.eof && a.offset <= state.index {
+		a.fill(len(a.tokens))
+	}
+	a.next = sort.Search(len(a.tokens), func(i int) bool {
+		return a.tokens[i].Start >= state.index
+	})
+}
+
+type iteratorState struct {
+	// index is the index of the next input rune to process
+	index int
+}
+
+func (s iteratorState) Equal(o syn.IteratorState) bool {
+	other, ok := o.(*iteratorState)
+	if !ok {
+		return false
+	}
+	return s.index == other.index
+}
+
+func (s *iteratorState) SetIndex(i int) {
+	s.index = i
+}
+
+func (s *iteratorState) AddToIndex(delta int) {
+	s.index += delta
+}
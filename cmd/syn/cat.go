@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/jeffwilliams/syn"
+	"github.com/jeffwilliams/syn/lexers"
+	"github.com/jeffwilliams/syn/tui"
+)
+
+const catUsage = "[-n] [-l language] [-color when] [-paging when] [file ...]"
+
+// cat highlights files, or the standard input if none are given or for the file -, to the standard output.
+// When the standard output is a terminal the output is colored and, if it is longer than a screen, shown using
+// $PAGER (less by default). The standard input, and files whose names match no lexer, are highlighted using the
+// lexer that recognises their content, if any.
+func cat(args []string) int {
+	flags := flag.NewFlagSet("cat", flag.ExitOnError)
+	numbers := flags.Bool("n", false, "number the lines")
+	language := flags.String("l", "", "highlight using the lexer for `language` instead of the one matching each file name")
+	color := flags.String("color", "auto", "color the output: auto (if the output is a terminal), always or never (`when`)")
+	paging := flags.String("paging", "auto", "show the output using $PAGER: auto (if the output is a terminal), always or never (`when`)")
+	paths := parseArgs(flags, args)
+
+	tty := isTerminal(os.Stdout)
+	useColor, colorErr := when(*color, tty)
+	usePager, pagingErr := when(*paging, tty)
+	if colorErr != nil || pagingErr != nil {
+		fmt.Fprintln(os.Stderr, "usage: syn cat", catUsage)
+		return 2
+	}
+	var forced *syn.Lexer
+	if *language != "" {
+		if forced = lexers.Get(*language); forced == nil {
+			fmt.Fprintf(os.Stderr, "syn: no lexer for %q\n", *language)
+			return 2
+		}
+	}
+
+	profile := termenv.Ascii
+	if useColor {
+		profile = termenv.ANSI256
+	}
+	lipgloss.SetColorProfile(profile)
+
+	var out io.Writer = os.Stdout
+	var p *pager
+	if usePager {
+		if p = startPager(); p != nil {
+			out = p.in
+		}
+	}
+	w := bufio.NewWriter(out)
+
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	status := 0
+	for _, path := range paths {
+		if catErr := catFile(w, path, forced, *numbers); catErr != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, catErr)
+			status = 1
+		}
+	}
+
+	w.Flush()
+	if p != nil {
+		p.in.Close()
+		p.cmd.Wait()
+	}
+	return status
+}
+
+func catFile(w io.Writer, path string, lexer *syn.Lexer, numbers bool) error {
+	var data []byte
+	var readErr error
+	if path == "-" {
+		data, readErr = io.ReadAll(os.Stdin)
+	} else {
+		data, readErr = os.ReadFile(path)
+	}
+	if readErr != nil {
+		return readErr
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if lexer == nil && path != "-" {
+		lexer = lexers.Match(path)
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(string(data))
+	}
+	text := []rune(string(data))
+	var lines []string
+	if lexer != nil {
+		tokens, lexErr := lexer.TokensAll(text)
+		if lexErr != nil {
+			return lexErr
+		}
+		lines = tui.Lines(text, tokens, tui.DefaultStyle)
+	} else {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	width := len(fmt.Sprint(len(lines)))
+	for i, line := range lines {
+		if numbers {
+			fmt.Fprint(w, numberStyle.Render(fmt.Sprintf("%*d │ ", width, i+1)))
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// when returns whether an option that is auto, always or never is on, where auto means on if tty is set.
+func when(value string, tty bool) (bool, error) {
+	switch value {
+	case "auto":
+		return tty, nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q", value)
+}
+
+func isTerminal(f *os.File) bool {
+	info, statErr := f.Stat()
+	return statErr == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// pager is a running pager, whose standard input is in.
+type pager struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+}
+
+// startPager starts $PAGER, or less if it isn't set. less is told to pass colors through and to exit if the text
+// fits on one screen, unless $LESS is set. It returns nil if the pager can't be started.
+func startPager() *pager {
+	command := strings.Fields(os.Getenv("PAGER"))
+	if len(command) == 0 {
+		command = []string{"less"}
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	in, pipeErr := cmd.StdinPipe()
+	if pipeErr != nil {
+		return nil
+	}
+	if startErr := cmd.Start(); startErr != nil {
+		return nil
+	}
+	return &pager{cmd: cmd, in: in}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatFile(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	path := filepath.Join(t.TempDir(), "x.go")
+	assert.NoError(t, os.WriteFile(path, []byte("package x\n\nvar y = 1\n"), 0o644))
+
+	var b bytes.Buffer
+	assert.NoError(t, catFile(&b, path, nil, true))
+	assert.Equal(t, "1 │ package x\n2 │ \n3 │ var y = 1\n", b.String())
+
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	b.Reset()
+	assert.NoError(t, catFile(&b, path, nil, false))
+	assert.Contains(t, b.String(), "\x1b[1;38;5;204mpackage\x1b[0m x\n")
+}
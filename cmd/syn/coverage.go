@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeffwilliams/syn"
+	"github.com/jeffwilliams/syn/internal/golden"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+const coverageUsage = "[-s] [-l language] [file | directory ...]"
+
+// coverage lexes files, by default the source files of the golden-file tests in lexers/testdata, and reports for
+// each lexer used how many of its rules matched and which never did.
+func coverage(args []string) int {
+	flags := flag.NewFlagSet("coverage", flag.ExitOnError)
+	summary := flags.Bool("s", false, "only print the number of rules matched for each lexer")
+	language := flags.String("l", "", "only report on the lexer for `language`")
+	paths := parseArgs(flags, args)
+	if len(paths) == 0 {
+		paths = []string{"lexers/testdata"}
+	}
+
+	c := syn.NewCoverage()
+	status := 0
+	for _, path := range paths {
+		files, walkErr := sourceFiles(path)
+		if walkErr != nil {
+			fmt.Fprintln(os.Stderr, walkErr)
+			status = 1
+		}
+		for _, file := range files {
+			if lexErr := lexWithCoverage(file, c); lexErr != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", file, lexErr)
+				status = 1
+			}
+		}
+	}
+
+	names := c.Lexers()
+	if *language != "" {
+		lexer := lexers.Get(*language)
+		if lexer == nil {
+			fmt.Fprintf(os.Stderr, "syn: no lexer for %q\n", *language)
+			return 2
+		}
+		names = []string{lexer.Config().Name}
+	}
+	for _, name := range names {
+		if lexer := lexers.Get(name); lexer != nil {
+			writeCoverage(os.Stdout, c, lexer, *summary)
+		}
+	}
+	return status
+}
+
+// sourceFiles returns path if it is a file, or the files in the tree at path apart from hidden files and token
+// dumps.
+func sourceFiles(path string) ([]string, error) {
+	var files []string
+	walkErr := filepath.WalkDir(path, func(p string, d fs.DirEntry, entryErr error) error {
+		if entryErr != nil {
+			return entryErr
+		}
+		if p != path && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && (p == path || !strings.HasSuffix(p, golden.Suffix)) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, walkErr
+}
+
+// lexWithCoverage lexes the file at path, if a lexer matches its name, recording the rules matched in c.
+func lexWithCoverage(path string, c *syn.Coverage) error {
+	lexer := lexers.Match(path)
+	if lexer == nil {
+		return nil
+	}
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return readErr
+	}
+	_, lexErr := lexer.TokensAll([]rune(string(data)), syn.WithCoverage(c))
+	return lexErr
+}
+
+// writeCoverage writes the number of the rules of lexer that matched, followed unless summary is set by the
+// rules that didn't.
+func writeCoverage(w io.Writer, c *syn.Coverage, lexer *syn.Lexer, summary bool) {
+	rules := c.Rules(lexer)
+	unhit := c.Unhit(lexer)
+	if len(rules) == 0 {
+		return
+	}
+	matched := len(rules) - len(unhit)
+	fmt.Fprintf(w, "%s: %d of %d rules matched (%.1f%%)\n", lexer.Config().Name, matched, len(rules),
+		float64(matched)*100/float64(len(rules)))
+	if summary {
+		return
+	}
+	for _, r := range unhit {
+		fmt.Fprintf(w, "\t%s rule %d: /%s/\n", r.State, r.Index, r.Pattern)
+	}
+}
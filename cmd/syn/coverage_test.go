@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+func TestCoverage(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "x.go"), []byte("package x\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "x.go.tokens"), []byte("lexer: Go\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden.go"), []byte("package y\n"), 0o644))
+
+	files, walkErr := sourceFiles(dir)
+	assert.NoError(t, walkErr)
+	assert.Equal(t, []string{filepath.Join(dir, "x.go")}, files)
+
+	c := syn.NewCoverage()
+	assert.NoError(t, lexWithCoverage(files[0], c))
+	assert.Equal(t, []string{"Go"}, c.Lexers())
+
+	var b bytes.Buffer
+	writeCoverage(&b, c, lexers.Get("Go"), true)
+	assert.Equal(t, "Go: 4 of 28 rules matched (14.3%)\n", b.String())
+
+	b.Reset()
+	writeCoverage(&b, c, lexers.Get("Go"), false)
+	assert.Contains(t, b.String(), "\troot rule 4: //(\\\\\\n)?[*](.|\\n)*?[*](\\\\\\n)?//\n")
+	assert.Equal(t, len(c.Unhit(lexers.Get("Go")))+1, strings.Count(b.String(), "\n"))
+}
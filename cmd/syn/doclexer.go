@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jeffwilliams/syn"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+const docLexerUsage = "[-format text|html|dot] [-o file] name"
+
+// docLexer describes a lexer's states, rules and transitions, generated from the compiled lexer, as text, as an
+// HTML page with a graph of the states, or as a Graphviz graph of the states.
+func docLexer(args []string) int {
+	flags := flag.NewFlagSet("doc-lexer", flag.ExitOnError)
+	format := flags.String("format", "text", "write the description as text, html or dot (`format`)")
+	out := flags.String("o", "", "write the description to `file` instead of the standard output")
+	rest := parseArgs(flags, args)
+	if len(rest) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: syn doc-lexer", docLexerUsage)
+		return 2
+	}
+
+	lexer := lexers.Get(rest[0])
+	if lexer == nil {
+		fmt.Fprintf(os.Stderr, "syn: no lexer for %q\n", rest[0])
+		return 2
+	}
+	doc := newLexerDoc(lexer)
+
+	var b bytes.Buffer
+	switch *format {
+	case "text":
+		doc.writeText(&b)
+	case "html":
+		if docErr := doc.writeHTML(&b); docErr != nil {
+			fmt.Fprintln(os.Stderr, docErr)
+			return 1
+		}
+	case "dot":
+		doc.writeDot(&b)
+	default:
+		fmt.Fprintln(os.Stderr, "usage: syn doc-lexer", docLexerUsage)
+		return 2
+	}
+
+	if *out == "" {
+		os.Stdout.Write(b.Bytes())
+		return 0
+	}
+	if writeErr := os.WriteFile(*out, b.Bytes(), 0o644); writeErr != nil {
+		fmt.Fprintln(os.Stderr, writeErr)
+		return 1
+	}
+	return 0
+}
+
+// lexerDoc is the description of a lexer.
+type lexerDoc struct {
+	Config syn.LexerConfig
+	States []syn.StateInfo
+	// Tokens are the types of the tokens the rules produce, sorted by name.
+	Tokens []string
+	// Edges are the transitions between the states made by pushing a state, sorted.
+	Edges []edge
+	// Using are the lexers that the rules lex text with, sorted.
+	Using []string
+}
+
+// edge is a transition from the state From to the state To.
+type edge struct {
+	From, To string
+}
+
+func newLexerDoc(lexer *syn.Lexer) *lexerDoc {
+	d := &lexerDoc{Config: lexer.Config(), States: lexer.States()}
+	exists := map[string]bool{}
+	for _, s := range d.States {
+		exists[s.Name] = true
+	}
+	tokens, edges, using := map[string]bool{}, map[edge]bool{}, map[string]bool{}
+	for _, s := range d.States {
+		for _, r := range s.Rules {
+			for _, t := range r.TokenTypes() {
+				tokens[t.String()] = true
+			}
+			if exists[r.Push] {
+				edges[edge{s.Name, r.Push}] = true
+			}
+			if r.Using != "" {
+				using[r.Using] = true
+			}
+			for _, g := range r.Groups {
+				if g.Using != "" {
+					using[g.Using] = true
+				}
+			}
+		}
+	}
+	d.Tokens, d.Using = sortedKeys(tokens), sortedKeys(using)
+	for e := range edges {
+		d.Edges = append(d.Edges, e)
+	}
+	sort.Slice(d.Edges, func(i, j int) bool {
+		if d.Edges[i].From != d.Edges[j].From {
+			return d.Edges[i].From < d.Edges[j].From
+		}
+		return d.Edges[i].To < d.Edges[j].To
+	})
+	return d
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// action describes what the rule r of the state called state does when it matches, apart from its pattern.
+func action(state string, r syn.RuleInfo) string {
+	var parts []string
+	if r.Token != 0 {
+		parts = append(parts, r.Token.String())
+	}
+	if len(r.Groups) > 0 {
+		groups := make([]string, len(r.Groups))
+		for i, g := range r.Groups {
+			groups[i] = describeUse(g.Token, g.Using, g.UsingSelf)
+		}
+		parts = append(parts, "bygroups("+strings.Join(groups, ", ")+")")
+	}
+	if r.Using != "" || r.UsingSelf != "" {
+		parts = append(parts, describeUse(0, r.Using, r.UsingSelf))
+	}
+	if r.Push != "" {
+		parts = append(parts, "push "+r.Push)
+	}
+	if r.Pop > 0 {
+		parts = append(parts, fmt.Sprintf("pop %d", r.Pop))
+	}
+	if r.State != state {
+		parts = append(parts, fmt.Sprintf("[from %s rule %d]", r.State, r.Index))
+	}
+	return strings.Join(parts, " ")
+}
+
+func describeUse(tok syn.TokenType, using, usingSelf string) string {
+	switch {
+	case using != "":
+		return "using " + using
+	case usingSelf != "":
+		return "using this lexer from " + usingSelf
+	case tok == 0:
+		return "nothing"
+	}
+	return tok.String()
+}
+
+func (d *lexerDoc) writeText(w io.Writer) {
+	c := d.Config
+	fmt.Fprintln(w, c.Name)
+	writeList(w, "Aliases", c.Aliases)
+	writeList(w, "Filenames", c.Filenames)
+	writeList(w, "MIME types", c.MimeTypes)
+	if len(d.States) == 0 {
+		fmt.Fprintln(w, "This lexer combines other lexers and has no rules of its own.")
+		return
+	}
+	writeList(w, "Token types", d.Tokens)
+	writeList(w, "Uses lexers", d.Using)
+
+	fmt.Fprintln(w, "Transitions:")
+	for _, e := range d.Edges {
+		fmt.Fprintf(w, "\t%s -> %s\n", e.From, e.To)
+	}
+	for _, s := range d.States {
+		fmt.Fprintf(w, "\nstate %s", s.Name)
+		switch {
+		case s.Combined:
+			fmt.Fprintf(w, " (combines %s)", strings.Join(s.Includes, ", "))
+		case len(s.Includes) > 0:
+			fmt.Fprintf(w, " (includes %s)", strings.Join(s.Includes, ", "))
+		}
+		fmt.Fprintln(w)
+		for i, r := range s.Rules {
+			fmt.Fprintf(w, "\t%d\t/%s/\t%s\n", i, r.Pattern, action(s.Name, r))
+		}
+	}
+}
+
+func writeList(w io.Writer, label string, items []string) {
+	if len(items) > 0 {
+		fmt.Fprintf(w, "%s: %s\n", label, strings.Join(items, ", "))
+	}
+}
+
+func (d *lexerDoc) writeDot(w io.Writer) {
+	fmt.Fprintf(w, "digraph %q {\n\tnode [shape=box];\n", d.Config.Name)
+	for _, s := range d.States {
+		fmt.Fprintf(w, "\t%q;\n", s.Name)
+	}
+	for _, e := range d.Edges {
+		fmt.Fprintf(w, "\t%q -> %q;\n", e.From, e.To)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (d *lexerDoc) writeHTML(w io.Writer) error {
+	return docTemplate.Execute(w, struct {
+		*lexerDoc
+		Graph template.HTML
+	}{d, template.HTML(d.graphSVG())})
+}
+
+// Dimensions of the graph of the states drawn by graphSVG.
+const (
+	nodeHeight = 24
+	charWidth  = 7
+	rowGap     = 48
+	columnGap  = 16
+)
+
+// graphSVG draws the states as boxes in rows, by the number of transitions from root needed to reach them, with
+// arrows for the transitions. States that can't be reached from root are in the last row.
+func (d *lexerDoc) graphSVG() string {
+	depth := map[string]int{}
+	if len(d.States) > 0 {
+		depth[d.States[0].Name] = 0
+		queue := []string{d.States[0].Name}
+		for len(queue) > 0 {
+			from := queue[0]
+			queue = queue[1:]
+			for _, e := range d.Edges {
+				if _, seen := depth[e.To]; e.From == from && !seen {
+					depth[e.To] = depth[from] + 1
+					queue = append(queue, e.To)
+				}
+			}
+		}
+	}
+	var rows [][]string
+	unreachable := len(depth) < len(d.States)
+	for _, s := range d.States {
+		row, ok := depth[s.Name]
+		if !ok {
+			row = -1
+		}
+		for row >= len(rows) {
+			rows = append(rows, nil)
+		}
+		if row >= 0 {
+			rows[row] = append(rows[row], s.Name)
+		}
+	}
+	if unreachable {
+		var last []string
+		for _, s := range d.States {
+			if _, ok := depth[s.Name]; !ok {
+				last = append(last, s.Name)
+			}
+		}
+		rows = append(rows, last)
+	}
+
+	type box struct{ x, y, w int }
+	boxes := map[string]box{}
+	width := 0
+	for i, row := range rows {
+		x := columnGap
+		for _, name := range row {
+			w := len(name)*charWidth + 16
+			boxes[name] = box{x, columnGap + i*(nodeHeight+rowGap), w}
+			x += w + columnGap
+		}
+		width = max(width, x)
+	}
+	height := columnGap*2 + len(rows)*(nodeHeight+rowGap) - rowGap
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" class="graph">`, width, height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0L10,5L0,10z"/></marker></defs>`)
+	for _, e := range d.Edges {
+		from, to := boxes[e.From], boxes[e.To]
+		if e.From == e.To {
+			x, y := from.x+from.w, from.y+nodeHeight/2
+			fmt.Fprintf(&b, `<path d="M%d,%d c16,-12 16,24 0,12" marker-end="url(#arrow)"/>`, x, y-6)
+			continue
+		}
+		x1, y1 := from.x+from.w/2, from.y+nodeHeight
+		x2, y2 := to.x+to.w/2, to.y
+		if to.y <= from.y {
+			// Transitions back up go from the top of the state to the bottom of the other.
+			y1, y2 = from.y, to.y+nodeHeight
+		}
+		fmt.Fprintf(&b, `<path d="M%d,%d Q%d,%d %d,%d" marker-end="url(#arrow)"/>`, x1, y1, (x1+x2)/2+(y2-y1)/4, (y1+y2)/2, x2, y2)
+	}
+	for _, s := range d.States {
+		bx := boxes[s.Name]
+		fmt.Fprintf(&b, `<a href="#state-%s"><rect x="%d" y="%d" width="%d" height="%d" rx="4"/><text x="%d" y="%d">%s</text></a>`,
+			template.HTMLEscapeString(s.Name), bx.x, bx.y, bx.w, nodeHeight, bx.x+8, bx.y+16, template.HTMLEscapeString(s.Name))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+var docTemplate = template.Must(template.New("doc").Funcs(template.FuncMap{"action": action}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Config.Name}} lexer</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+code, td.pattern { font-family: monospace; white-space: pre-wrap; word-break: break-all; }
+table { border-collapse: collapse; }
+td, th { border-bottom: 1px solid #8884; padding: 0.2em 0.6em; text-align: left; vertical-align: top; }
+.graph rect { fill: #eef; stroke: #446; }
+.graph text { font: 12px monospace; }
+.graph path { fill: none; stroke: #446; }
+.graph marker path { fill: #446; }
+.included { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Config.Name}}</h1>
+<dl>
+{{with .Config.Aliases}}<dt>Aliases</dt><dd>{{range $i, $a := .}}{{if $i}}, {{end}}<code>{{$a}}</code>{{end}}</dd>{{end}}
+{{with .Config.Filenames}}<dt>Filenames</dt><dd>{{range $i, $a := .}}{{if $i}}, {{end}}<code>{{$a}}</code>{{end}}</dd>{{end}}
+{{with .Config.MimeTypes}}<dt>MIME types</dt><dd>{{range $i, $a := .}}{{if $i}}, {{end}}<code>{{$a}}</code>{{end}}</dd>{{end}}
+{{with .Tokens}}<dt>Token types</dt><dd>{{range $i, $a := .}}{{if $i}}, {{end}}{{$a}}{{end}}</dd>{{end}}
+{{with .Using}}<dt>Uses lexers</dt><dd>{{range $i, $a := .}}{{if $i}}, {{end}}{{$a}}{{end}}</dd>{{end}}
+</dl>
+{{if .States}}<h2>States</h2>
+{{.Graph}}
+{{range .States}}{{$state := .Name}}<h3 id="state-{{.Name}}">{{.Name}}</h3>
+{{if .Combined}}<p>Combines {{range $i, $s := .Includes}}{{if $i}}, {{end}}<a href="#state-{{$s}}">{{$s}}</a>{{end}}.</p>
+{{else if .Includes}}<p>Includes {{range $i, $s := .Includes}}{{if $i}}, {{end}}<a href="#state-{{$s}}">{{$s}}</a>{{end}}.</p>
+{{end}}<table>
+<tr><th>#</th><th>Pattern</th><th>Action</th></tr>
+{{range $i, $r := .Rules}}<tr{{if ne $r.State $state}} class="included"{{end}}><td>{{$i}}</td><td class="pattern">{{$r.Pattern}}</td><td>{{action $state $r}}</td></tr>
+{{end}}</table>
+{{end}}{{else}}<p>This lexer combines other lexers and has no rules of its own.</p>
+{{end}}</body>
+</html>
+`))
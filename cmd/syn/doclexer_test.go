@@ -0,0 +1,40 @@
+//go:build !syn_minimal
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+func TestDocLexer(t *testing.T) {
+	d := newLexerDoc(lexers.Get("JSON"))
+	assert.Contains(t, d.Edges, edge{"root", "objectvalue"})
+	assert.Contains(t, d.Edges, edge{"arrayvalue", "arrayvalue"})
+	assert.Contains(t, d.Tokens, "LiteralStringDouble")
+
+	var b bytes.Buffer
+	d.writeText(&b)
+	assert.Contains(t, b.String(), "JSON\nAliases: json\n")
+	assert.Contains(t, b.String(), "\nstate root (includes value)\n\t0\t/\\s+/\tText [from whitespace rule 0]\n")
+	assert.Contains(t, b.String(), "\troot -> arrayvalue\n")
+
+	b.Reset()
+	d.writeDot(&b)
+	assert.Contains(t, b.String(), "digraph \"JSON\" {\n")
+	assert.Contains(t, b.String(), "\t\"root\" -> \"objectvalue\";\n")
+
+	b.Reset()
+	assert.NoError(t, d.writeHTML(&b))
+	assert.Contains(t, b.String(), `<svg xmlns="http://www.w3.org/2000/svg"`)
+	assert.Contains(t, b.String(), `<h3 id="state-objectvalue">objectvalue</h3>`)
+	assert.Contains(t, b.String(), `<a href="#state-root"><rect`)
+
+	b.Reset()
+	newLexerDoc(lexers.Get("Literate Markdown")).writeText(&b)
+	assert.Contains(t, b.String(), "no rules of its own")
+}
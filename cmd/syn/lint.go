@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeffwilliams/syn/internal/config"
+	"github.com/jeffwilliams/syn/internal/lint"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+// embeddedBaseline lists the problems known to be reported for the embedded lexer definitions, which are
+// ignored when checking them.
+//
+//go:embed lint-baseline.txt
+var embeddedBaseline string
+
+// lintGrammars checks the embedded lexer definitions, those in a directory, or the given files, printing the
+// problems found other than those listed in the baseline. The exit status is 1 if there are any, so that it can
+// be used in CI.
+func lintGrammars(args []string) int {
+	flags := flag.NewFlagSet("lint-grammars", flag.ExitOnError)
+	dir := flags.String("dir", "", "check the XML definitions in `directory` instead of the embedded ones")
+	checks := flags.String("checks", "invalid,backtracking,shadowed,unused", "comma-separated `list` of the kinds of problem to report")
+	baselinePath := flags.String("baseline", "", "ignore the problems listed in `file`; by default those known in the embedded definitions are ignored when checking them")
+	flags.Parse(args)
+
+	enabled := map[lint.Kind]bool{}
+	for _, name := range strings.Split(*checks, ",") {
+		kind, ok := lint.ParseKind(strings.TrimSpace(name))
+		if !ok {
+			fmt.Fprintf(os.Stderr, "syn: unknown check %q\n", name)
+			return 2
+		}
+		enabled[kind] = true
+	}
+
+	fsys := lexers.Definitions()
+	if *dir != "" {
+		fsys = os.DirFS(*dir)
+	}
+	open := fsys.Open
+	paths := flags.Args()
+	if len(paths) == 0 {
+		var globErr error
+		if paths, globErr = fs.Glob(fsys, "*.xml"); globErr != nil {
+			fmt.Fprintln(os.Stderr, globErr)
+			return 2
+		}
+	} else if *dir == "" {
+		// Files named on the command line are opened as given, so that they may be absolute or outside the
+		// current directory.
+		open = func(name string) (fs.File, error) { return os.Open(name) }
+	} else {
+		for i, p := range paths {
+			paths[i] = filepath.ToSlash(p)
+		}
+	}
+
+	var baselineFile io.Reader
+	switch {
+	case *baselinePath != "":
+		f, openErr := os.Open(*baselinePath)
+		if openErr != nil {
+			fmt.Fprintln(os.Stderr, openErr)
+			return 2
+		}
+		defer f.Close()
+		baselineFile = f
+	case *dir == "" && len(flags.Args()) == 0:
+		baselineFile = strings.NewReader(embeddedBaseline)
+	}
+	baseline := map[string]bool{}
+	if baselineFile != nil {
+		var readErr error
+		if baseline, readErr = readBaseline(baselineFile); readErr != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *baselinePath, readErr)
+			return 2
+		}
+	}
+
+	status := 0
+	for _, p := range paths {
+		problems, lintErr := lintFile(open, p)
+		if lintErr != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", p, lintErr)
+			status = 1
+			continue
+		}
+		for _, problem := range problems {
+			if !enabled[problem.Kind] || baseline[baselineKey(p, problem)] {
+				continue
+			}
+			fmt.Printf("%s: %v\n", p, problem)
+			status = 1
+		}
+	}
+	return status
+}
+
+func lintFile(open func(name string) (fs.File, error), path string) ([]lint.Problem, error) {
+	f, openErr := open(path)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer f.Close()
+
+	lex, decodeErr := config.DecodeLexer(f)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	return lint.Lint(lex), nil
+}
+
+// baselineKey returns the line of a baseline that lists the problem p in the definition at file: the base name
+// of the file, the location of the problem and its kind, as "file: location: kind". The message is left out so
+// that a baseline doesn't need updating when the wording of a message changes.
+func baselineKey(file string, p lint.Problem) string {
+	return path.Base(filepath.ToSlash(file)) + ": " + strings.TrimSuffix(p.String(), ": "+p.Message)
+}
+
+// readBaseline reads a baseline of known problems, one per line as made by baselineKey. Blank lines and lines
+// starting with # are ignored.
+func readBaseline(r io.Reader) (map[string]bool, error) {
+	baseline := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		baseline[line] = true
+	}
+	return baseline, scanner.Err()
+}
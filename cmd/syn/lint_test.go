@@ -0,0 +1,77 @@
+//go:build !syn_minimal
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+// TestLintEmbedded checks that lint-grammars reports no problems in the embedded lexer definitions other than
+// those in the baseline, and that each problem in the baseline is still reported.
+func TestLintEmbedded(t *testing.T) {
+	baseline, readErr := readBaseline(strings.NewReader(embeddedBaseline))
+	assert.NoError(t, readErr)
+	assert.NotEmpty(t, baseline)
+
+	fsys := lexers.Definitions()
+	paths, globErr := fs.Glob(fsys, "*.xml")
+	assert.NoError(t, globErr)
+	assert.NotEmpty(t, paths)
+
+	reported := map[string]bool{}
+	for _, path := range paths {
+		problems, lintErr := lintFile(fsys.Open, path)
+		assert.NoError(t, lintErr, path)
+		for _, p := range problems {
+			key := baselineKey(path, p)
+			reported[key] = true
+			assert.True(t, baseline[key], "%s: %v", path, p)
+		}
+	}
+	for key := range baseline {
+		assert.True(t, reported[key], "%s is no longer reported; remove it from lint-baseline.txt", key)
+	}
+
+	assert.Equal(t, 0, lintGrammars(nil))
+}
+
+// TestLintFiles checks that files named on the command line are found outside the current directory, and that
+// a baseline given with -baseline is applied to them.
+func TestLintFiles(t *testing.T) {
+	dir := t.TempDir()
+	def := filepath.Join(dir, "test.xml")
+	writeErr := os.WriteFile(def, []byte(`<lexer>
+  <config>
+    <name>Test</name>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern="(\d+\s*)+">
+        <token type="Name"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>
+`), 0o644)
+	assert.NoError(t, writeErr)
+	assert.Equal(t, 1, lintGrammars([]string{def}))
+
+	baseline := filepath.Join(dir, "baseline.txt")
+	writeErr = os.WriteFile(baseline, []byte("# Known problems.\ntest.xml: state \"root\" rule 0: backtracking\n"), 0o644)
+	assert.NoError(t, writeErr)
+	assert.Equal(t, 0, lintGrammars([]string{"-baseline", baseline, def}))
+
+	wd, wdErr := os.Getwd()
+	assert.NoError(t, wdErr)
+	rel, relErr := filepath.Rel(wd, def)
+	assert.NoError(t, relErr)
+	assert.Equal(t, 0, lintGrammars([]string{"-baseline", baseline, rel}))
+}
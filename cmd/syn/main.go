@@ -0,0 +1,80 @@
+// Command syn provides tools for working with syn lexers and their XML definitions.
+//
+// Usage:
+//
+//	syn <command> [arguments]
+//
+// The commands are:
+//
+//	cat            highlight files to the terminal, with optional line numbers and paging
+//	coverage       report the rules of the lexers that never match when lexing a set of files
+//	doc-lexer      describe a lexer's states, rules and transitions as text, HTML or a Graphviz graph
+//	lint-grammars  check lexer definitions for invalid patterns, backtracking risks, shadowed rules and unused states
+//	record         print the token dumps of files used by the golden-file tests
+//	render         highlight the files in a source tree to a browsable tree of HTML pages
+//	verify         check that the token dumps written by record still match
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command is a subcommand of syn. run returns the exit status.
+type command struct {
+	usage string
+	run   func(args []string) int
+}
+
+var commands = map[string]command{
+	"cat":           {catUsage, cat},
+	"coverage":      {coverageUsage, coverage},
+	"doc-lexer":     {docLexerUsage, docLexer},
+	"lint-grammars": {"[-dir directory] [-checks list] [-baseline file] [file.xml ...]", lintGrammars},
+	"record":        {recordUsage, record},
+	"render":        {renderUsage, render},
+	"verify":        {verifyUsage, verify},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "syn: unknown command %q\n", os.Args[1])
+		usage()
+	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: syn <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", name, commands[name].usage)
+	}
+	os.Exit(2)
+}
+
+// parseArgs parses args using flags, allowing the flags to follow the other arguments, as in
+// "syn render src -o out", and returns the other arguments.
+func parseArgs(flags *flag.FlagSet, args []string) []string {
+	var rest []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return rest
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
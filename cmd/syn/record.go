@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jeffwilliams/syn/internal/golden"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+const (
+	recordUsage = "[-l language] [-w] file ..."
+	verifyUsage = "[file.tokens | directory ...]"
+)
+
+// record prints the canonical dumps of the tokens of files, as used by the golden-file tests in lexers/testdata,
+// or with -w writes each next to its file.
+func record(args []string) int {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	language := flags.String("l", "", "lex using the lexer for `language` instead of the one matching each file name")
+	write := flags.Bool("w", false, "write the dump of each file to the file with "+golden.Suffix+" added to its name")
+	paths := parseArgs(flags, args)
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: syn record", recordUsage)
+		return 2
+	}
+
+	status := 0
+	for _, path := range paths {
+		dump, recordErr := golden.Record(lexers.GlobalLexerRegistry, path, *language)
+		if recordErr == nil && *write {
+			recordErr = os.WriteFile(path+golden.Suffix, dump, 0o644)
+		} else if recordErr == nil {
+			os.Stdout.Write(dump)
+		}
+		if recordErr != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, recordErr)
+			status = 1
+		}
+	}
+	return status
+}
+
+// verify checks the dumps written by record against the tokens produced now for their files. With no arguments
+// it checks those in lexers/testdata.
+func verify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	paths := parseArgs(flags, args)
+	if len(paths) == 0 {
+		paths = []string{"lexers/testdata"}
+	}
+
+	status, checked := 0, 0
+	for _, path := range paths {
+		dumps := []string{path}
+		if !strings.HasSuffix(path, golden.Suffix) {
+			var globErr error
+			if dumps, globErr = golden.Fixtures(path); globErr != nil {
+				fmt.Fprintln(os.Stderr, globErr)
+				status = 1
+				continue
+			}
+		}
+		for _, dump := range dumps {
+			checked++
+			if verifyErr := golden.Verify(lexers.GlobalLexerRegistry, dump); verifyErr != nil {
+				fmt.Println(verifyErr)
+				status = 1
+			}
+		}
+	}
+	if status == 0 {
+		fmt.Printf("%d token dumps match\n", checked)
+	}
+	return status
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+
+	"github.com/jeffwilliams/syn/chromacompat"
+	"github.com/jeffwilliams/syn/lexers"
+)
+
+const renderUsage = "[-o directory] [-style name] [-n=false] directory"
+
+// render highlights the files in a source tree to HTML, writing a tree of pages with an index for each
+// directory. Files that no lexer matches, binary files and hidden files and directories are left out.
+func render(args []string) int {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	out := flags.String("o", "syn-html", "write the pages to `directory`")
+	styleName := flags.String("style", "github", "highlight using the Chroma style `name`")
+	lineNumbers := flags.Bool("n", true, "number the lines")
+	rest := parseArgs(flags, args)
+	if len(rest) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: syn render", renderUsage)
+		return 2
+	}
+
+	style, ok := styles.Registry[*styleName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "syn: unknown style %q; the styles are %s\n", *styleName, strings.Join(styles.Names(), ", "))
+		return 2
+	}
+
+	r := &siteRenderer{
+		src:   rest[0],
+		out:   *out,
+		style: style,
+		formatter: html.New(html.WithClasses(true), html.WithLineNumbers(*lineNumbers), html.LineNumbersInTable(true),
+			html.WithLinkableLineNumbers(true, "L"), html.TabWidth(4)),
+		dirs: map[string][]indexEntry{},
+	}
+	if renderErr := r.run(); renderErr != nil {
+		fmt.Fprintln(os.Stderr, renderErr)
+		return 1
+	}
+	return r.status
+}
+
+type siteRenderer struct {
+	src, out  string
+	style     *chroma.Style
+	formatter *html.Formatter
+	// dirs holds the entries of the index of each directory, by its path relative to src, using slashes.
+	dirs   map[string][]indexEntry
+	status int
+}
+
+// indexEntry is a file or subdirectory listed in the index of a directory.
+type indexEntry struct {
+	Name     string
+	Href     string
+	Language string
+	Dir      bool
+}
+
+func (r *siteRenderer) run() error {
+	walkErr := filepath.WalkDir(r.src, func(p string, d fs.DirEntry, entryErr error) error {
+		if entryErr != nil {
+			return entryErr
+		}
+		rel, relErr := filepath.Rel(r.src, p)
+		if relErr != nil {
+			return relErr
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if sameFile(p, r.out) {
+				// Don't render the pages if they are being written within the source tree.
+				return filepath.SkipDir
+			}
+			r.dirs[rel] = r.dirs[rel]
+			if rel != "." {
+				parent := path.Dir(rel)
+				r.dirs[parent] = append(r.dirs[parent], indexEntry{Name: d.Name() + "/", Href: d.Name() + "/index.html", Dir: true})
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if fileErr := r.renderFile(p, rel); fileErr != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", p, fileErr)
+			r.status = 1
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+
+	for dir, entries := range r.dirs {
+		if indexErr := r.writeIndex(dir, entries); indexErr != nil {
+			return indexErr
+		}
+	}
+	return r.writeCSS()
+}
+
+// renderFile writes the page for the file at p, whose path relative to src is rel.
+func (r *siteRenderer) renderFile(p, rel string) error {
+	lexer := lexers.Match(p)
+	if lexer == nil {
+		return nil
+	}
+	data, readErr := os.ReadFile(p)
+	if readErr != nil {
+		return readErr
+	}
+	if !isText(data) {
+		return nil
+	}
+
+	var body bytes.Buffer
+	it, itErr := chromacompat.IteratorErr(lexer.Tokenise([]rune(string(data))))
+	if formatErr := r.formatter.Format(&body, r.style, it); formatErr != nil {
+		return formatErr
+	}
+	if lexErr := itErr(); lexErr != nil {
+		return lexErr
+	}
+
+	name := path.Base(rel)
+	language := lexer.Config().Name
+	dir := path.Dir(rel)
+	r.dirs[dir] = append(r.dirs[dir], indexEntry{Name: name, Href: name + ".html", Language: language})
+	return r.writePage(rel+".html", rel, page{
+		Language: language,
+		Body:     template.HTML(body.String()),
+	})
+}
+
+func sameFile(a, b string) bool {
+	ai, aErr := os.Stat(a)
+	bi, bErr := os.Stat(b)
+	return aErr == nil && bErr == nil && os.SameFile(ai, bi)
+}
+
+// isText returns whether data looks like text rather than a binary file.
+func isText(data []byte) bool {
+	return utf8.Valid(data) && !bytes.ContainsRune(data[:min(len(data), 8000)], 0)
+}
+
+func (r *siteRenderer) writeIndex(dir string, entries []indexEntry) error {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Dir != entries[j].Dir {
+			return entries[i].Dir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return r.writePage(path.Join(dir, "index.html"), dir, page{Entries: entries, Index: true})
+}
+
+func (r *siteRenderer) writeCSS() error {
+	var css bytes.Buffer
+	css.WriteString(siteCSS)
+	if cssErr := r.formatter.WriteCSS(&css, r.style); cssErr != nil {
+		return cssErr
+	}
+	return os.WriteFile(filepath.Join(r.out, "style.css"), css.Bytes(), 0o644)
+}
+
+// page holds the contents of a page: either an index of a directory or a highlighted file.
+type page struct {
+	Title    string
+	Language string
+	Body     template.HTML
+	Index    bool
+	Entries  []indexEntry
+	// Root is the relative URL of the output directory, and Crumbs link to the indexes of the directories that
+	// the file or directory is in.
+	Root   string
+	Crumbs []indexEntry
+}
+
+// writePage writes the page at rel, a slash-separated path relative to the output directory, for subject, the
+// path relative to src of the file or directory the page shows.
+func (r *siteRenderer) writePage(rel, subject string, p page) error {
+	dir := path.Dir(rel)
+	p.Root = strings.Repeat("../", depth(dir))
+	p.Title = r.name(subject)
+	for a := subject; a != "."; {
+		a = path.Dir(a)
+		href := strings.Repeat("../", depth(dir)-depth(a)) + "index.html"
+		p.Crumbs = append([]indexEntry{{Name: r.name(a), Href: href}}, p.Crumbs...)
+	}
+
+	var buf bytes.Buffer
+	if execErr := pageTemplate.Execute(&buf, p); execErr != nil {
+		return execErr
+	}
+	file := filepath.Join(r.out, filepath.FromSlash(rel))
+	if mkdirErr := os.MkdirAll(filepath.Dir(file), 0o755); mkdirErr != nil {
+		return mkdirErr
+	}
+	return os.WriteFile(file, buf.Bytes(), 0o644)
+}
+
+// name returns the name shown for the file or directory at rel.
+func (r *siteRenderer) name(rel string) string {
+	if rel == "." {
+		if abs, absErr := filepath.Abs(r.src); absErr == nil {
+			return filepath.Base(abs)
+		}
+		return r.src
+	}
+	return path.Base(rel)
+}
+
+// depth returns the number of directories in the slash-separated relative path dir.
+func depth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body class="chroma">
+<nav>{{range .Crumbs}}<a href="{{.Href}}">{{.Name}}</a> / {{end}}<b>{{.Title}}</b>{{with .Language}} <span class="language">{{.}}</span>{{end}}</nav>
+{{if .Index}}<ul>
+{{range .Entries}}<li><a href="{{.Href}}">{{.Name}}</a>{{with .Language}} <span class="language">{{.}}</span>{{end}}</li>
+{{end}}</ul>
+{{else}}{{.Body}}{{end}}
+</body>
+</html>
+`))
+
+const siteCSS = `body { margin: 0; font-family: sans-serif; }
+nav { padding: 0.5em 1em; border-bottom: 1px solid #8884; }
+ul { font-family: monospace; }
+.language { color: #888; font-size: smaller; }
+pre { margin: 0; padding: 0.5em; }
+`
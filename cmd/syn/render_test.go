@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	src, out := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		"main.go":       "package main\n\nfunc main() {}\n",
+		"sub/script.py": "print('hi')\n",
+		"data.bin":      "\x00\x01",
+		".hidden/x.go":  "package x\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(src, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	assert.Equal(t, 0, render([]string{src, "-o", out}))
+
+	for _, name := range []string{"index.html", "style.css", "main.go.html", "sub/index.html", "sub/script.py.html"} {
+		assert.FileExists(t, filepath.Join(out, name))
+	}
+	for _, name := range []string{"data.bin.html", ".hidden"} {
+		assert.NoFileExists(t, filepath.Join(out, name))
+	}
+
+	page, _ := os.ReadFile(filepath.Join(out, "sub/script.py.html"))
+	assert.Contains(t, string(page), `<a href="../index.html">`)
+	assert.Contains(t, string(page), `<span class="nb">print</span>`)
+	index, _ := os.ReadFile(filepath.Join(out, "index.html"))
+	assert.Contains(t, string(index), `<a href="sub/index.html">sub/</a>`)
+	assert.Contains(t, string(index), `<a href="main.go.html">main.go</a> <span class="language">Go</span>`)
+}
+
+func TestRenderMissingDir(t *testing.T) {
+	out := t.TempDir()
+	assert.Equal(t, 1, render([]string{filepath.Join(out, "missing"), "-o", out}))
+	assert.NoFileExists(t, filepath.Join(out, "index.html"))
+}
@@ -3,17 +3,15 @@ package syn
 import (
 	"bytes"
 	"fmt"
-
-	"github.com/ddkwork/golibrary/mylog"
 )
 
 type coalescer struct {
-	it       Iterator
+	it       tokenSource
 	accum    Token
 	accumSet bool
 }
 
-func coalesce(in Iterator) Iterator {
+func coalesce(in tokenSource) tokenSource {
 	return &coalescer{
 		it: in,
 	}
@@ -25,9 +23,10 @@ func (c *coalescer) Next() (tok Token, err error) {
 	}
 
 	for {
-		tok = mylog.Check2(c.it.Next())
-		if err != nil || (c.accumSet && c.accum.Type == EOFType) {
-			return
+		var nextErr error
+		tok, nextErr = c.it.Next()
+		if nextErr != nil || (c.accumSet && c.accum.Type == EOFType) {
+			return tok, nextErr
 		}
 
 		if !c.accumSet {
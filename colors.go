@@ -0,0 +1,209 @@
+package syn
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxColorTokens is the most tokens that the filter returned by NewColorFilter holds back while looking for
+// the end of a color function such as rgb(...).
+const maxColorTokens = 32
+
+// NewColorFilter returns a Filter that finds color literals: hex colors such as #f80 and #ff8800, and the
+// functions rgb(), rgba(), hsl() and hsla() with constant arguments. Each is replaced by a single token of type
+// LiteralColor whose Meta is the color as a color.NRGBA, so that an editor can display a swatch. Hex colors are
+// recognized in Number tokens, so that the ids in selectors such as #add are not mistaken for colors.
+//
+// This filter is used by default by the lexers for CSS, SCSS, Sass and Stylus in the registry of the
+// lexers package, and can be used with any other lexer using WithFilters.
+func NewColorFilter() Filter {
+	return &colorFilter{}
+}
+
+type colorFilter struct {
+	// held holds the tokens of a color function, starting with its name, that have been seen so far.
+	held []Token
+}
+
+func (f *colorFilter) Filter(tok Token) []Token {
+	if len(f.held) == 0 {
+		return f.start(tok)
+	}
+
+	if len(f.held) == 1 {
+		if tok.Type.InCategory(Punctuation) && len(tok.Value) > 0 && tok.Value[0] == '(' && !strings.ContainsRune(string(tok.Value), ')') {
+			f.held = append(f.held, tok)
+			return nil
+		}
+		return append(f.Flush(), f.start(tok)...)
+	}
+
+	i := indexRune(tok.Value, ')')
+	if i < 0 || (!tok.Type.InCategory(Punctuation) && !tok.Type.InCategory(Operator)) {
+		if len(f.held) == maxColorTokens {
+			return append(f.Flush(), tok)
+		}
+		f.held = append(f.held, tok)
+		return nil
+	}
+
+	first := f.held[0]
+	end := tok.Start + i + 1
+	value := first.Value[0 : end-first.Start]
+	c, ok := parseColorFunc(string(value))
+	if !ok {
+		return append(f.Flush(), tok)
+	}
+
+	f.held = nil
+	toks := []Token{{Type: LiteralColor, Value: value, Start: first.Start, End: end, Meta: c}}
+	if end < tok.End {
+		toks = append(toks, Token{Type: tok.Type, Value: tok.Value[i+1:], Start: end, End: tok.End, Meta: tok.Meta})
+	}
+	return toks
+}
+
+// start handles a token when no tokens are held.
+func (f *colorFilter) start(tok Token) []Token {
+	switch {
+	case tok.Type.InCategory(Name) && isColorFunc(string(tok.Value)):
+		f.held = []Token{tok}
+		return nil
+	case tok.Type.InSubCategory(LiteralNumber):
+		if c, ok := parseHexColor(string(tok.Value)); ok {
+			tok.Type = LiteralColor
+			tok.Meta = c
+		}
+	}
+	return []Token{tok}
+}
+
+func (f *colorFilter) Flush() []Token {
+	held := f.held
+	f.held = nil
+	return held
+}
+
+func (f *colorFilter) Clone() Filter {
+	return &colorFilter{held: append([]Token(nil), f.held...)}
+}
+
+func indexRune(s []rune, r rune) int {
+	for i, c := range s {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func isColorFunc(name string) bool {
+	switch strings.ToLower(name) {
+	case "rgb", "rgba", "hsl", "hsla":
+		return true
+	}
+	return false
+}
+
+// parseHexColor parses a color in one of the forms #rgb, #rgba, #rrggbb and #rrggbbaa.
+func parseHexColor(s string) (c color.NRGBA, ok bool) {
+	if len(s) < 4 || s[0] != '#' {
+		return
+	}
+	hex := s[1:]
+	switch len(hex) {
+	case 3, 4:
+		var b strings.Builder
+		for _, r := range hex {
+			b.WriteRune(r)
+			b.WriteRune(r)
+		}
+		hex = b.String()
+	case 6, 8:
+	default:
+		return
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	v, parseErr := strconv.ParseUint(hex, 16, 32)
+	if parseErr != nil {
+		return
+	}
+
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
+// parseColorFunc parses a call of one of the functions rgb, rgba, hsl and hsla with constant arguments,
+// which may be separated by commas or, in the newer form, by spaces with the alpha after a /.
+func parseColorFunc(s string) (c color.NRGBA, ok bool) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(s[:open]))
+	args := strings.FieldsFunc(s[open+1:len(s)-1], func(r rune) bool {
+		return r == ',' || r == '/' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(args) != 3 && len(args) != 4 {
+		return
+	}
+
+	alpha := 1.0
+	if len(args) == 4 {
+		if alpha, ok = parseColorArg(args[3], 1); !ok {
+			return
+		}
+	}
+
+	var v [3]float64
+	if strings.HasPrefix(name, "rgb") {
+		for i := range v {
+			if v[i], ok = parseColorArg(args[i], 255); !ok {
+				return
+			}
+		}
+	} else {
+		h, parseErr := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+		sat, ok1 := parseColorArg(args[1], 1)
+		light, ok2 := parseColorArg(args[2], 1)
+		if parseErr != nil || !ok1 || !ok2 {
+			return c, false
+		}
+		v = hslToRGB(h, sat, light)
+	}
+
+	return color.NRGBA{R: channel(v[0]), G: channel(v[1]), B: channel(v[2]), A: channel(alpha * 255)}, true
+}
+
+// parseColorArg parses a number or percentage; a percentage is scaled so that 100% is max.
+func parseColorArg(s string, max float64) (float64, bool) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		f, parseErr := strconv.ParseFloat(p, 64)
+		return f / 100 * max, parseErr == nil
+	}
+	f, parseErr := strconv.ParseFloat(s, 64)
+	if max == 1 && f > 1 && parseErr == nil {
+		// Saturation and lightness given without %, as allowed by newer CSS.
+		f /= 100
+	}
+	return f, parseErr == nil
+}
+
+// hslToRGB converts h in degrees and s and l from 0 to 1 to red, green and blue from 0 to 255.
+func hslToRGB(h, s, l float64) [3]float64 {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	f := func(n float64) float64 {
+		k := math.Mod(n+h*12, 12)
+		a := s * math.Min(l, 1-l)
+		return (l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))) * 255
+	}
+	return [3]float64{f(0), f(8), f(4)}
+}
+
+func channel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
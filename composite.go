@@ -0,0 +1,116 @@
+package syn
+
+import (
+	"github.com/ddkwork/golibrary/mylog"
+)
+
+// Segment is a part of a document that is lexed by a single lexer, found by a Segmenter.
+type Segment struct {
+	// Start and End are the indexes in the text of the first rune of the segment and of the rune after it.
+	Start, End int
+	// Lexer lexes the text of the segment. If it is nil the segment is returned as a single token of type Type,
+	// which is used for text that none of the lexers should see, such as the markers between the cells of a
+	// notebook.
+	Lexer *Lexer
+	Type  TokenType
+	// Continues is set if the segment continues the text of the previous segment with the same Lexer, so that
+	// the two are lexed as one text. This is used when the text of one lexer is interrupted by text for another,
+	// such as the comment markers at the start of each line of a Markdown cell in a script.
+	Continues bool
+}
+
+// Segmenter divides text among the lexers of a composite lexer, returning the segments in order. registry is
+// the registry of the composite lexer, in which the Segmenter can find the lexers it uses by name; it is nil if
+// the composite lexer isn't in a registry. Text not covered by a segment is left out of the tokens.
+type Segmenter func(text []rune, registry *LexerRegistry) []Segment
+
+// composition holds the Segmenter of a composite lexer and the options that are applied to the lexers of its
+// segments.
+type composition struct {
+	segment Segmenter
+	opts    []Option
+}
+
+// NewCompositeLexer creates a Lexer for documents made up of parts in different languages, such as literate
+// programs, that uses segment to divide the text among several lexers. Each lexer lexes only its segments, and
+// the tokens they produce are merged with their offsets in the whole text. FencedCodeSegmenter,
+// AsciiDocSegmenter, RSTSegmenter, OrgSegmenter, PercentCellSegmenter, CWEBSegmenter and LiterateSegmenter
+// divide some common literate formats and documents with source blocks, and FixedFormSegmenter divides
+// fixed-form Fortran into its columns.
+//
+// Options given to the composite lexer also apply to the lexers of its segments. Like a delegating lexer, a
+// composite lexer must lex the entire text before returning the first token.
+func NewCompositeLexer(cfg LexerConfig, segment Segmenter) *Lexer {
+	c := cfg.toConfig()
+	return &Lexer{
+		config:      c,
+		indent:      mylog.Check2(newIndentRules(c.Config.Editing)),
+		composition: &composition{segment: segment},
+	}
+}
+
+// withComposition returns a copy of the composite lexer with opts applied to it and to the lexers of its segments.
+func (l *Lexer) withComposition(opts []Option) *Lexer {
+	c := *l
+	c.composition = l.composition.with(opts)
+	c.iterOpts = l.options(opts).iterOpts
+	return &c
+}
+
+// with returns a copy of the composition with opts added to its options.
+func (c *composition) with(opts []Option) *composition {
+	return &composition{segment: c.segment, opts: append(c.opts[:len(c.opts):len(c.opts)], opts...)}
+}
+
+func (c *composition) tokenise(text []rune, registry *LexerRegistry) *delegatingIterator {
+	return &delegatingIterator{
+		text:  text,
+		lex:   func(it *delegatingIterator) error { return c.lex(it, registry) },
+		diags: newDiagnostics(text),
+	}
+}
+
+// compositeRun is the text of the segments that a lexer of a composite lexer lexes together.
+type compositeRun struct {
+	lexer    *Lexer
+	text     []rune
+	segments []delegatedSegment
+}
+
+func (c *composition) lex(it *delegatingIterator, registry *LexerRegistry) error {
+	var runs []*compositeRun
+	last := map[*Lexer]*compositeRun{}
+	for _, s := range c.segment(it.text, registry) {
+		if s.End <= s.Start {
+			continue
+		}
+		if s.Lexer == nil {
+			it.tokens = append(it.tokens, Token{Type: s.Type, Value: it.text[s.Start:s.End], Start: s.Start, End: s.End})
+			continue
+		}
+
+		run := last[s.Lexer]
+		if run == nil || !s.Continues {
+			run = &compositeRun{lexer: s.Lexer}
+			runs = append(runs, run)
+			last[s.Lexer] = run
+		}
+		run.segments = append(run.segments, delegatedSegment{
+			otherStart: len(run.text),
+			start:      s.Start,
+			length:     s.End - s.Start,
+		})
+		run.text = append(run.text, it.text[s.Start:s.End]...)
+	}
+
+	for _, run := range runs {
+		lex := run.lexer
+		if len(c.opts) > 0 {
+			lex = lex.withCallOptions(c.opts)
+		}
+		if joinErr := it.addJoined(lex, run.text, run.segments); joinErr != nil {
+			return joinErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package syn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+// checkContiguous checks that the tokens cover input in order.
+func checkContiguous(t *testing.T, input []rune, tokens []Token) {
+	end := 0
+	for _, tok := range tokens {
+		assert.Equal(t, end, tok.Start)
+		assert.Equal(t, input[tok.Start:tok.End], tok.Value)
+		end = tok.End
+	}
+	assert.Equal(t, len(input), end)
+}
+
+func TestFencedCodeSegmenter(t *testing.T) {
+	prog := "# Title\n\n```go\nx := 1\n```\n\n```unknown\nx\n```\n"
+
+	reg := newTestRegistry("markdown.xml", "go.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "Literate Markdown"}, FencedCodeSegmenter("Markdown")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: GenericHeading, Value: []rune("# Title\n"), Start: 0, End: 8},
+		{Type: Other, Value: []rune("\n"), Start: 8, End: 9},
+		{Type: LiteralString, Value: []rune("```go\n"), Start: 9, End: 15},
+		{Type: NameOther, Value: []rune("x"), Start: 15, End: 16},
+		{Type: Text, Value: []rune(" "), Start: 16, End: 17},
+		{Type: Operator, Value: []rune(":="), Start: 17, End: 19},
+		{Type: Text, Value: []rune(" "), Start: 19, End: 20},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 20, End: 21},
+		{Type: Text, Value: []rune("\n"), Start: 21, End: 22},
+		{Type: LiteralString, Value: []rune("```"), Start: 22, End: 25},
+		{Type: Other, Value: []rune("\n\n"), Start: 25, End: 27},
+		{Type: LiteralString, Value: []rune("```unknown\n"), Start: 27, End: 38},
+		{Type: Text, Value: []rune("x\n"), Start: 38, End: 40},
+		{Type: LiteralString, Value: []rune("```"), Start: 40, End: 43},
+		{Type: Other, Value: []rune("\n"), Start: 43, End: 44},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestAsciiDocSegmenter(t *testing.T) {
+	prog := "[source,go]\n----\nx := 1\n----\n\n[,go]\ny := 2\n\n[source,unknown]\n----\nx\n----\n"
+
+	reg := newTestRegistry("asciidoc.xml", "go.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "Literate AsciiDoc"}, AsciiDocSegmenter("AsciiDoc")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: Punctuation, Value: []rune("["), Start: 0, End: 1},
+		{Type: Keyword, Value: []rune("source"), Start: 1, End: 7},
+		{Type: Punctuation, Value: []rune(","), Start: 7, End: 8},
+		{Type: LiteralString, Value: []rune("go"), Start: 8, End: 10},
+		{Type: Punctuation, Value: []rune("]"), Start: 10, End: 11},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 11, End: 12},
+		{Type: Keyword, Value: []rune("----"), Start: 12, End: 16},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 16, End: 17},
+		{Type: NameOther, Value: []rune("x"), Start: 17, End: 18},
+		{Type: Text, Value: []rune(" "), Start: 18, End: 19},
+		{Type: Operator, Value: []rune(":="), Start: 19, End: 21},
+		{Type: Text, Value: []rune(" "), Start: 21, End: 22},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 22, End: 23},
+		{Type: Text, Value: []rune("\n"), Start: 23, End: 24},
+		{Type: Keyword, Value: []rune("----"), Start: 24, End: 28},
+		{Type: TextWhitespace, Value: []rune("\n\n"), Start: 28, End: 30},
+		{Type: Punctuation, Value: []rune("[,"), Start: 30, End: 32},
+		{Type: LiteralString, Value: []rune("go"), Start: 32, End: 34},
+		{Type: Punctuation, Value: []rune("]"), Start: 34, End: 35},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 35, End: 36},
+		{Type: NameOther, Value: []rune("y"), Start: 36, End: 37},
+		{Type: Text, Value: []rune(" "), Start: 37, End: 38},
+		{Type: Operator, Value: []rune(":="), Start: 38, End: 40},
+		{Type: Text, Value: []rune(" "), Start: 40, End: 41},
+		{Type: LiteralNumberInteger, Value: []rune("2"), Start: 41, End: 42},
+		{Type: Text, Value: []rune("\n"), Start: 42, End: 43},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 43, End: 44},
+		{Type: Punctuation, Value: []rune("["), Start: 44, End: 45},
+		{Type: Keyword, Value: []rune("source"), Start: 45, End: 51},
+		{Type: Punctuation, Value: []rune(","), Start: 51, End: 52},
+		{Type: LiteralString, Value: []rune("unknown"), Start: 52, End: 59},
+		{Type: Punctuation, Value: []rune("]"), Start: 59, End: 60},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 60, End: 61},
+		{Type: Keyword, Value: []rune("----"), Start: 61, End: 65},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 65, End: 66},
+		{Type: LiteralString, Value: []rune("x\n"), Start: 66, End: 68},
+		{Type: Keyword, Value: []rune("----"), Start: 68, End: 72},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 72, End: 73},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestRSTSegmenter(t *testing.T) {
+	prog := ".. code-block:: go\n   :linenos:\n\n   x := 1\n\n.. highlight:: go\n\nText::\n\n   y\n"
+
+	reg := newTestRegistry("restructuredtext.xml", "go.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "Literate reStructuredText"}, RSTSegmenter("reStructuredText")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: Punctuation, Value: []rune(".."), Start: 0, End: 2},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 2, End: 3},
+		{Type: OperatorWord, Value: []rune("code-block"), Start: 3, End: 13},
+		{Type: Punctuation, Value: []rune("::"), Start: 13, End: 15},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 15, End: 16},
+		{Type: Keyword, Value: []rune("go"), Start: 16, End: 18},
+		{Type: TextWhitespace, Value: []rune("\n   "), Start: 18, End: 22},
+		{Type: NameAttribute, Value: []rune(":linenos:"), Start: 22, End: 31},
+		{Type: TextWhitespace, Value: []rune("\n\n"), Start: 31, End: 33},
+		{Type: Text, Value: []rune("   "), Start: 33, End: 36},
+		{Type: NameOther, Value: []rune("x"), Start: 36, End: 37},
+		{Type: Text, Value: []rune(" "), Start: 37, End: 38},
+		{Type: Operator, Value: []rune(":="), Start: 38, End: 40},
+		{Type: Text, Value: []rune(" "), Start: 40, End: 41},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 41, End: 42},
+		{Type: Text, Value: []rune("\n"), Start: 42, End: 43},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 43, End: 44},
+		{Type: Punctuation, Value: []rune(".."), Start: 44, End: 46},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 46, End: 47},
+		{Type: OperatorWord, Value: []rune("highlight"), Start: 47, End: 56},
+		{Type: Punctuation, Value: []rune("::"), Start: 56, End: 58},
+		{Type: Text, Value: []rune(" go"), Start: 58, End: 61},
+		{Type: TextWhitespace, Value: []rune("\n\n"), Start: 61, End: 63},
+		{Type: Text, Value: []rune("Text"), Start: 63, End: 67},
+		{Type: Punctuation, Value: []rune("::"), Start: 67, End: 69},
+		{Type: TextWhitespace, Value: []rune("\n\n"), Start: 69, End: 71},
+		{Type: Text, Value: []rune("   "), Start: 71, End: 74},
+		{Type: NameOther, Value: []rune("y"), Start: 74, End: 75},
+		{Type: Text, Value: []rune("\n"), Start: 75, End: 76},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestOrgSegmenter(t *testing.T) {
+	prog := "* Notes\n#+BEGIN_SRC go\nx := 1\n#+END_SRC\n#+begin_src unknown\nx\n#+end_src\n"
+
+	reg := newTestRegistry("org.xml", "go.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "Literate Org"}, OrgSegmenter("Org")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: GenericHeading, Value: []rune("*"), Start: 0, End: 1},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 1, End: 2},
+		{Type: GenericHeading, Value: []rune("Notes"), Start: 2, End: 7},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 7, End: 8},
+		{Type: CommentPreproc, Value: []rune("#+BEGIN_SRC"), Start: 8, End: 19},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 19, End: 20},
+		{Type: Keyword, Value: []rune("go"), Start: 20, End: 22},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 22, End: 23},
+		{Type: NameOther, Value: []rune("x"), Start: 23, End: 24},
+		{Type: Text, Value: []rune(" "), Start: 24, End: 25},
+		{Type: Operator, Value: []rune(":="), Start: 25, End: 27},
+		{Type: Text, Value: []rune(" "), Start: 27, End: 28},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 28, End: 29},
+		{Type: Text, Value: []rune("\n"), Start: 29, End: 30},
+		{Type: CommentPreproc, Value: []rune("#+END_SRC"), Start: 30, End: 39},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 39, End: 40},
+		{Type: CommentPreproc, Value: []rune("#+begin_src"), Start: 40, End: 51},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 51, End: 52},
+		{Type: Keyword, Value: []rune("unknown"), Start: 52, End: 59},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 59, End: 60},
+		{Type: LiteralString, Value: []rune("x\n"), Start: 60, End: 62},
+		{Type: CommentPreproc, Value: []rune("#+end_src"), Start: 62, End: 71},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 71, End: 72},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestPercentCellSegmenter(t *testing.T) {
+	prog := "import os\n# %% [markdown]\n# Some *text*\n# %%\nx = 1\n"
+
+	reg := newTestRegistry("markdown.xml", "python.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "Python Percent Script"}, PercentCellSegmenter("Python", "Markdown", "#")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: KeywordNamespace, Value: []rune("import"), Start: 0, End: 6},
+		{Type: Text, Value: []rune(" "), Start: 6, End: 7},
+		{Type: NameNamespace, Value: []rune("os"), Start: 7, End: 9},
+		{Type: Text, Value: []rune("\n"), Start: 9, End: 10},
+		{Type: CommentSpecial, Value: []rune("# %% [markdown]\n"), Start: 10, End: 26},
+		{Type: Comment, Value: []rune("# "), Start: 26, End: 28},
+		{Type: Other, Value: []rune("Some"), Start: 28, End: 32},
+		{Type: Text, Value: []rune(" "), Start: 32, End: 33},
+		{Type: GenericEmph, Value: []rune("*text*"), Start: 33, End: 39},
+		{Type: Text, Value: []rune(""), Start: 39, End: 39},
+		{Type: Other, Value: []rune("\n"), Start: 39, End: 40},
+		{Type: CommentSpecial, Value: []rune("# %%\n"), Start: 40, End: 45},
+		{Type: Name, Value: []rune("x"), Start: 45, End: 46},
+		{Type: Text, Value: []rune(" "), Start: 46, End: 47},
+		{Type: Operator, Value: []rune("="), Start: 47, End: 48},
+		{Type: Text, Value: []rune(" "), Start: 48, End: 49},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 49, End: 50},
+		{Type: Text, Value: []rune("\n"), Start: 50, End: 51},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestCWEBSegmenter(t *testing.T) {
+	prog := "@* Intro. Hello.\n@c\n@<Header@>@;\nint x;\n@ @<Header@>=\n#include <stdio.h>\n"
+
+	reg := newTestRegistry("tex.xml", "c.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "CWEB"}, CWEBSegmenter("TeX", "C")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: Keyword, Value: []rune("@*"), Start: 0, End: 2},
+		{Type: Text, Value: []rune(" Intro. Hello.\n"), Start: 2, End: 17},
+		{Type: Keyword, Value: []rune("@c"), Start: 17, End: 19},
+		{Type: Text, Value: []rune("\n"), Start: 19, End: 20},
+		{Type: NameLabel, Value: []rune("@<Header@>"), Start: 20, End: 30},
+		{Type: Keyword, Value: []rune("@;"), Start: 30, End: 32},
+		{Type: Text, Value: []rune("\n"), Start: 32, End: 33},
+		{Type: KeywordType, Value: []rune("int"), Start: 33, End: 36},
+		{Type: Text, Value: []rune(" "), Start: 36, End: 37},
+		{Type: Name, Value: []rune("x"), Start: 37, End: 38},
+		{Type: Punctuation, Value: []rune(";"), Start: 38, End: 39},
+		{Type: Text, Value: []rune("\n"), Start: 39, End: 40},
+		{Type: Keyword, Value: []rune("@ "), Start: 40, End: 42},
+		{Type: NameLabel, Value: []rune("@<Header@>="), Start: 42, End: 53},
+		{Type: Text, Value: []rune("\n"), Start: 53, End: 54},
+		{Type: CommentPreproc, Value: []rune("#include"), Start: 54, End: 62},
+		{Type: Text, Value: []rune(" "), Start: 62, End: 63},
+		{Type: CommentPreprocFile, Value: []rune("<stdio.h>"), Start: 63, End: 72},
+		{Type: CommentPreproc, Value: []rune("\n"), Start: 72, End: 73},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestLiterateSegmenter(t *testing.T) {
+	prog := "Adds one.\n\n> inc n = n + 1\n\nDone.\n"
+
+	reg := newTestRegistry("haskell.xml", "tex.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "Literate Haskell"}, LiterateSegmenter("Haskell", "TeX")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: Text, Value: []rune("Adds one.\n\n"), Start: 0, End: 11},
+		{Type: CommentSpecial, Value: []rune(">"), Start: 11, End: 12},
+		{Type: Text, Value: []rune(" "), Start: 12, End: 13},
+		{Type: Name, Value: []rune("inc"), Start: 13, End: 16},
+		{Type: Text, Value: []rune(" "), Start: 16, End: 17},
+		{Type: Name, Value: []rune("n"), Start: 17, End: 18},
+		{Type: Text, Value: []rune(" "), Start: 18, End: 19},
+		{Type: OperatorWord, Value: []rune("="), Start: 19, End: 20},
+		{Type: Text, Value: []rune(" "), Start: 20, End: 21},
+		{Type: Name, Value: []rune("n"), Start: 21, End: 22},
+		{Type: Text, Value: []rune(" "), Start: 22, End: 23},
+		{Type: Operator, Value: []rune("+"), Start: 23, End: 24},
+		{Type: Text, Value: []rune(" "), Start: 24, End: 25},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 25, End: 26},
+		{Type: Text, Value: []rune("\n\nDone.\n"), Start: 26, End: 34},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestLiterateSegmenterLaTeX(t *testing.T) {
+	prog := "\\begin{code}\ninc = (+ 1)\n\\end{code}\n"
+
+	reg := newTestRegistry("haskell.xml", "tex.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "Literate Haskell"}, LiterateSegmenter("Haskell", "TeX")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: Keyword, Value: []rune("\\begin"), Start: 0, End: 6},
+		{Type: NameBuiltin, Value: []rune("{"), Start: 6, End: 7},
+		{Type: Text, Value: []rune("code"), Start: 7, End: 11},
+		{Type: NameBuiltin, Value: []rune("}"), Start: 11, End: 12},
+		{Type: Text, Value: []rune("\n"), Start: 12, End: 13},
+		{Type: Name, Value: []rune("inc"), Start: 13, End: 16},
+		{Type: Text, Value: []rune(" "), Start: 16, End: 17},
+		{Type: OperatorWord, Value: []rune("="), Start: 17, End: 18},
+		{Type: Text, Value: []rune(" "), Start: 18, End: 19},
+		{Type: Punctuation, Value: []rune("("), Start: 19, End: 20},
+		{Type: Operator, Value: []rune("+"), Start: 20, End: 21},
+		{Type: Text, Value: []rune(" "), Start: 21, End: 22},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 22, End: 23},
+		{Type: Punctuation, Value: []rune(")"), Start: 23, End: 24},
+		{Type: Text, Value: []rune("\n"), Start: 24, End: 25},
+		{Type: Keyword, Value: []rune("\\end"), Start: 25, End: 29},
+		{Type: NameBuiltin, Value: []rune("{"), Start: 29, End: 30},
+		{Type: Text, Value: []rune("code"), Start: 30, End: 34},
+		{Type: NameBuiltin, Value: []rune("}"), Start: 34, End: 35},
+		{Type: Text, Value: []rune("\n"), Start: 35, End: 36},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestFixedFormSegmenter(t *testing.T) {
+	prog := "C Sum.\n   10 X = 1\n     & + 2\n      Y = X" + strings.Repeat(" ", 61) + "SEQ1\n"
+
+	reg := newTestRegistry("fortran.xml")
+	lex := reg.Register(NewCompositeLexer(LexerConfig{Name: "FortranFixed"}, FixedFormSegmenter("Fortran")))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+	checkContiguous(t, input, tokens)
+
+	expected := []Token{
+		{Type: Comment, Value: []rune("C Sum.\n"), Start: 0, End: 7},
+		{Type: NameLabel, Value: []rune("   10"), Start: 7, End: 12},
+		{Type: Text, Value: []rune(" "), Start: 12, End: 13},
+		{Type: Name, Value: []rune("X"), Start: 13, End: 14},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 14, End: 15},
+		{Type: Operator, Value: []rune("="), Start: 15, End: 16},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 16, End: 17},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 17, End: 18},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 18, End: 19},
+		{Type: Text, Value: []rune("     "), Start: 19, End: 24},
+		{Type: GenericStrong, Value: []rune("&"), Start: 24, End: 25},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 25, End: 26},
+		{Type: Operator, Value: []rune("+"), Start: 26, End: 27},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 27, End: 28},
+		{Type: LiteralNumberInteger, Value: []rune("2"), Start: 28, End: 29},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 29, End: 30},
+		{Type: Text, Value: []rune("      "), Start: 30, End: 36},
+		{Type: Name, Value: []rune("Y"), Start: 36, End: 37},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 37, End: 38},
+		{Type: Operator, Value: []rune("="), Start: 38, End: 39},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 39, End: 40},
+		{Type: Name, Value: []rune("X"), Start: 40, End: 41},
+		{Type: TextWhitespace, Value: []rune(strings.Repeat(" ", 61)), Start: 41, End: 102},
+		{Type: Comment, Value: []rune("SEQ1"), Start: 102, End: 106},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 106, End: 107},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestCompositeLexerError(t *testing.T) {
+	broken := brokenLexer()
+	lex := NewCompositeLexer(LexerConfig{Name: "Broken"}, func(text []rune, registry *LexerRegistry) []Segment {
+		return []Segment{{Start: 0, End: len(text), Lexer: broken}}
+	})
+
+	tokens, lexErr := lex.TokensAll([]rune("a (b"))
+	assert.EqualError(t, lexErr, "syn.iterator: a rule refers to a state nested that doesn't exist")
+	assert.Empty(t, tokens)
+}
@@ -0,0 +1,105 @@
+package syn
+
+import (
+	"sort"
+	"sync"
+)
+
+// RuleRef identifies a rule in a lexer definition: the rule at Index in the rules of State in the lexer called
+// Lexer.
+type RuleRef struct {
+	Lexer string
+	State string
+	Index int
+}
+
+// Coverage records which rules of the lexers matched while lexing, so that grammar authors can find rules that
+// are never used and inputs missing from their tests. It is passed to the lexers with WithCoverage, and may be
+// shared by several lexers and used concurrently. A rule that is copied into other states by an include or a
+// combined state is recorded as the rule in the state it is defined in, and the rules of lexers used by using
+// rules are recorded under those lexers.
+type Coverage struct {
+	mu   sync.Mutex
+	hits map[RuleRef]int
+}
+
+// NewCoverage returns a Coverage that has recorded no matches.
+func NewCoverage() *Coverage {
+	return &Coverage{hits: map[RuleRef]int{}}
+}
+
+// WithCoverage sets the Coverage that records the rules of the lexer that match.
+func WithCoverage(c *Coverage) Option {
+	return func(o *lexerOptions) {
+		o.iterOpts.coverage = c
+	}
+}
+
+func (c *Coverage) hit(r RuleRef) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits[r]++
+}
+
+// Hits returns the number of times the rule r matched.
+func (c *Coverage) Hits(r RuleRef) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits[r]
+}
+
+// RuleCoverage is the number of times a rule matched.
+type RuleCoverage struct {
+	RuleRef
+	// Pattern is the rule's pattern as written in the lexer definition.
+	Pattern string
+	Hits    int
+}
+
+// Rules returns the coverage of each rule in the definition of the lexer l, in the order they are defined. Rules
+// that include a state are left out, since only the rules they include can match. Delegating and composite
+// lexers have no rules of their own; the coverage of the lexers they combine are found by passing those lexers.
+func (c *Coverage) Rules(l *Lexer) []RuleCoverage {
+	cfg := l.cfg()
+	var rules []RuleCoverage
+	for _, state := range cfg.Rules.States {
+		for i, r := range state.Rules {
+			if r.Include != nil {
+				continue
+			}
+			ref := RuleRef{Lexer: cfg.Config.Name, State: state.Name, Index: i}
+			rules = append(rules, RuleCoverage{RuleRef: ref, Pattern: r.Pattern, Hits: c.Hits(ref)})
+		}
+	}
+	return rules
+}
+
+// Unhit returns the rules of the lexer l, as returned by Rules, that never matched.
+func (c *Coverage) Unhit(l *Lexer) []RuleCoverage {
+	var unhit []RuleCoverage
+	for _, r := range c.Rules(l) {
+		if r.Hits == 0 {
+			unhit = append(unhit, r)
+		}
+	}
+	return unhit
+}
+
+// Lexers returns the names of the lexers that at least one rule has matched in, sorted.
+func (c *Coverage) Lexers() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seen := map[string]bool{}
+	var names []string
+	for r := range c.hits {
+		if !seen[r.Lexer] {
+			seen[r.Lexer] = true
+			names = append(names, r.Lexer)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,76 @@
+package syn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+// coverageLexer is a lexer definition with an included state and a rule that no text reaches.
+const coverageLexer = `<lexer>
+  <config>
+    <name>cover</name>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern="&quot;">
+        <token type="LiteralString"/>
+        <push state="string"/>
+      </rule>
+      <rule>
+        <include state="words"/>
+      </rule>
+      <rule pattern="\s+">
+        <token type="Text"/>
+      </rule>
+    </state>
+    <state name="words">
+      <rule pattern="\d+">
+        <token type="LiteralNumber"/>
+      </rule>
+      <rule pattern="\w+">
+        <token type="Name"/>
+      </rule>
+    </state>
+    <state name="string">
+      <rule pattern="&quot;">
+        <token type="LiteralString"/>
+        <pop depth="1"/>
+      </rule>
+      <rule>
+        <include state="words"/>
+      </rule>
+      <rule pattern="[^&quot;\w]+">
+        <token type="LiteralString"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>`
+
+func TestCoverage(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewCoverage()
+	lex := mylog.Check2(NewLexerFromXML(strings.NewReader(coverageLexer)))
+	mylog.Check2(lex.TokensAll([]rune(`a 1 "b"`), WithCoverage(c)))
+
+	assert.Equal([]string{"cover"}, c.Lexers())
+	assert.Equal(1, c.Hits(RuleRef{Lexer: "cover", State: "string", Index: 0}))
+	// The rules of words are recorded as the same rules whichever state includes them.
+	assert.Equal(2, c.Hits(RuleRef{Lexer: "cover", State: "words", Index: 1}))
+	assert.Equal(1, c.Hits(RuleRef{Lexer: "cover", State: "words", Index: 0}))
+
+	rules := c.Rules(lex)
+	assert.Len(rules, 6)
+	assert.Equal(RuleCoverage{RuleRef: RuleRef{Lexer: "cover", State: "root", Index: 2}, Pattern: `\s+`, Hits: 2}, rules[1])
+
+	assert.Equal([]RuleCoverage{
+		{RuleRef: RuleRef{Lexer: "cover", State: "string", Index: 2}, Pattern: `[^"\w]+`},
+	}, c.Unhit(lex))
+
+	// A lexer given the coverage with With records to it too.
+	mylog.Check2(lex.With(WithCoverage(c)).TokensAll([]rune(`"- -"`)))
+	assert.Empty(c.Unhit(lex))
+}
@@ -0,0 +1,284 @@
+package syn
+
+import (
+	"sort"
+
+	"github.com/ddkwork/golibrary/mylog"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+// LexerConfig holds the identifying information for a Lexer that is not created from an XML
+// definition: the name and aliases it may be looked up by in a LexerRegistry, and the filenames
+// and MIME types it is matched against.
+type LexerConfig struct {
+	Name      string
+	Aliases   []string
+	Filenames []string
+	MimeTypes []string
+	// Priority is used to choose between lexers that match the same filename. Higher wins; zero is treated as 1.
+	Priority float32
+	// Analyse holds the patterns used to recognise text in the language by its content; see Lexer.AnalyseText.
+	Analyse *Analyse
+	// Editing holds the information about the language used by editors; see Lexer.Editing.
+	Editing Editing
+}
+
+func (c LexerConfig) toConfig() *config.Lexer {
+	return &config.Lexer{
+		Config: config.Config{
+			Name:      c.Name,
+			Aliases:   c.Aliases,
+			Filenames: c.Filenames,
+			MimeTypes: c.MimeTypes,
+			Priority:  c.Priority,
+			Analyse:   c.Analyse.toConfig(),
+			Editing:   c.Editing.toConfig(),
+		},
+	}
+}
+
+// delegation holds the two lexers that make up a delegating lexer.
+type delegation struct {
+	root     *Lexer
+	language *Lexer
+}
+
+// NewDelegatingLexer creates a Lexer that combines two lexers, for example a template language
+// embedded in HTML. The text is first lexed using language; the text of all the tokens that it marks
+// with type Other is then joined and lexed using root, and the two token streams are merged.
+//
+// Unlike other lexers, a delegating lexer must lex the entire text before returning the first token.
+func NewDelegatingLexer(cfg LexerConfig, root, language *Lexer) *Lexer {
+	c := cfg.toConfig()
+	return &Lexer{
+		config: c,
+		indent: mylog.Check2(newIndentRules(c.Config.Editing)),
+		delegation: &delegation{
+			root:     root,
+			language: language,
+		},
+	}
+}
+
+func (d *delegation) tokenise(text []rune) *delegatingIterator {
+	return &delegatingIterator{
+		text:  text,
+		lex:   d.lex,
+		diags: newDiagnostics(text),
+	}
+}
+
+// delegatedSegment records where the text of an Other token produced by the language lexer
+// was placed in the text given to the root lexer.
+type delegatedSegment struct {
+	// otherStart is the index of the segment in the joined text of the Other tokens
+	otherStart int
+	// start is the index of the segment in the original text
+	start  int
+	length int
+}
+
+func (s delegatedSegment) otherEnd() int {
+	return s.otherStart + s.length
+}
+
+// delegatingIterator returns the tokens of a lexer made up of other lexers, such as a delegating lexer or a
+// composite lexer, which are all produced when the first token is requested.
+type delegatingIterator struct {
+	text []rune
+	// lex adds the tokens of text, in any order, and the diagnostics of the lexers.
+	lex    func(it *delegatingIterator) error
+	tokens []Token
+	lexed  bool
+	// err is the error lexing the text failed with, if any. Next returns it instead of the tokens.
+	err error
+	// next is the index in tokens of the next token to return
+	next int
+	// diags holds the diagnostics of both lexers, relative to text.
+	diags *diagnostics
+}
+
+func (it *delegatingIterator) Next() (Token, error) {
+	it.lexIfNeeded()
+	if it.err != nil {
+		return Token{}, it.err
+	}
+
+	if it.next >= len(it.tokens) {
+		return Token{Type: EOFType, Value: nil}, nil
+	}
+
+	tok := it.tokens[it.next]
+	it.next++
+	return tok, nil
+}
+
+func (it *delegatingIterator) lexIfNeeded() {
+	if it.lexed {
+		return
+	}
+	it.lexed = true
+
+	if lexErr := it.lex(it); lexErr != nil {
+		it.err = lexErr
+		return
+	}
+
+	sort.SliceStable(it.tokens, func(i, j int) bool {
+		return it.tokens[i].Start < it.tokens[j].Start
+	})
+
+	it.tokens = it.coalesceTokens(it.tokens)
+}
+
+func (d *delegation) lex(it *delegatingIterator) error {
+	var others []rune
+	var segments []delegatedSegment
+
+	langIter := newLookahead(d.language.tokenise(it.text))
+	langTokens, collectErr := langIter.Collect()
+	if collectErr != nil {
+		return collectErr
+	}
+	for _, tok := range langTokens {
+		if tok.Type != Other {
+			it.tokens = append(it.tokens, tok)
+			continue
+		}
+		segments = append(segments, delegatedSegment{
+			otherStart: len(others),
+			start:      tok.Start,
+			length:     tok.Length(),
+		})
+		others = append(others, it.text[tok.Start:tok.End]...)
+	}
+
+	for _, diag := range langIter.Diagnostics() {
+		it.diags.addOriginal(diag)
+	}
+
+	if len(others) > 0 {
+		return it.addJoined(d.root, others, segments)
+	}
+	return nil
+}
+
+// addJoined lexes text, which is made up of the segments of the iterator's text, using lex, and adds the tokens
+// and diagnostics it produces mapped back to the iterator's text. It returns the error lexing text failed with.
+func (it *delegatingIterator) addJoined(lex *Lexer, text []rune, segments []delegatedSegment) error {
+	iter := newLookahead(lex.tokenise(text))
+	tokens, collectErr := iter.Collect()
+	if collectErr != nil {
+		return collectErr
+	}
+	for _, tok := range tokens {
+		it.tokens = it.appendSplitBySegments(it.tokens, tok, segments)
+	}
+	for _, diag := range iter.Diagnostics() {
+		diag.Offset = it.originalOffset(diag.Offset, segments)
+		it.diags.addOriginal(diag)
+	}
+	return nil
+}
+
+// appendSplitBySegments maps a token produced by the root lexer back to the original text. Since
+// the token may span text that came from more than one Other token it may need to be split into
+// multiple tokens.
+func (it *delegatingIterator) appendSplitBySegments(toks []Token, tok Token, segments []delegatedSegment) []Token {
+	first := sort.Search(len(segments), func(i int) bool {
+		return segments[i].otherEnd() > tok.Start
+	})
+
+	if tok.Length() == 0 {
+		if first < len(segments) {
+			s := segments[first]
+			start := s.start + tok.Start - s.otherStart
+			toks = append(toks, Token{Type: tok.Type, Value: it.text[start:start], Start: start, End: start})
+		}
+		return toks
+	}
+
+	for _, s := range segments[first:] {
+		if s.otherStart >= tok.End {
+			break
+		}
+		start := max(tok.Start, s.otherStart)
+		end := min(tok.End, s.otherEnd())
+		start, end = s.start+start-s.otherStart, s.start+end-s.otherStart
+		toks = append(toks, Token{Type: tok.Type, Value: it.text[start:end], Start: start, End: end})
+	}
+	return toks
+}
+
+// originalOffset maps an offset in the text given to the root lexer back to the original text.
+func (it *delegatingIterator) originalOffset(offset int, segments []delegatedSegment) int {
+	i := sort.Search(len(segments), func(i int) bool {
+		return segments[i].otherEnd() > offset
+	})
+	if i == len(segments) {
+		return len(it.text)
+	}
+	s := segments[i]
+	return s.start + offset - s.otherStart
+}
+
+// coalesceTokens merges adjacent tokens of the same type, like the coalescer does for
+// other lexers.
+func (it *delegatingIterator) coalesceTokens(toks []Token) []Token {
+	result := toks[:0]
+	for _, tok := range toks {
+		if len(result) > 0 {
+			prev := &result[len(result)-1]
+			if prev.Type == tok.Type && prev.End == tok.Start {
+				prev.End = tok.End
+				prev.Value = it.text[prev.Start:prev.End]
+				continue
+			}
+		}
+		result = append(result, tok)
+	}
+	return result
+}
+
+// State returns the state of the iterator. Since a delegating lexer lexes the whole text at once, the
+// state is simply the position of the next token.
+func (it *delegatingIterator) State() IteratorState {
+	it.lexIfNeeded()
+
+	index := len(it.text)
+	if it.next < len(it.tokens) {
+		index = it.tokens[it.next].Start
+	}
+	return &delegatingState{index: index}
+}
+
+func (it *delegatingIterator) SetState(s IteratorState) {
+	state := s.(*delegatingState)
+
+	it.lexIfNeeded()
+	it.next = sort.Search(len(it.tokens), func(i int) bool {
+		return it.tokens[i].Start >= state.index
+	})
+}
+
+type delegatingState struct {
+	// index is the index of the next input rune to process
+	index int
+}
+
+func (s delegatingState) Equal(o IteratorState) bool {
+	other, ok := o.(*delegatingState)
+	if !ok {
+		return false
+	}
+	return s.index == other.index
+}
+
+func (s *delegatingState) SetIndex(i int) {
+	s.index = i
+}
+
+func (s *delegatingState) AddToIndex(delta int) {
+	s.index += delta
+}
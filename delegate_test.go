@@ -0,0 +1,417 @@
+package syn
+
+import (
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRegistry(files ...string) *LexerRegistry {
+	reg := NewLexerRegistry()
+	for _, f := range files {
+		reg.Register(mylog.Check2(NewLexerFromXMLFile("lexers/embedded/" + f)))
+	}
+	return reg
+}
+
+func TestDelegatingLexer(t *testing.T) {
+	prog := "<p class=\"x\">{{ .Name }}</p>\r\n{{/* note */}}<b>{{ if .X }}y{{end}}</b>"
+
+	assert := assert.New(t)
+
+	reg := newTestRegistry("html.xml", "go_template.xml")
+	lex := NewDelegatingLexer(LexerConfig{Name: "Go HTML Template"}, reg.Get("HTML"), reg.Get("Go Text Template"))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+
+	dumpTokens(t, tokens)
+
+	for i, tok := range tokens {
+		assert.Equal(tok.Value, input[tok.Start:tok.End])
+		if i > 0 {
+			assert.Equal(tokens[i-1].End, tok.Start)
+		}
+	}
+
+	expected := []Token{
+		{Type: Punctuation, Value: []rune("<"), Start: 0, End: 1},
+		{Type: Text, Value: []rune(""), Start: 1, End: 1},
+		{Type: NameTag, Value: []rune("p"), Start: 1, End: 2},
+		{Type: Text, Value: []rune(" "), Start: 2, End: 3},
+		{Type: NameAttribute, Value: []rune("class"), Start: 3, End: 8},
+		{Type: Operator, Value: []rune("="), Start: 8, End: 9},
+		{Type: Text, Value: []rune(""), Start: 9, End: 9},
+		{Type: LiteralString, Value: []rune("\"x\""), Start: 9, End: 12},
+		{Type: Punctuation, Value: []rune(""), Start: 12, End: 12},
+		{Type: Text, Value: []rune(""), Start: 12, End: 12},
+		{Type: Punctuation, Value: []rune(">"), Start: 12, End: 13},
+		{Type: CommentPreproc, Value: []rune("{{"), Start: 13, End: 15},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 15, End: 16},
+		{Type: NameAttribute, Value: []rune(".Name"), Start: 16, End: 21},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 21, End: 22},
+		{Type: CommentPreproc, Value: []rune("}}"), Start: 22, End: 24},
+		{Type: Punctuation, Value: []rune("<"), Start: 24, End: 25},
+		{Type: Text, Value: []rune(""), Start: 25, End: 25},
+		{Type: Punctuation, Value: []rune("/"), Start: 25, End: 26},
+		{Type: Text, Value: []rune(""), Start: 26, End: 26},
+		{Type: NameTag, Value: []rune("p"), Start: 26, End: 27},
+		{Type: Text, Value: []rune(""), Start: 27, End: 27},
+		{Type: Punctuation, Value: []rune(">"), Start: 27, End: 28},
+		{Type: Text, Value: []rune("\r\n"), Start: 28, End: 30},
+		{Type: CommentMultiline, Value: []rune("{{/* note */}}"), Start: 30, End: 44},
+		{Type: Punctuation, Value: []rune("<"), Start: 44, End: 45},
+		{Type: Text, Value: []rune(""), Start: 45, End: 45},
+		{Type: NameTag, Value: []rune("b"), Start: 45, End: 46},
+		{Type: Punctuation, Value: []rune(""), Start: 46, End: 46},
+		{Type: Text, Value: []rune(""), Start: 46, End: 46},
+		{Type: Punctuation, Value: []rune(">"), Start: 46, End: 47},
+		{Type: CommentPreproc, Value: []rune("{{"), Start: 47, End: 49},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 49, End: 50},
+		{Type: Keyword, Value: []rune("if"), Start: 50, End: 52},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 52, End: 53},
+		{Type: NameAttribute, Value: []rune(".X"), Start: 53, End: 55},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 55, End: 56},
+		{Type: CommentPreproc, Value: []rune("}}"), Start: 56, End: 58},
+		{Type: Text, Value: []rune("y"), Start: 58, End: 59},
+		{Type: CommentPreproc, Value: []rune("{{"), Start: 59, End: 61},
+		{Type: Keyword, Value: []rune("end"), Start: 61, End: 64},
+		{Type: CommentPreproc, Value: []rune("}}"), Start: 64, End: 66},
+		{Type: Punctuation, Value: []rune("<"), Start: 66, End: 67},
+		{Type: Text, Value: []rune(""), Start: 67, End: 67},
+		{Type: Punctuation, Value: []rune("/"), Start: 67, End: 68},
+		{Type: Text, Value: []rune(""), Start: 68, End: 68},
+		{Type: NameTag, Value: []rune("b"), Start: 68, End: 69},
+		{Type: Text, Value: []rune(""), Start: 69, End: 69},
+		{Type: Punctuation, Value: []rune(">"), Start: 69, End: 70},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestUsing(t *testing.T) {
+	prog := "<script>var x = 1;</script>"
+
+	assert := assert.New(t)
+
+	reg := newTestRegistry("html.xml", "javascript.xml")
+
+	tokens := mylog.Check2(tokenize(reg.Get("HTML").Tokenise([]rune(prog))))
+
+	dumpTokens(t, tokens)
+
+	expected := []Token{
+		{Type: Punctuation, Value: []rune("<"), Start: 0, End: 1},
+		{Type: Text, Value: []rune(""), Start: 1, End: 1},
+		{Type: NameTag, Value: []rune("script"), Start: 1, End: 7},
+		{Type: Text, Value: []rune(""), Start: 7, End: 7},
+		{Type: Punctuation, Value: []rune(">"), Start: 7, End: 8},
+		{Type: KeywordDeclaration, Value: []rune("var"), Start: 8, End: 11},
+		{Type: Text, Value: []rune(" "), Start: 11, End: 12},
+		{Type: NameOther, Value: []rune("x"), Start: 12, End: 13},
+		{Type: Text, Value: []rune(" "), Start: 13, End: 14},
+		{Type: Operator, Value: []rune("="), Start: 14, End: 15},
+		{Type: Text, Value: []rune(" "), Start: 15, End: 16},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 16, End: 17},
+		{Type: Punctuation, Value: []rune(";<"), Start: 17, End: 19},
+		{Type: Text, Value: []rune(""), Start: 19, End: 19},
+		{Type: Punctuation, Value: []rune("/"), Start: 19, End: 20},
+		{Type: Text, Value: []rune(""), Start: 20, End: 20},
+		{Type: NameTag, Value: []rune("script"), Start: 20, End: 26},
+		{Type: Text, Value: []rune(""), Start: 26, End: 26},
+		{Type: Punctuation, Value: []rune(">"), Start: 26, End: 27},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestDelegatingLexerYAMLJinja(t *testing.T) {
+	prog := "name: {{- user.name | upper }}\n{# comment #}\n{% for x in xs %}- {{ x }}\n{% endfor %}\n"
+
+	assert := assert.New(t)
+
+	reg := newTestRegistry("yaml.xml", "django_jinja.xml")
+	lex := NewDelegatingLexer(LexerConfig{Name: "YAML+Jinja"}, reg.Get("YAML"), reg.Get("Django/Jinja"))
+
+	input := []rune(prog)
+	tokens := mylog.Check2(tokenize(lex.Tokenise(input)))
+
+	dumpTokens(t, tokens)
+
+	for i, tok := range tokens {
+		assert.Equal(tok.Value, input[tok.Start:tok.End])
+		if i > 0 {
+			assert.Equal(tokens[i-1].End, tok.Start)
+		}
+	}
+
+	expected := []Token{
+		{Type: NameTag, Value: []rune("name"), Start: 0, End: 4},
+		{Type: Punctuation, Value: []rune(":"), Start: 4, End: 5},
+		{Type: TextWhitespace, Value: []rune(" "), Start: 5, End: 6},
+		{Type: CommentPreproc, Value: []rune("{{"), Start: 6, End: 8},
+		{Type: Text, Value: []rune("- "), Start: 8, End: 10},
+		{Type: NameVariable, Value: []rune("user.name"), Start: 10, End: 19},
+		{Type: Text, Value: []rune(" "), Start: 19, End: 20},
+		{Type: Operator, Value: []rune("|"), Start: 20, End: 21},
+		{Type: Text, Value: []rune(" "), Start: 21, End: 22},
+		{Type: NameFunction, Value: []rune("upper"), Start: 22, End: 27},
+		{Type: Text, Value: []rune(" "), Start: 27, End: 28},
+		{Type: CommentPreproc, Value: []rune("}}"), Start: 28, End: 30},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 30, End: 31},
+		{Type: Comment, Value: []rune("{# comment #}"), Start: 31, End: 44},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 44, End: 45},
+		{Type: CommentPreproc, Value: []rune("{%"), Start: 45, End: 47},
+		{Type: Text, Value: []rune(" "), Start: 47, End: 48},
+		{Type: Keyword, Value: []rune("for"), Start: 48, End: 51},
+		{Type: Text, Value: []rune(" "), Start: 51, End: 52},
+		{Type: NameVariable, Value: []rune("x"), Start: 52, End: 53},
+		{Type: Text, Value: []rune(" "), Start: 53, End: 54},
+		{Type: Keyword, Value: []rune("in"), Start: 54, End: 56},
+		{Type: Text, Value: []rune(" "), Start: 56, End: 57},
+		{Type: NameVariable, Value: []rune("xs"), Start: 57, End: 59},
+		{Type: Text, Value: []rune(" "), Start: 59, End: 60},
+		{Type: CommentPreproc, Value: []rune("%}"), Start: 60, End: 62},
+		{Type: Text, Value: []rune("- "), Start: 62, End: 64},
+		{Type: CommentPreproc, Value: []rune("{{"), Start: 64, End: 66},
+		{Type: Text, Value: []rune(" "), Start: 66, End: 67},
+		{Type: NameVariable, Value: []rune("x"), Start: 67, End: 68},
+		{Type: Text, Value: []rune(" "), Start: 68, End: 69},
+		{Type: CommentPreproc, Value: []rune("}}"), Start: 69, End: 71},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 71, End: 72},
+		{Type: CommentPreproc, Value: []rune("{%"), Start: 72, End: 74},
+		{Type: Text, Value: []rune(" "), Start: 74, End: 75},
+		{Type: Keyword, Value: []rune("endfor"), Start: 75, End: 81},
+		{Type: Text, Value: []rune(" "), Start: 81, End: 82},
+		{Type: CommentPreproc, Value: []rune("%}"), Start: 82, End: 84},
+		{Type: TextWhitespace, Value: []rune("\n"), Start: 84, End: 85},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestDelegatingLexerERB(t *testing.T) {
+	prog := "<%# note %>\n<li><%= @user.name %></li>\n  % if x\n<%% ok\n"
+
+	assert := assert.New(t)
+
+	reg := newTestRegistry("html.xml", "erb.xml", "ruby.xml")
+	lex := NewDelegatingLexer(LexerConfig{Name: "RHTML"}, reg.Get("HTML"), reg.Get("ERB"))
+
+	tokens := mylog.Check2(tokenize(lex.Tokenise([]rune(prog))))
+
+	dumpTokens(t, tokens)
+
+	expected := []Token{
+		{Type: CommentPreproc, Value: []rune("<%#"), Start: 0, End: 3},
+		{Type: Comment, Value: []rune(" note "), Start: 3, End: 9},
+		{Type: CommentPreproc, Value: []rune("%>"), Start: 9, End: 11},
+		{Type: Text, Value: []rune("\n"), Start: 11, End: 12},
+		{Type: Punctuation, Value: []rune("<"), Start: 12, End: 13},
+		{Type: Text, Value: []rune(""), Start: 13, End: 13},
+		{Type: NameTag, Value: []rune("li"), Start: 13, End: 15},
+		{Type: Punctuation, Value: []rune(""), Start: 15, End: 15},
+		{Type: Text, Value: []rune(""), Start: 15, End: 15},
+		{Type: Punctuation, Value: []rune(">"), Start: 15, End: 16},
+		{Type: CommentPreproc, Value: []rune("<%="), Start: 16, End: 19},
+		{Type: Text, Value: []rune(" "), Start: 19, End: 20},
+		{Type: NameVariableInstance, Value: []rune("@user"), Start: 20, End: 25},
+		{Type: Operator, Value: []rune("."), Start: 25, End: 26},
+		{Type: Name, Value: []rune("name"), Start: 26, End: 30},
+		{Type: Text, Value: []rune(" "), Start: 30, End: 31},
+		{Type: CommentPreproc, Value: []rune("%>"), Start: 31, End: 33},
+		{Type: Punctuation, Value: []rune("<"), Start: 33, End: 34},
+		{Type: Text, Value: []rune(""), Start: 34, End: 34},
+		{Type: Punctuation, Value: []rune("/"), Start: 34, End: 35},
+		{Type: Text, Value: []rune(""), Start: 35, End: 35},
+		{Type: NameTag, Value: []rune("li"), Start: 35, End: 37},
+		{Type: Text, Value: []rune(""), Start: 37, End: 37},
+		{Type: Punctuation, Value: []rune(">"), Start: 37, End: 38},
+		{Type: Text, Value: []rune("\n  "), Start: 38, End: 41},
+		{Type: CommentPreproc, Value: []rune("%"), Start: 41, End: 42},
+		{Type: Text, Value: []rune(" "), Start: 42, End: 43},
+		{Type: Keyword, Value: []rune("if"), Start: 43, End: 45},
+		{Type: Text, Value: []rune(" "), Start: 45, End: 46},
+		{Type: Name, Value: []rune("x"), Start: 46, End: 47},
+		{Type: Text, Value: []rune("\n<%% ok\n"), Start: 47, End: 55},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestVue(t *testing.T) {
+	prog := "<template><b>x</b></template>\n<script lang=\"ts\">let x: number\n</script>\n<style>a { }</style>\n"
+
+	assert := assert.New(t)
+
+	reg := newTestRegistry("vue.xml", "html.xml", "typescript.xml", "css.xml")
+
+	tokens := mylog.Check2(tokenize(reg.Get("vue").Tokenise([]rune(prog))))
+
+	dumpTokens(t, tokens)
+
+	expected := []Token{
+		{Type: Punctuation, Value: []rune("<"), Start: 0, End: 1},
+		{Type: Text, Value: []rune(""), Start: 1, End: 1},
+		{Type: NameTag, Value: []rune("template"), Start: 1, End: 9},
+		{Type: Punctuation, Value: []rune("><"), Start: 9, End: 11},
+		{Type: Text, Value: []rune(""), Start: 11, End: 11},
+		{Type: NameTag, Value: []rune("b"), Start: 11, End: 12},
+		{Type: Punctuation, Value: []rune(""), Start: 12, End: 12},
+		{Type: Text, Value: []rune(""), Start: 12, End: 12},
+		{Type: Punctuation, Value: []rune(">"), Start: 12, End: 13},
+		{Type: Text, Value: []rune("x"), Start: 13, End: 14},
+		{Type: Punctuation, Value: []rune("<"), Start: 14, End: 15},
+		{Type: Text, Value: []rune(""), Start: 15, End: 15},
+		{Type: Punctuation, Value: []rune("/"), Start: 15, End: 16},
+		{Type: Text, Value: []rune(""), Start: 16, End: 16},
+		{Type: NameTag, Value: []rune("b"), Start: 16, End: 17},
+		{Type: Text, Value: []rune(""), Start: 17, End: 17},
+		{Type: Punctuation, Value: []rune("><"), Start: 17, End: 19},
+		{Type: Text, Value: []rune(""), Start: 19, End: 19},
+		{Type: Punctuation, Value: []rune("/"), Start: 19, End: 20},
+		{Type: Text, Value: []rune(""), Start: 20, End: 20},
+		{Type: NameTag, Value: []rune("template"), Start: 20, End: 28},
+		{Type: Text, Value: []rune(""), Start: 28, End: 28},
+		{Type: Punctuation, Value: []rune(">"), Start: 28, End: 29},
+		{Type: Text, Value: []rune("\n"), Start: 29, End: 30},
+		{Type: Punctuation, Value: []rune("<"), Start: 30, End: 31},
+		{Type: Text, Value: []rune(""), Start: 31, End: 31},
+		{Type: NameTag, Value: []rune("script"), Start: 31, End: 37},
+		{Type: Text, Value: []rune(" "), Start: 37, End: 38},
+		{Type: NameAttribute, Value: []rune("lang"), Start: 38, End: 42},
+		{Type: Text, Value: []rune(""), Start: 42, End: 42},
+		{Type: Operator, Value: []rune("="), Start: 42, End: 43},
+		{Type: Text, Value: []rune(""), Start: 43, End: 43},
+		{Type: LiteralString, Value: []rune("\"ts\""), Start: 43, End: 47},
+		{Type: Punctuation, Value: []rune(">"), Start: 47, End: 48},
+		{Type: KeywordDeclaration, Value: []rune("let"), Start: 48, End: 51},
+		{Type: Text, Value: []rune(" "), Start: 51, End: 52},
+		{Type: NameOther, Value: []rune("x"), Start: 52, End: 53},
+		{Type: Operator, Value: []rune(":"), Start: 53, End: 54},
+		{Type: Text, Value: []rune(" "), Start: 54, End: 55},
+		{Type: KeywordType, Value: []rune("number"), Start: 55, End: 61},
+		{Type: Text, Value: []rune("\n"), Start: 61, End: 62},
+		{Type: Punctuation, Value: []rune("<"), Start: 62, End: 63},
+		{Type: Text, Value: []rune(""), Start: 63, End: 63},
+		{Type: Punctuation, Value: []rune("/"), Start: 63, End: 64},
+		{Type: Text, Value: []rune(""), Start: 64, End: 64},
+		{Type: NameTag, Value: []rune("script"), Start: 64, End: 70},
+		{Type: Text, Value: []rune(""), Start: 70, End: 70},
+		{Type: Punctuation, Value: []rune(">"), Start: 70, End: 71},
+		{Type: Text, Value: []rune("\n"), Start: 71, End: 72},
+		{Type: Punctuation, Value: []rune("<"), Start: 72, End: 73},
+		{Type: Text, Value: []rune(""), Start: 73, End: 73},
+		{Type: NameTag, Value: []rune("style"), Start: 73, End: 78},
+		{Type: Punctuation, Value: []rune(">"), Start: 78, End: 79},
+		{Type: NameTag, Value: []rune("a"), Start: 79, End: 80},
+		{Type: Text, Value: []rune(" "), Start: 80, End: 81},
+		{Type: Punctuation, Value: []rune("{"), Start: 81, End: 82},
+		{Type: Text, Value: []rune(" "), Start: 82, End: 83},
+		{Type: Punctuation, Value: []rune("}<"), Start: 83, End: 85},
+		{Type: Text, Value: []rune(""), Start: 85, End: 85},
+		{Type: Punctuation, Value: []rune("/"), Start: 85, End: 86},
+		{Type: Text, Value: []rune(""), Start: 86, End: 86},
+		{Type: NameTag, Value: []rune("style"), Start: 86, End: 91},
+		{Type: Text, Value: []rune(""), Start: 91, End: 91},
+		{Type: Punctuation, Value: []rune(">"), Start: 91, End: 92},
+		{Type: Text, Value: []rune("\n"), Start: 92, End: 93},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestSvelte(t *testing.T) {
+	prog := "<script>\n$: d = n * 2\n</script>\n{#if d as x}<a on:click={f}>{d}</a>{/if}\n"
+
+	assert := assert.New(t)
+
+	reg := newTestRegistry("svelte.xml", "javascript.xml")
+
+	tokens := mylog.Check2(tokenize(reg.Get("Svelte").Tokenise([]rune(prog))))
+
+	dumpTokens(t, tokens)
+
+	expected := []Token{
+		{Type: Punctuation, Value: []rune("<"), Start: 0, End: 1},
+		{Type: Text, Value: []rune(""), Start: 1, End: 1},
+		{Type: NameTag, Value: []rune("script"), Start: 1, End: 7},
+		{Type: Punctuation, Value: []rune(">"), Start: 7, End: 8},
+		{Type: Text, Value: []rune("\n"), Start: 8, End: 9},
+		{Type: KeywordDeclaration, Value: []rune("$"), Start: 9, End: 10},
+		{Type: Punctuation, Value: []rune(":"), Start: 10, End: 11},
+		{Type: Text, Value: []rune(" "), Start: 11, End: 12},
+		{Type: NameOther, Value: []rune("d"), Start: 12, End: 13},
+		{Type: Text, Value: []rune(" "), Start: 13, End: 14},
+		{Type: Operator, Value: []rune("="), Start: 14, End: 15},
+		{Type: Text, Value: []rune(" "), Start: 15, End: 16},
+		{Type: NameOther, Value: []rune("n"), Start: 16, End: 17},
+		{Type: Text, Value: []rune(" "), Start: 17, End: 18},
+		{Type: Operator, Value: []rune("*"), Start: 18, End: 19},
+		{Type: Text, Value: []rune(" "), Start: 19, End: 20},
+		{Type: LiteralNumberInteger, Value: []rune("2"), Start: 20, End: 21},
+		{Type: Text, Value: []rune("\n"), Start: 21, End: 22},
+		{Type: Punctuation, Value: []rune("<"), Start: 22, End: 23},
+		{Type: Text, Value: []rune(""), Start: 23, End: 23},
+		{Type: Punctuation, Value: []rune("/"), Start: 23, End: 24},
+		{Type: Text, Value: []rune(""), Start: 24, End: 24},
+		{Type: NameTag, Value: []rune("script"), Start: 24, End: 30},
+		{Type: Text, Value: []rune(""), Start: 30, End: 30},
+		{Type: Punctuation, Value: []rune(">"), Start: 30, End: 31},
+		{Type: Text, Value: []rune("\n"), Start: 31, End: 32},
+		{Type: Punctuation, Value: []rune("{"), Start: 32, End: 33},
+		{Type: Text, Value: []rune(""), Start: 33, End: 33},
+		{Type: Punctuation, Value: []rune("#"), Start: 33, End: 34},
+		{Type: Keyword, Value: []rune("if"), Start: 34, End: 36},
+		{Type: Text, Value: []rune(" "), Start: 36, End: 37},
+		{Type: NameOther, Value: []rune("d"), Start: 37, End: 38},
+		{Type: Text, Value: []rune(" "), Start: 38, End: 39},
+		{Type: Keyword, Value: []rune("as"), Start: 39, End: 41},
+		{Type: Text, Value: []rune(" "), Start: 41, End: 42},
+		{Type: NameOther, Value: []rune("x"), Start: 42, End: 43},
+		{Type: Punctuation, Value: []rune("}<"), Start: 43, End: 45},
+		{Type: Text, Value: []rune(""), Start: 45, End: 45},
+		{Type: NameTag, Value: []rune("a"), Start: 45, End: 46},
+		{Type: Text, Value: []rune(" "), Start: 46, End: 47},
+		{Type: Keyword, Value: []rune("on"), Start: 47, End: 49},
+		{Type: Punctuation, Value: []rune(":"), Start: 49, End: 50},
+		{Type: NameAttribute, Value: []rune("click"), Start: 50, End: 55},
+		{Type: Text, Value: []rune(""), Start: 55, End: 55},
+		{Type: Operator, Value: []rune("="), Start: 55, End: 56},
+		{Type: Text, Value: []rune(""), Start: 56, End: 56},
+		{Type: Punctuation, Value: []rune("{"), Start: 56, End: 57},
+		{Type: NameOther, Value: []rune("f"), Start: 57, End: 58},
+		{Type: Punctuation, Value: []rune("}"), Start: 58, End: 59},
+		{Type: Text, Value: []rune(""), Start: 59, End: 59},
+		{Type: Punctuation, Value: []rune(">{"), Start: 59, End: 61},
+		{Type: NameOther, Value: []rune("d"), Start: 61, End: 62},
+		{Type: Punctuation, Value: []rune("}<"), Start: 62, End: 64},
+		{Type: Text, Value: []rune(""), Start: 64, End: 64},
+		{Type: Punctuation, Value: []rune("/"), Start: 64, End: 65},
+		{Type: Text, Value: []rune(""), Start: 65, End: 65},
+		{Type: NameTag, Value: []rune("a"), Start: 65, End: 66},
+		{Type: Text, Value: []rune(""), Start: 66, End: 66},
+		{Type: Punctuation, Value: []rune(">{"), Start: 66, End: 68},
+		{Type: Text, Value: []rune(""), Start: 68, End: 68},
+		{Type: Punctuation, Value: []rune("/"), Start: 68, End: 69},
+		{Type: Keyword, Value: []rune("if"), Start: 69, End: 71},
+		{Type: Punctuation, Value: []rune("}"), Start: 71, End: 72},
+		{Type: Text, Value: []rune("\n"), Start: 72, End: 73},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestDelegatingLexerError(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := newTestRegistry("html.xml")
+	lex := NewDelegatingLexer(LexerConfig{Name: "Broken Template"}, reg.Get("HTML"), brokenLexer())
+
+	it := lex.Tokenise([]rune("a (b"))
+	_, nextErr := it.Next()
+	assert.EqualError(nextErr, "syn.iterator: a rule refers to a state nested that doesn't exist")
+	_, nextErr = it.Next()
+	assert.Error(nextErr)
+}
@@ -0,0 +1,141 @@
+package syn
+
+import "fmt"
+
+// DiagnosticKind identifies the kind of problem described by a Diagnostic.
+type DiagnosticKind int
+
+const (
+	// DiagnosticUnmatched means no rule of the current state matched the input, so the input was
+	// returned as Error tokens.
+	DiagnosticUnmatched DiagnosticKind = iota
+	// DiagnosticTimeout means matching a rule's pattern took longer than the match timeout and was abandoned.
+	DiagnosticTimeout
+	// DiagnosticStackReset means the state stack was reset to the root state to recover from unmatched input.
+	DiagnosticStackReset
+	// DiagnosticStackTooDeep means a rule tried to push a state when the state stack was already at
+	// the maximum depth, so the stack was reset to the root state.
+	DiagnosticStackTooDeep
+)
+
+func (k DiagnosticKind) String() string {
+	switch k {
+	case DiagnosticUnmatched:
+		return "unmatched input"
+	case DiagnosticTimeout:
+		return "match timeout"
+	case DiagnosticStackReset:
+		return "stack reset"
+	case DiagnosticStackTooDeep:
+		return "stack too deep"
+	default:
+		return fmt.Sprintf("DiagnosticKind(%d)", int(k))
+	}
+}
+
+// Diagnostic describes a non-fatal problem that occurred while lexing. Lexing continues after such
+// problems, but the highlighting around the problem is likely degraded. Editors can use diagnostics to
+// show where that is the case.
+type Diagnostic struct {
+	Kind DiagnosticKind
+	// Offset is the index of the rune in the text at which the problem occurred, and Length is the number
+	// of runes affected.
+	Offset int
+	Length int
+	// Line is the zero-based line number of Offset, and Column the zero-based index of the rune at Offset in
+	// the line.
+	Line   int
+	Column int
+	// State is the name of the lexer state that was active.
+	State string
+	// Rule is the pattern of the rule involved, if any.
+	Rule string
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%d:%d: %s in state %s", d.Line+1, d.Column+1, d.Kind, d.State)
+	if d.Rule != "" {
+		s += fmt.Sprintf(" (rule /%s/)", d.Rule)
+	}
+	return s
+}
+
+// diagnostics collects the Diagnostics of an iterator and all its sublexers.
+type diagnostics struct {
+	// text is the original text being lexed. Offsets are converted to be relative to this text, and
+	// lines are counted in it.
+	text []rune
+	// transitions are the offsets in text of the \r of each \r\n that was converted to \n.
+	transitions []int
+	list        []Diagnostic
+	// lineOffset, line and lineStart cache the line number of a previously computed offset and the offset
+	// at which that line starts, since diagnostics are mostly added in order.
+	lineOffset, line, lineStart int
+}
+
+func newDiagnostics(text []rune) *diagnostics {
+	return &diagnostics{text: text}
+}
+
+// add records a problem at offset in the text with \r\n converted to \n. A \n that was converted
+// from \r\n is mapped to the offset of the \r.
+func (d *diagnostics) add(kind DiagnosticKind, offset, length int, state, rule string) {
+	if d == nil {
+		return
+	}
+
+	end := d.originalOffset(offset + length)
+	offset = d.originalOffset(offset)
+	length = end - offset
+
+	if n := len(d.list); n > 0 && kind == DiagnosticUnmatched {
+		last := &d.list[n-1]
+		if last.Kind == kind && last.State == state && last.Offset+last.Length == offset {
+			// Merge runs of unmatched runes into one diagnostic
+			last.Length += length
+			return
+		}
+	}
+
+	d.addOriginal(Diagnostic{Kind: kind, Offset: offset, Length: length, State: state, Rule: rule})
+}
+
+// addOriginal records a diagnostic whose Offset is already relative to the original text.
+func (d *diagnostics) addOriginal(diag Diagnostic) {
+	var lineStart int
+	diag.Line, lineStart = d.lineOf(diag.Offset)
+	diag.Column = diag.Offset - lineStart
+	d.list = append(d.list, diag)
+}
+
+func (d *diagnostics) originalOffset(offset int) int {
+	n := 0
+	for k, t := range d.transitions {
+		if t-k >= offset {
+			break
+		}
+		n++
+	}
+	return offset + n
+}
+
+// lineOf returns the zero-based line number of offset and the offset at which the line starts.
+func (d *diagnostics) lineOf(offset int) (line, start int) {
+	if offset < d.lineOffset {
+		d.lineOffset, d.line, d.lineStart = 0, 0, 0
+	}
+	for ; d.lineOffset < offset && d.lineOffset < len(d.text); d.lineOffset++ {
+		if d.text[d.lineOffset] == '\n' {
+			d.line++
+			d.lineStart = d.lineOffset + 1
+		}
+	}
+	return d.line, d.lineStart
+}
+
+func (d *diagnostics) get() []Diagnostic {
+	if d == nil {
+		return nil
+	}
+	return append([]Diagnostic(nil), d.list...)
+}
@@ -0,0 +1,98 @@
+package syn
+
+import "github.com/jeffwilliams/syn/internal/config"
+
+// Editing holds information about a language that editors can use to implement commands such as toggling
+// comments, automatically closing brackets and quotes, and automatic indentation. It is set by the editing element of the config of an
+// XML lexer definition:
+//
+//	<editing>
+//	  <line_comment>//</line_comment>
+//	  <block_comment open="/*" close="*/"/>
+//	  <bracket open="{" close="}"/>
+//	  <quote>"</quote>
+//	  <increase_indent>[{(\[]\s*$</increase_indent>
+//	  <decrease_indent>^\s*[})\]]</decrease_indent>
+//	</editing>
+type Editing struct {
+	// LineComments holds the prefixes that start a comment that ends at the end of the line. The first is
+	// the one to use when commenting out lines.
+	LineComments []string
+	// BlockComments holds the delimiters of comments that may span lines. The first is the one to use when
+	// commenting out a selection.
+	BlockComments []Delimiters
+	// Brackets holds the bracket pairs of the language.
+	Brackets []Delimiters
+	// Quotes holds the characters that start and end strings.
+	Quotes []string
+	// IncreaseIndent is a regular expression matching lines after which the indentation should be increased;
+	// see Lexer.NextLineIndent.
+	IncreaseIndent string
+	// DecreaseIndent is a regular expression matching lines that should be indented one level less than the
+	// line before them; see Lexer.ShouldOutdent.
+	DecreaseIndent string
+}
+
+// Delimiters is a pair of opening and closing delimiters, such as those of a block comment or a bracket pair.
+type Delimiters struct {
+	Open, Close string
+}
+
+func editingFromConfig(e config.Editing) Editing {
+	return Editing{
+		LineComments:   e.LineComments,
+		BlockComments:  delimitersFromConfig(e.BlockComments),
+		Brackets:       delimitersFromConfig(e.Brackets),
+		Quotes:         e.Quotes,
+		IncreaseIndent: e.IncreaseIndent,
+		DecreaseIndent: e.DecreaseIndent,
+	}
+}
+
+func delimitersFromConfig(ds []config.Delimited) []Delimiters {
+	if ds == nil {
+		return nil
+	}
+	out := make([]Delimiters, len(ds))
+	for i, d := range ds {
+		out[i] = Delimiters{Open: d.Open, Close: d.Close}
+	}
+	return out
+}
+
+func (e Editing) toConfig() config.Editing {
+	return config.Editing{
+		LineComments:   e.LineComments,
+		BlockComments:  delimitersToConfig(e.BlockComments),
+		Brackets:       delimitersToConfig(e.Brackets),
+		Quotes:         e.Quotes,
+		IncreaseIndent: e.IncreaseIndent,
+		DecreaseIndent: e.DecreaseIndent,
+	}
+}
+
+func delimitersToConfig(ds []Delimiters) []config.Delimited {
+	if ds == nil {
+		return nil
+	}
+	out := make([]config.Delimited, len(ds))
+	for i, d := range ds {
+		out[i] = config.Delimited{Open: d.Open, Close: d.Close}
+	}
+	return out
+}
+
+// Editing returns the editing information of the lexer's language. For delegating lexers whose LexerConfig
+// doesn't set it, the editing information of the language lexer is returned.
+func (l *Lexer) Editing() Editing {
+	e := editingFromConfig(l.config.Config.Editing)
+	if l.delegation != nil && e.empty() {
+		return l.delegation.language.Editing()
+	}
+	return e
+}
+
+func (e Editing) empty() bool {
+	return len(e.LineComments) == 0 && len(e.BlockComments) == 0 && len(e.Brackets) == 0 && len(e.Quotes) == 0 &&
+		e.IncreaseIndent == "" && e.DecreaseIndent == ""
+}
@@ -0,0 +1,145 @@
+package syn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// tokensMagic starts the encoding of a token stream, followed by its version.
+const (
+	tokensMagic   = "SYNT"
+	tokensVersion = 1
+)
+
+// EncodeTokens returns a compact binary encoding of tokens, so that the results of lexing can be stored, for
+// example in a cache of highlighted files, and read back with DecodeTokens without lexing the text again.
+//
+// The tokens' values and Meta are not encoded; DecodeTokens gets the values from the text. The offsets are
+// encoded as varints holding the gap since the end of the previous token, which is usually zero, and the length
+// of the token, and the types as indexes into a table of the names of the types used, so that the encoding
+// doesn't depend on the numeric values of the types. A typical token takes three bytes.
+func EncodeTokens(tokens []Token) []byte {
+	var types []TokenType
+	index := map[TokenType]uint64{}
+	for _, tok := range tokens {
+		if _, ok := index[tok.Type]; !ok {
+			index[tok.Type] = uint64(len(types))
+			types = append(types, tok.Type)
+		}
+	}
+
+	buf := append([]byte(tokensMagic), tokensVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(types)))
+	for _, t := range types {
+		name := t.String()
+		buf = binary.AppendUvarint(buf, uint64(len(name)))
+		buf = append(buf, name...)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(tokens)))
+	end := 0
+	for _, tok := range tokens {
+		buf = binary.AppendUvarint(buf, index[tok.Type])
+		buf = binary.AppendVarint(buf, int64(tok.Start-end))
+		buf = binary.AppendUvarint(buf, uint64(tok.Length()))
+		end = tok.End
+	}
+	return buf
+}
+
+// ErrInvalidTokenEncoding is returned by DecodeTokens if the data is not an encoding produced by EncodeTokens.
+var ErrInvalidTokenEncoding = errors.New("syn: invalid token encoding")
+
+// DecodeTokens decodes tokens encoded by EncodeTokens. text is the text that was lexed to produce the tokens; the
+// Value of each token is set to its slice of text, with no copy made. If text is nil the values are left nil, as
+// when lexing with the WithOffsetsOnly option.
+func DecodeTokens(data []byte, text []rune) ([]Token, error) {
+	if !bytes.HasPrefix(data, []byte(tokensMagic)) || len(data) <= len(tokensMagic) {
+		return nil, ErrInvalidTokenEncoding
+	}
+	if v := data[len(tokensMagic)]; v != tokensVersion {
+		return nil, fmt.Errorf("syn: unsupported token encoding version %d", v)
+	}
+	d := tokenDecoder{data: data[len(tokensMagic)+1:]}
+
+	types := make([]TokenType, d.count())
+	for i := range types {
+		name := d.bytes(d.uvarint())
+		if d.failed {
+			break
+		}
+		t, typeErr := TokenTypeString(string(name))
+		if typeErr != nil {
+			return nil, fmt.Errorf("syn: unknown token type %q in token encoding", name)
+		}
+		types[i] = t
+	}
+
+	tokens := make([]Token, d.count())
+	end := 0
+	for i := range tokens {
+		typ := d.uvarint()
+		start := end + int(d.varint())
+		end = start + int(d.uvarint())
+		if d.failed || typ >= uint64(len(types)) || start < 0 || end < start || (text != nil && end > len(text)) {
+			return nil, ErrInvalidTokenEncoding
+		}
+		tokens[i] = Token{Type: types[typ], Start: start, End: end}
+		if text != nil {
+			tokens[i].Value = text[start:end]
+		}
+	}
+	if d.failed || len(d.data) > 0 {
+		return nil, ErrInvalidTokenEncoding
+	}
+	return tokens, nil
+}
+
+// tokenDecoder reads the parts of a token encoding. failed is set if the data ends too soon.
+type tokenDecoder struct {
+	data   []byte
+	failed bool
+}
+
+func (d *tokenDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.failed = true
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *tokenDecoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.failed = true
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *tokenDecoder) bytes(n uint64) []byte {
+	if n > uint64(len(d.data)) {
+		d.failed = true
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+// count reads the number of items that follow, limited by the length of the remaining data so that corrupt data
+// can't cause a huge allocation.
+func (d *tokenDecoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.failed = true
+		return 0
+	}
+	return int(n)
+}
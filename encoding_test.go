@@ -0,0 +1,41 @@
+package syn
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeTokens(t *testing.T) {
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+	text := []rune("#include <stdio.h>\n\nint main() {\n\tprintf(\"héllo\\n\");\n}\n")
+	tokens := mylog.Check2(lex.TokensAll(text))
+
+	data := EncodeTokens(tokens)
+	decoded := mylog.Check2(DecodeTokens(data, text))
+	assert.Equal(tokens, decoded)
+
+	offsets := mylog.Check2(DecodeTokens(data, nil))
+	assert.Equal(mylog.Check2(lex.TokensAll(text, WithOffsetsOnly(true))), offsets)
+
+	js := mylog.Check2(json.Marshal(tokens))
+	assert.Less(len(data)*4, len(js))
+
+	// Tokens with gaps between them, as produced by some filters, are encoded too.
+	gappy := []Token{{Type: Keyword, Start: 2, End: 4}, {Type: Name, Start: 3, End: 5}}
+	assert.Equal(gappy, mylog.Check2(DecodeTokens(EncodeTokens(gappy), nil)))
+
+	empty := EncodeTokens(nil)
+	assert.Empty(mylog.Check2(DecodeTokens(empty, nil)))
+
+	for _, bad := range [][]byte{nil, []byte("JSON"), data[:len(data)-1], append(data, 0)} {
+		_, decodeErr := DecodeTokens(bad, text)
+		assert.ErrorIs(decodeErr, ErrInvalidTokenEncoding)
+	}
+	_, decodeErr := DecodeTokens(data, text[:5])
+	assert.ErrorIs(decodeErr, ErrInvalidTokenEncoding)
+}
@@ -0,0 +1,141 @@
+package syn
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// eolSplitter is a tokenSource decorator that splits the tokens read from the underlying tokenSource at
+// line endings, so that each \n or \r\n is returned as a separate token of type TextEOL.
+type eolSplitter struct {
+	it tokenSource
+	// pending holds the pieces of a split token that have not yet been returned.
+	pending []Token
+}
+
+func splitEOL(it tokenSource) tokenSource {
+	return &eolSplitter{it: it}
+}
+
+func (s *eolSplitter) Next() (tok Token, err error) {
+	if len(s.pending) == 0 {
+		next, nextErr := s.it.Next()
+		if nextErr != nil || next.Type == EOFType {
+			return next, nextErr
+		}
+		s.pending = splitAtEOL(next)
+	}
+
+	tok = s.pending[0]
+	s.pending = s.pending[1:]
+	return
+}
+
+// splitAtEOL splits tok into the runs of text between line endings and the line endings themselves.
+func splitAtEOL(tok Token) []Token {
+	var parts []Token
+
+	piece := func(typ TokenType, start, end int) {
+		parts = append(parts, Token{
+			Type:  typ,
+			Value: tok.Value[start:end],
+			Start: tok.Start + start,
+			End:   tok.Start + end,
+		})
+	}
+
+	last := 0
+	for i := 0; i < len(tok.Value); i++ {
+		end := i
+		switch {
+		case tok.Value[i] == '\n':
+			end = i + 1
+		case tok.Value[i] == '\r' && i+1 < len(tok.Value) && tok.Value[i+1] == '\n':
+			end = i + 2
+		default:
+			continue
+		}
+
+		if i > last {
+			piece(tok.Type, last, i)
+		}
+		piece(TextEOL, i, end)
+		i = end - 1
+		last = end
+	}
+
+	if last == 0 {
+		return []Token{tok}
+	}
+	if last < len(tok.Value) {
+		piece(tok.Type, last, len(tok.Value))
+	}
+	return parts
+}
+
+func (s *eolSplitter) State() IteratorState {
+	return &eolSplitterState{
+		pending:   append([]Token(nil), s.pending...),
+		iterState: s.it.State(),
+	}
+}
+
+func (s *eolSplitter) SetState(st IteratorState) {
+	state := st.(*eolSplitterState)
+
+	s.pending = append([]Token(nil), state.pending...)
+	s.it.SetState(state.iterState)
+}
+
+// eolSplitterState is the state of an eolSplitter, including the pieces of the current token that have
+// not yet been returned.
+type eolSplitterState struct {
+	pending   []Token
+	iterState IteratorState
+}
+
+func (s eolSplitterState) Equal(o IteratorState) bool {
+	other, ok := o.(*eolSplitterState)
+	if !ok || len(s.pending) != len(other.pending) {
+		return false
+	}
+
+	for i, tok := range s.pending {
+		o := other.pending[i]
+		if tok.Type != o.Type || tok.Start != o.Start || tok.End != o.End {
+			return false
+		}
+	}
+
+	return s.iterState.Equal(other.iterState)
+}
+
+func (s *eolSplitterState) SetIndex(i int) {
+	if len(s.pending) == 0 {
+		s.iterState.SetIndex(i)
+		return
+	}
+	s.AddToIndex(i - s.pending[0].Start)
+}
+
+func (s *eolSplitterState) AddToIndex(delta int) {
+	for i := range s.pending {
+		s.pending[i].Start += delta
+		s.pending[i].End += delta
+	}
+	s.iterState.AddToIndex(delta)
+}
+
+func (s eolSplitterState) String() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "eolSplitterState: \n")
+	fmt.Fprintf(&buf, "  pending: %v\n", s.pending)
+
+	st, ok := s.iterState.(fmt.Stringer)
+	if ok {
+		fmt.Fprintf(&buf, "  iterState:\n%s", st.String())
+	}
+
+	return buf.String()
+}
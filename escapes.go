@@ -0,0 +1,140 @@
+package syn
+
+import (
+	"strings"
+
+	"github.com/dlclark/regexp2"
+)
+
+// EscapeSyntax describes the escape sequences and format directives of the strings of a language, for use
+// by the filter returned by NewEscapeFilter.
+type EscapeSyntax struct {
+	// Escapes is a regular expression matching an escape sequence, such as \n or \x41.
+	Escapes string
+	// Formats is a regular expression matching a format directive, such as %d. It may be empty if the
+	// language has none.
+	Formats string
+	// Raw holds the prefixes of the string tokens in which escape sequences are not interpreted, such as ` for
+	// the raw strings of Go.
+	Raw []string
+}
+
+const printfFormats = `%(?:%|[-+ #0]*(?:\*|\d+)?(?:\.(?:\*|\d+))?(?:hh|h|ll|l|L|z|j|t)?[diouxXeEfFgGaAcspn])`
+
+// The escape syntaxes of the strings of some languages. CEscapes is used for both C and C++.
+var (
+	CEscapes = EscapeSyntax{
+		Escapes: `\\(?:[abefnrtv\\'"?]|[0-7]{1,3}|x[0-9a-fA-F]+|u[0-9a-fA-F]{4}|U[0-9a-fA-F]{8})`,
+		Formats: printfFormats,
+		Raw:     []string{`R"`, `LR"`, `uR"`, `UR"`, `u8R"`},
+	}
+	GoEscapes = EscapeSyntax{
+		Escapes: `\\(?:[abfnrtv\\'"]|[0-7]{3}|x[0-9a-fA-F]{2}|u[0-9a-fA-F]{4}|U[0-9a-fA-F]{8})`,
+		Formats: `%(?:%|[-+# 0]*(?:\[\d+\])?(?:\*|\d+)?(?:\.(?:\*|\d+)?)?(?:\[\d+\])?[vTtbcdoOqxXUeEfFgGsp])`,
+		Raw:     []string{"`"},
+	}
+	JavaEscapes = EscapeSyntax{
+		Escapes: `\\(?:[btnfrs\\'"]|[0-7]{1,3}|u+[0-9a-fA-F]{4})`,
+		Formats: `%(?:[%n]|(?:\d+\$)?[-#+ 0,(]*\d*(?:\.\d+)?(?:[tT][a-zA-Z]|[bBhHsScCdoxXeEfgGaA]))`,
+	}
+	JavaScriptEscapes = EscapeSyntax{
+		Escapes: `\\(?:u\{[0-9a-fA-F]+\}|u[0-9a-fA-F]{4}|x[0-9a-fA-F]{2}|[0-7]{1,3}|[^\r\n0-7ux])`,
+	}
+	CSharpEscapes = EscapeSyntax{
+		Escapes: `\\(?:[abefnrtv0\\'"]|x[0-9a-fA-F]{1,4}|u[0-9a-fA-F]{4}|U[0-9a-fA-F]{8})`,
+		Formats: `\{\d+(?:,-?\d+)?(?::[^{}]*)?\}|\{\{|\}\}`,
+		Raw:     []string{`@"`, `$@"`, `@$"`, `"""`},
+	}
+)
+
+// NewEscapeFilter returns a Filter that splits the escape sequences and format directives described by
+// syntax out of String tokens, into tokens of types LiteralStringEscape and LiteralStringFormat, so that
+// grammars don't each need to model them. Tokens that the grammar has already given a more specific type,
+// such as LiteralStringEscape, LiteralStringInterpol or LiteralStringRegex, are left as they are.
+//
+// The syntaxes of some languages are predefined, such as GoEscapes, and are used by default by the lexers for
+// those languages in the registry of the lexers package.
+func NewEscapeFilter(syntax EscapeSyntax) (Filter, error) {
+	var alts []string
+	if syntax.Escapes != "" {
+		alts = append(alts, "(?<escape>"+syntax.Escapes+")")
+	}
+	if syntax.Formats != "" {
+		alts = append(alts, "(?<format>"+syntax.Formats+")")
+	}
+	f := &escapeFilter{raw: syntax.Raw}
+	if len(alts) > 0 {
+		pattern, compileErr := regexp2.Compile(strings.Join(alts, "|"), regexp2.ExplicitCapture)
+		if compileErr != nil {
+			return nil, compileErr
+		}
+		f.pattern = pattern
+	}
+	return FilterFunc(f.filter), nil
+}
+
+type escapeFilter struct {
+	pattern *regexp2.Regexp
+	raw     []string
+}
+
+func (f *escapeFilter) filter(tok Token) []Token {
+	if f.pattern == nil || !f.applies(tok) {
+		return []Token{tok}
+	}
+
+	var toks []Token
+	piece := func(typ TokenType, start, end int, meta any) {
+		toks = append(toks, Token{
+			Type:  typ,
+			Value: tok.Value[start:end],
+			Start: tok.Start + start,
+			End:   tok.Start + end,
+			Meta:  meta,
+		})
+	}
+
+	last := 0
+	// The pattern has no match timeout, so matching can't fail.
+	m, _ := f.pattern.FindRunesMatch(tok.Value)
+	for ; m != nil; m, _ = f.pattern.FindNextMatch(m) {
+		if m.Length == 0 {
+			continue
+		}
+		if m.Index > last {
+			piece(tok.Type, last, m.Index, tok.Meta)
+		}
+		typ := LiteralStringEscape
+		if m.GroupByName("format").Length > 0 {
+			typ = LiteralStringFormat
+		}
+		piece(typ, m.Index, m.Index+m.Length, nil)
+		last = m.Index + m.Length
+	}
+
+	if last == 0 {
+		return []Token{tok}
+	}
+	if last < len(tok.Value) {
+		piece(tok.Type, last, len(tok.Value), tok.Meta)
+	}
+	return toks
+}
+
+// applies returns whether tok is a string token in which escape sequences are interpreted.
+func (f *escapeFilter) applies(tok Token) bool {
+	switch tok.Type {
+	case LiteralStringAffix, LiteralStringAtom, LiteralStringBoolean, LiteralStringDelimiter, LiteralStringEscape,
+		LiteralStringFormat, LiteralStringInterpol, LiteralStringName, LiteralStringRegex, LiteralStringSymbol:
+		return false
+	}
+	if !tok.Type.InSubCategory(LiteralString) {
+		return false
+	}
+	for _, p := range f.raw {
+		if strings.HasPrefix(string(tok.Value), p) {
+			return false
+		}
+	}
+	return true
+}
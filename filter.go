@@ -0,0 +1,193 @@
+package syn
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Filter changes the tokens produced by a lexer before they are returned by an Iterator. Filters are set
+// using the option WithFilters.
+type Filter interface {
+	// Filter returns the tokens that replace tok in the token stream. This is usually tok itself, perhaps
+	// with a different Type or Meta, or tok split into several tokens which together cover the same text.
+	Filter(tok Token) []Token
+	// Clone returns a copy of the filter including any state it keeps between tokens. Each Iterator uses its
+	// own clone of the filters so that a filter may be used by more than one at a time, and the state of an
+	// Iterator includes clones of its filters. A filter that keeps no state may return itself.
+	Clone() Filter
+}
+
+// Flusher is implemented by Filters that hold back tokens, by returning fewer tokens than they are given,
+// until they have seen the tokens that follow. Flush is called when the end of the input is reached and
+// returns the tokens still held.
+type Flusher interface {
+	Flush() []Token
+}
+
+// FilterFunc is a Filter that keeps no state between tokens.
+type FilterFunc func(tok Token) []Token
+
+func (f FilterFunc) Filter(tok Token) []Token {
+	return f(tok)
+}
+
+func (f FilterFunc) Clone() Filter {
+	return f
+}
+
+// WithFilters sets the filters that the tokens are passed through, in order, before being returned. The
+// filters are applied after line endings are split from tokens if WithEOLTokens is set.
+func WithFilters(filters ...Filter) Option {
+	return func(o *lexerOptions) {
+		o.iterOpts.filters = filters
+	}
+}
+
+// filterer is a tokenSource decorator that passes the tokens read from the underlying tokenSource
+// through a list of Filters.
+type filterer struct {
+	it      tokenSource
+	filters []Filter
+	// pending holds the tokens produced by the filters that have not yet been returned.
+	pending []Token
+	// eof is set once the underlying tokenSource has returned its EOFType token, which is held in eofTok
+	// until the tokens flushed from the filters have been returned.
+	eof    bool
+	eofTok Token
+}
+
+func applyFilters(it tokenSource, filters []Filter) tokenSource {
+	return &filterer{it: it, filters: cloneFilters(filters)}
+}
+
+func cloneFilters(filters []Filter) []Filter {
+	clones := make([]Filter, len(filters))
+	for i, f := range filters {
+		clones[i] = f.Clone()
+	}
+	return clones
+}
+
+func (f *filterer) Next() (tok Token, err error) {
+	for len(f.pending) == 0 {
+		if f.eof {
+			return f.eofTok, nil
+		}
+
+		next, nextErr := f.it.Next()
+		if nextErr != nil {
+			return next, nextErr
+		}
+		if next.Type == EOFType {
+			f.eof = true
+			f.eofTok = next
+			f.pending = f.flush()
+			continue
+		}
+
+		toks := []Token{next}
+		for _, filter := range f.filters {
+			toks = filterAll(filter, toks)
+		}
+		f.pending = toks
+	}
+
+	tok = f.pending[0]
+	f.pending = f.pending[1:]
+	return
+}
+
+func filterAll(filter Filter, toks []Token) []Token {
+	var out []Token
+	for _, t := range toks {
+		out = append(out, filter.Filter(t)...)
+	}
+	return out
+}
+
+// flush returns the tokens held by the filters at the end of the input. The tokens flushed from
+// each filter are passed through the filters after it.
+func (f *filterer) flush() []Token {
+	var toks []Token
+	for _, filter := range f.filters {
+		toks = filterAll(filter, toks)
+		if fl, ok := filter.(Flusher); ok {
+			toks = append(toks, fl.Flush()...)
+		}
+	}
+	return toks
+}
+
+func (f *filterer) State() IteratorState {
+	return &filtererState{
+		filters:   cloneFilters(f.filters),
+		pending:   append([]Token(nil), f.pending...),
+		eof:       f.eof,
+		eofTok:    f.eofTok,
+		iterState: f.it.State(),
+	}
+}
+
+func (f *filterer) SetState(s IteratorState) {
+	state := s.(*filtererState)
+
+	f.filters = cloneFilters(state.filters)
+	f.pending = append([]Token(nil), state.pending...)
+	f.eof = state.eof
+	f.eofTok = state.eofTok
+	f.it.SetState(state.iterState)
+}
+
+type filtererState struct {
+	filters   []Filter
+	pending   []Token
+	eof       bool
+	eofTok    Token
+	iterState IteratorState
+}
+
+func (s filtererState) Equal(o IteratorState) bool {
+	other, ok := o.(*filtererState)
+	if !ok || len(s.pending) != len(other.pending) || s.eof != other.eof {
+		return false
+	}
+
+	for i, tok := range s.pending {
+		o := other.pending[i]
+		if tok.Type != o.Type || tok.Start != o.Start || tok.End != o.End {
+			return false
+		}
+	}
+
+	return s.iterState.Equal(other.iterState)
+}
+
+func (s *filtererState) SetIndex(i int) {
+	if len(s.pending) == 0 {
+		s.iterState.SetIndex(i)
+		return
+	}
+	s.AddToIndex(i - s.pending[0].Start)
+}
+
+func (s *filtererState) AddToIndex(delta int) {
+	for i := range s.pending {
+		s.pending[i].Start += delta
+		s.pending[i].End += delta
+	}
+	s.iterState.AddToIndex(delta)
+}
+
+func (s filtererState) String() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "filtererState: \n")
+	fmt.Fprintf(&buf, "  pending: %v\n", s.pending)
+
+	st, ok := s.iterState.(fmt.Stringer)
+	if ok {
+		fmt.Fprintf(&buf, "  iterState:\n%s", st.String())
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,124 @@
+package gio
+
+import (
+	"strings"
+	"testing"
+
+	"gioui.org/font"
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+)
+
+func TestStyleGet(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(DefaultStyle.Tokens[syn.Keyword], DefaultStyle.Get(syn.KeywordReserved))
+	assert.Equal(DefaultStyle.Tokens[syn.LiteralString], DefaultStyle.Get(syn.LiteralStringDouble))
+	assert.Equal(DefaultStyle.Default, DefaultStyle.Get(syn.Text))
+}
+
+func TestHighlighterSpans(t *testing.T) {
+	prog := []rune("int x = 1; // one\n")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(syn.NewLexerFromXMLFile("../lexers/embedded/c.xml"))
+
+	h := NewHighlighter(lex, 12)
+	h.SetText(prog)
+
+	var contents []string
+	for _, s := range h.Spans(2, 14) {
+		contents = append(contents, s.Content)
+	}
+	assert.Equal([]string{"t", " ", "x", " ", "=", " ", "1", ";", " ", "// "}, contents)
+
+	spans := h.Spans(0, len(prog))
+	assert.Equal("int", spans[0].Content)
+	assert.Equal(DefaultStyle.Get(syn.KeywordType).Color, spans[0].Color)
+	assert.Equal("// one\n", spans[len(spans)-1].Content)
+	assert.Equal(font.Italic, spans[len(spans)-1].Font.Style)
+
+	tokens := mylog.Check2(lex.TokensAll(prog))
+	assert.Equal(spans, Spans(prog, tokens, DefaultStyle, font.Font{}, 12))
+}
+
+func TestHighlighterSetText(t *testing.T) {
+	prog := []rune("int x = 1;\n/* a\n   b */\nint y = 2;\nchar *z;\n")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(syn.NewLexerFromXMLFile("../lexers/embedded/c.xml"))
+
+	h := NewHighlighter(lex, 12)
+	h.SetText(prog)
+	h.Spans(0, len(prog))
+	kept := h.Tokens(0, 11)
+
+	// Changes are lexed again from a line before them, and earlier lines keep their tokens.
+	for _, edit := range []string{
+		"int x = 1;\n/* a\n   b */\nint y = 2;\nchar *w;\n",
+		"int x = 1;\n/* a\n   b */\nint y = 2;\nchar *w; // w\n",
+		"int x = 1;\n/* a\n   b\nint y = 2;\nchar *w;\n",
+		"long x = 1;\n",
+		"",
+	} {
+		text := []rune(edit)
+		h.SetText(text)
+		fresh := NewHighlighter(lex, 12)
+		fresh.SetText(text)
+		assert.Equal(fresh.Spans(0, len(text)), h.Spans(0, len(text)), edit)
+	}
+
+	h.SetText(prog)
+	h.Spans(0, len(prog))
+	h.SetText([]rune(string(prog) + "int u;\n"))
+	assert.Equal(kept, h.tokens[:len(kept)])
+}
+
+const brokenLexer = `<lexer>
+  <config>
+    <name>broken</name>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern="\(">
+        <token type="Punctuation"/>
+        <push state="missing"/>
+      </rule>
+      <rule pattern="\w+">
+        <token type="Keyword"/>
+      </rule>
+      <rule pattern="\s+">
+        <token type="Text"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>`
+
+func TestHighlighterError(t *testing.T) {
+	prog := []rune("a (b c")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(syn.NewLexerFromXML(strings.NewReader(brokenLexer)))
+
+	h := NewHighlighter(lex, 12)
+	h.SetText(prog)
+
+	spans := h.Spans(0, len(prog))
+	assert.EqualError(h.Err(), "syn.iterator: a rule refers to a state missing that doesn't exist")
+	var contents []string
+	for _, s := range spans {
+		contents = append(contents, s.Content)
+	}
+	assert.Equal([]string{"a", " (b c"}, contents)
+	assert.Equal(DefaultStyle.Get(syn.Keyword).Color, spans[0].Color)
+	assert.Equal(DefaultStyle.Default.Color, spans[1].Color)
+
+	h.SetText([]rune("a b"))
+	h.Spans(0, 3)
+	assert.NoError(h.Err())
+}
@@ -0,0 +1,182 @@
+package gio
+
+import (
+	"sort"
+
+	"gioui.org/font"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/x/styledtext"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// Highlighter lays out text highlighted using a syn Lexer. The text is lexed only as far as is needed for
+// the spans requested, and lexing continues from where it stopped on later calls, so that displaying the start
+// of a long text doesn't require lexing all of it. Changing the text with SetText starts lexing again from the
+// start of a line before the change, keeping the tokens of the lines above.
+type Highlighter struct {
+	Lexer *syn.Lexer
+	Style Style
+	Font  font.Font
+	Size  unit.Sp
+
+	text   []rune
+	it     syn.Iterator
+	tokens []syn.Token
+	// lines holds the state of it at the start of each line lexed so far.
+	lines []lineState
+	done  bool
+	err   error
+}
+
+// lineState is the state of the iterator of a Highlighter after it returned the last token of a line.
+type lineState struct {
+	// tokens is the number of tokens returned before the state was saved.
+	tokens int
+	state  syn.IteratorState
+}
+
+// NewHighlighter returns a Highlighter that lexes using lexer and uses the style DefaultStyle.
+func NewHighlighter(lexer *syn.Lexer, size unit.Sp) *Highlighter {
+	return &Highlighter{
+		Lexer: lexer,
+		Style: DefaultStyle,
+		Size:  size,
+	}
+}
+
+// SetText sets the text to be highlighted. If it is unchanged the tokens already produced are kept; otherwise
+// the tokens of the lines before the first change are.
+func (h *Highlighter) SetText(text []rune) {
+	if h.it != nil && string(text) == string(h.text) {
+		return
+	}
+	changed := firstDifference(h.text, text)
+	h.text = text
+	h.done = false
+	h.err = nil
+
+	// Restart from the last line whose first token ends before the change. The iterator has read that token
+	// when its state is saved, so the token mustn't be affected by the change.
+	for i := len(h.lines) - 1; i >= 0; i-- {
+		line := h.lines[i]
+		if line.tokens < len(h.tokens) && h.tokens[line.tokens].End < changed {
+			h.it = h.Lexer.Tokenise(h.text, syn.WithOffsetsOnly(true))
+			h.it.SetState(line.state)
+			h.tokens = h.tokens[:line.tokens]
+			// The iterator now shares the saved state, so it is saved again.
+			h.lines = append(h.lines[:i], lineState{tokens: line.tokens, state: h.it.State()})
+			return
+		}
+	}
+	h.it = nil
+	h.tokens = h.tokens[:0]
+	h.lines = h.lines[:0]
+}
+
+// firstDifference returns the index of the first rune that differs between a and b, or the length of the
+// shorter of the two if it is a prefix of the other.
+func firstDifference(a, b []rune) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// Text returns the text being highlighted.
+func (h *Highlighter) Text() []rune {
+	return h.text
+}
+
+// Err returns the error that lexing the text failed with, if any. The text after the point where lexing stopped
+// is laid out unstyled.
+func (h *Highlighter) Err() error {
+	return h.err
+}
+
+// lexTo lexes until the tokens cover the text up to end.
+func (h *Highlighter) lexTo(end int) {
+	if h.it == nil {
+		h.it = h.Lexer.Tokenise(h.text, syn.WithOffsetsOnly(true))
+	}
+
+	for !h.done && (len(h.tokens) == 0 || h.tokens[len(h.tokens)-1].End < end) {
+		tok, nextErr := h.it.Next()
+		if nextErr != nil {
+			h.stop(nextErr)
+			break
+		}
+		if tok.Type == syn.EOFType {
+			h.done = true
+			break
+		}
+		h.tokens = append(h.tokens, tok)
+		if tok.End > 0 && h.text[tok.End-1] == '\n' {
+			h.lines = append(h.lines, lineState{tokens: len(h.tokens), state: h.it.State()})
+		}
+	}
+}
+
+// stop stops lexing after the error err, adding a Text token for the rest of the text.
+func (h *Highlighter) stop(err error) {
+	h.err = err
+	h.done = true
+
+	start := 0
+	if len(h.tokens) > 0 {
+		start = h.tokens[len(h.tokens)-1].End
+	}
+	if start < len(h.text) {
+		h.tokens = append(h.tokens, syn.Token{Type: syn.Text, Start: start, End: len(h.text)})
+	}
+}
+
+// Tokens returns the tokens that overlap the range of text from start to end. The first and last token may
+// extend outside of the range.
+func (h *Highlighter) Tokens(start, end int) []syn.Token {
+	h.lexTo(end)
+
+	first := sort.Search(len(h.tokens), func(i int) bool {
+		return h.tokens[i].End > start
+	})
+	last := sort.Search(len(h.tokens), func(i int) bool {
+		return h.tokens[i].Start >= end
+	})
+	if first > last {
+		return nil
+	}
+	return h.tokens[first:last]
+}
+
+// Spans returns the styled text spans for the range of text from start to end.
+func (h *Highlighter) Spans(start, end int) []styledtext.SpanStyle {
+	start = max(0, start)
+	end = min(end, len(h.text))
+
+	tokens := h.Tokens(start, end)
+	spans := make([]styledtext.SpanStyle, 0, len(tokens))
+	for _, tok := range tokens {
+		s, e := max(tok.Start, start), min(tok.End, end)
+		if s >= e {
+			continue
+		}
+		spans = append(spans, span(string(h.text[s:e]), h.Style.Get(tok.Type), h.Font, h.Size))
+	}
+	return spans
+}
+
+// Layout lays out the whole text using shaper.
+func (h *Highlighter) Layout(gtx layout.Context, shaper *text.Shaper) layout.Dimensions {
+	return h.LayoutRange(gtx, shaper, 0, len(h.text))
+}
+
+// LayoutRange lays out the range of text from start to end using shaper. An editor that displays a window onto
+// a long text can use this to lay out only the lines that are visible.
+func (h *Highlighter) LayoutRange(gtx layout.Context, shaper *text.Shaper, start, end int) layout.Dimensions {
+	return styledtext.Text(shaper, h.Spans(start, end)...).Layout(gtx, nil)
+}
@@ -0,0 +1,97 @@
+// Package gio maps the tokens produced by syn lexers to the styled text spans of Gio (gioui.org), and provides
+// a Highlighter that lays out highlighted text in a Gio user interface.
+package gio
+
+import (
+	"image/color"
+
+	"gioui.org/font"
+	"gioui.org/unit"
+	"gioui.org/x/styledtext"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// TokenStyle is how the text of a token is drawn.
+type TokenStyle struct {
+	Color  color.NRGBA
+	Weight font.Weight
+	Style  font.Style
+}
+
+// Style maps token types to how the tokens are drawn.
+type Style struct {
+	// Default is used for tokens whose type, and the parents of whose type, are not in Tokens.
+	Default TokenStyle
+	Tokens  map[syn.TokenType]TokenStyle
+}
+
+// Get returns the TokenStyle for tokens of type t. If t is not in s.Tokens, the style of its closest parent
+// type that is is returned, or Default if none are.
+func (s Style) Get(t syn.TokenType) TokenStyle {
+	for {
+		if ts, ok := s.Tokens[t]; ok {
+			return ts
+		}
+		if t == 0 {
+			return s.Default
+		}
+		t = t.Parent()
+	}
+}
+
+// DefaultStyle is a light style that can be used when the application doesn't provide its own.
+var DefaultStyle = Style{
+	Default: TokenStyle{Color: rgb(0x24292e)},
+	Tokens: map[syn.TokenType]TokenStyle{
+		syn.Error:              {Color: rgb(0xb31d28)},
+		syn.Keyword:            {Color: rgb(0xd73a49), Weight: font.Bold},
+		syn.KeywordType:        {Color: rgb(0x6f42c1)},
+		syn.NameBuiltin:        {Color: rgb(0x005cc5)},
+		syn.NameClass:          {Color: rgb(0x6f42c1)},
+		syn.NameFunction:       {Color: rgb(0x6f42c1)},
+		syn.NameTag:            {Color: rgb(0x22863a)},
+		syn.NameAttribute:      {Color: rgb(0x6f42c1)},
+		syn.NameDecorator:      {Color: rgb(0x6f42c1)},
+		syn.LiteralString:      {Color: rgb(0x032f62)},
+		syn.LiteralStringRegex: {Color: rgb(0x22863a)},
+		syn.LiteralNumber:      {Color: rgb(0x005cc5)},
+		syn.Operator:           {Color: rgb(0xd73a49)},
+		syn.Comment:            {Color: rgb(0x6a737d), Style: font.Italic},
+		syn.CommentPreproc:     {Color: rgb(0xd73a49), Style: font.Regular},
+		syn.GenericDeleted:     {Color: rgb(0xb31d28)},
+		syn.GenericInserted:    {Color: rgb(0x22863a)},
+		syn.GenericHeading:     {Color: rgb(0x005cc5), Weight: font.Bold},
+		syn.GenericEmph:        {Style: font.Italic},
+		syn.GenericStrong:      {Weight: font.Bold},
+	},
+}
+
+func rgb(c uint32) color.NRGBA {
+	return color.NRGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xff}
+}
+
+// Spans returns the styled text spans for tokens, which were produced by lexing text. The spans use the
+// typeface of fnt and the size size, and the colour, weight and style given by style. Tokens need not have
+// their Value set, so tokens produced using the option syn.WithOffsetsOnly can be used.
+func Spans(text []rune, tokens []syn.Token, style Style, fnt font.Font, size unit.Sp) []styledtext.SpanStyle {
+	spans := make([]styledtext.SpanStyle, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.Length() == 0 {
+			continue
+		}
+		spans = append(spans, span(string(tok.ValueIn(text)), style.Get(tok.Type), fnt, size))
+	}
+	return spans
+}
+
+func span(content string, ts TokenStyle, fnt font.Font, size unit.Sp) styledtext.SpanStyle {
+	fnt.Weight = ts.Weight
+	fnt.Style = ts.Style
+	return styledtext.SpanStyle{
+		Font:    fnt,
+		Size:    size,
+		Color:   ts.Color,
+		Content: content,
+	}
+}
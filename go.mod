@@ -3,13 +3,37 @@ module github.com/jeffwilliams/syn
 go 1.22.4
 
 require (
+	gioui.org v0.7.1
+	gioui.org/x v0.7.1
+	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/charmbracelet/lipgloss v0.12.1
+	github.com/ddkwork/golibrary v0.0.83
 	github.com/dlclark/regexp2 v1.11.0
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/muesli/termenv v0.15.2
 	github.com/stretchr/testify v1.9.0
 )
 
 require (
+	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
+	github.com/charmbracelet/x/ansi v0.1.4 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
-	github.com/ddkwork/golibrary v0.0.83 // indirect
+	github.com/dc0d/caseconv v0.5.0 // indirect
+	github.com/fatih/color v1.17.0 // indirect
+	github.com/go-text/typesetting v0.1.1 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	golang.org/x/exp v0.0.0-20240707233637-46b078467d37 // indirect
+	golang.org/x/image v0.18.0 // indirect
+	golang.org/x/mod v0.19.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	golang.org/x/tools v0.23.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
+	mvdan.cc/gofumpt v0.6.0 // indirect
 )
@@ -0,0 +1,96 @@
+package syn
+
+import (
+	"strings"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/dlclark/regexp2"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+// indentRules holds the compiled IncreaseIndent and DecreaseIndent patterns of the lexer's Editing.
+type indentRules struct {
+	increase, decrease *regexp2.Regexp
+}
+
+func newIndentRules(e config.Editing) (r indentRules, err error) {
+	if e.IncreaseIndent != "" {
+		r.increase = mylog.Check2(regexp2.Compile(e.IncreaseIndent, regexp2.Multiline))
+	}
+	if e.DecreaseIndent != "" {
+		r.decrease = mylog.Check2(regexp2.Compile(e.DecreaseIndent, regexp2.Multiline))
+	}
+	return
+}
+
+func (l *Lexer) indentRules() indentRules {
+	if l.delegation != nil && l.indent.increase == nil && l.indent.decrease == nil {
+		return l.delegation.language.indentRules()
+	}
+	return l.indent
+}
+
+// NextLineIndent suggests the indentation for the line after a line of text, which is the indentation of the
+// line with unit, such as "\t" or "    ", added if the lexer's increase_indent pattern matches the line. line
+// holds the tokens of the line, which must be tokens produced by lexing text. Since the patterns are matched
+// against the code of the line, comments are removed and each string literal is replaced by "", so that
+// brackets in strings and comments don't affect the indentation.
+func (l *Lexer) NextLineIndent(text []rune, line []Token, unit string) string {
+	indent := leadingSpace(text, line)
+	if matchLine(l.indentRules().increase, text, line) {
+		return indent + unit
+	}
+	return indent
+}
+
+// ShouldOutdent returns whether a line of text should be indented one level less than the line before it,
+// because the lexer's decrease_indent pattern matches it. An editor can use this to outdent a line as a closing
+// bracket or a keyword such as else is typed. The line is matched as described for NextLineIndent.
+func (l *Lexer) ShouldOutdent(text []rune, line []Token) bool {
+	return matchLine(l.indentRules().decrease, text, line)
+}
+
+func matchLine(re *regexp2.Regexp, text []rune, line []Token) bool {
+	if re == nil {
+		return false
+	}
+	// An error can only be a timeout, in which case it's assumed the pattern doesn't match.
+	ok, _ := re.MatchString(lineCode(text, line))
+	return ok
+}
+
+// lineCode returns the text of the line with comments removed and string literals replaced by "".
+func lineCode(text []rune, line []Token) string {
+	var b strings.Builder
+	inString := false
+	for _, tok := range line {
+		switch {
+		case tok.Type.InCategory(Comment) && !tok.Type.InSubCategory(CommentPreproc):
+			inString = false
+		case tok.Type.InSubCategory(LiteralString):
+			if !inString {
+				b.WriteString(`""`)
+			}
+			inString = true
+		default:
+			inString = false
+			b.WriteString(strings.TrimRight(string(tok.ValueIn(text)), "\r\n"))
+		}
+	}
+	return b.String()
+}
+
+// leadingSpace returns the spaces and tabs at the start of the line.
+func leadingSpace(text []rune, line []Token) string {
+	var b strings.Builder
+	for _, tok := range line {
+		for _, r := range tok.ValueIn(text) {
+			if r != ' ' && r != '\t' {
+				return b.String()
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -11,8 +11,16 @@ import (
 	"github.com/ddkwork/golibrary/mylog"
 )
 
+// SchemaVersion is the newest version of the schema of lexer definitions that DecodeLexer supports. A definition
+// gives the version it is written for in the version attribute of its lexer element; a definition without one
+// is taken to be version 1.
+//
+// Version 1 is the schema of Chroma's lexer definitions. Version 2 adds the editing element.
+const SchemaVersion = 2
+
 type Lexer struct {
 	XMLName xml.Name `xml:"lexer"`
+	Version int      `xml:"version,attr,omitempty"`
 	Config  Config   `xml:"config"`
 	Rules   Rules    `xml:"rules"`
 }
@@ -24,6 +32,43 @@ type Config struct {
 	MimeTypes []string `xml:"mime_type"`
 	EnsureNL  bool     `xml:"ensure_nl"`
 	Priority  float32  `xml:"priority,omitempty"`
+
+	CaseInsensitive bool `xml:"case_insensitive,omitempty"`
+	DotAll          bool `xml:"dot_all,omitempty"`
+	NotMultiline    bool `xml:"not_multiline,omitempty"`
+
+	Analyse *Analyse `xml:"analyse,omitempty"`
+	Editing Editing  `xml:"editing"`
+}
+
+// Analyse holds the patterns that text in the language is likely to match, used to recognise the language by the
+// content of a file. It is the same as the analyse element of Chroma's definitions.
+type Analyse struct {
+	Regexes []AnalyseRegex `xml:"regex"`
+	First   bool           `xml:"first,attr,omitempty"`
+}
+
+// AnalyseRegex is a pattern of an Analyse element and the score that text matching it is given.
+type AnalyseRegex struct {
+	Pattern string  `xml:"pattern,attr"`
+	Score   float32 `xml:"score,attr"`
+}
+
+// Editing holds information about the language that editors use to implement commands such as toggling
+// comments, automatically closing brackets and quotes, and automatic indentation.
+type Editing struct {
+	LineComments   []string    `xml:"line_comment"`
+	BlockComments  []Delimited `xml:"block_comment"`
+	Brackets       []Delimited `xml:"bracket"`
+	Quotes         []string    `xml:"quote"`
+	IncreaseIndent string      `xml:"increase_indent,omitempty"`
+	DecreaseIndent string      `xml:"decrease_indent,omitempty"`
+}
+
+// Delimited is a pair of opening and closing delimiters, such as those of a block comment or a bracket pair.
+type Delimited struct {
+	Open  string `xml:"open,attr"`
+	Close string `xml:"close,attr"`
 }
 
 type Rules struct {
@@ -43,6 +88,7 @@ type Rule struct {
 	Push      *Push      `xml:"push"`
 	ByGroups  *ByGroups  `xml:"bygroups"`
 	UsingSelf *UsingSelf `xml:"usingself"`
+	Using     *Using     `xml:"using"`
 	Combined  *Combined  `xml:"combined"`
 }
 
@@ -66,7 +112,7 @@ type ByGroups struct {
 	ByGroupsElements []ByGroupsElement `xml:",any"`
 }
 
-// ByGroups contains usingself and token elements intermixed, and the order matters.
+// ByGroups contains usingself, using and token elements intermixed, and the order matters.
 // We preserve the order by representing either of those elements by a ByGroupsElement
 type ByGroupsElement struct {
 	V interface{}
@@ -78,6 +124,8 @@ func (m *ByGroupsElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 		m.V = &Token{}
 	case "usingself":
 		m.V = &UsingSelf{}
+	case "using":
+		m.V = &Using{}
 	default:
 		return fmt.Errorf("unknown element: %s", start)
 	}
@@ -90,12 +138,34 @@ type UsingSelf struct {
 	State string `xml:"state,attr"`
 }
 
+// Using requests that the matched text be lexed by another lexer, looked up by name
+// in the registry the lexer belongs to.
+type Using struct {
+	Lexer string `xml:"lexer,attr"`
+}
+
+// DecodeLexer decodes a lexer definition. It fails if the definition is written for a newer version of the schema
+// than SchemaVersion, since the features of that version it uses would otherwise be ignored.
 func DecodeLexer(rdr io.Reader) (lex *Lexer, e error) {
 	dec := xml.NewDecoder(rdr)
-	e = dec.Decode(&lex)
+	if e = dec.Decode(&lex); e != nil {
+		return
+	}
+	e = lex.checkVersion()
 	return
 }
 
+func (l *Lexer) checkVersion() error {
+	switch {
+	case l.Version < 0:
+		return fmt.Errorf("lexer %q: invalid schema version %d", l.Config.Name, l.Version)
+	case l.Version > SchemaVersion:
+		return fmt.Errorf("lexer %q is written for version %d of the lexer definition schema, but this version of "+
+			"syn only supports versions up to %d", l.Config.Name, l.Version, SchemaVersion)
+	}
+	return nil
+}
+
 type Combined struct {
 	States []string `xml:"state,attr"`
 }
@@ -133,3 +133,23 @@ func TestXmlDecode(t *testing.T) {
 
 	assert.Equal(expected, lex.Rules)
 }
+
+func TestSchemaVersion(t *testing.T) {
+	tests := []struct {
+		lexer string
+		ok    bool
+	}{
+		{`<lexer>`, true},
+		{`<lexer version="1">`, true},
+		{`<lexer version="2">`, true},
+		{`<lexer version="3">`, false},
+		{`<lexer version="-1">`, false},
+		{`<lexer version="two">`, false},
+	}
+
+	for _, tc := range tests {
+		inp := tc.lexer + `<config><name>T</name></config><rules><state name="root"/></rules></lexer>`
+		_, decodeErr := DecodeLexer(bytes.NewBufferString(inp))
+		assert.Equal(t, tc.ok, decodeErr == nil, "%s: %v", tc.lexer, decodeErr)
+	}
+}
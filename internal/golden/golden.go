@@ -0,0 +1,139 @@
+// Package golden records and checks the golden files of the lexers: files of source text in lexers/testdata, each
+// with a dump of the tokens a lexer produces for it in a file with the suffix .tokens. The dumps are written by
+// syn record and checked by syn verify and by the tests of the lexers package.
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// Suffix is added to the name of a source file to get the name of the file holding its dump.
+const Suffix = ".tokens"
+
+const header = "lexer: "
+
+// Dump returns the canonical dump of tokens produced by the lexer called lexer. The first line names the lexer and
+// each following line holds a token's type and its quoted value.
+func Dump(lexer string, tokens []syn.Token) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s%s\n", header, lexer)
+	for _, tok := range tokens {
+		fmt.Fprintf(&b, "%s %s\n", tok.Type, strconv.Quote(string(tok.Value)))
+	}
+	return b.Bytes()
+}
+
+// Record returns the dump of the tokens produced for the source file at path by the lexer in reg called language,
+// or if language is empty, the lexer matching the file's name.
+func Record(reg *syn.LexerRegistry, path, language string) ([]byte, error) {
+	var lexer *syn.Lexer
+	if language != "" {
+		lexer = reg.Get(language)
+	} else {
+		lexer = reg.Match(path)
+	}
+	if lexer == nil {
+		return nil, fmt.Errorf("no lexer for %s", path)
+	}
+
+	source, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	tokens, lexErr := lexer.TokensAll([]rune(string(source)))
+	if lexErr != nil {
+		return nil, lexErr
+	}
+	return Dump(lexer.Config().Name, tokens), nil
+}
+
+// Verify checks that the dump at path, which ends in Suffix, matches the tokens produced now for its source file by
+// the lexer named in it.
+func Verify(reg *syn.LexerRegistry, path string) error {
+	want, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return readErr
+	}
+	language, languageErr := dumpLanguage(path, want)
+	if languageErr != nil {
+		return languageErr
+	}
+
+	got, recordErr := Record(reg, strings.TrimSuffix(path, Suffix), language)
+	if recordErr != nil {
+		return recordErr
+	}
+	if line, differ := firstDifference(want, got); differ {
+		return fmt.Errorf("%s:%d: the tokens differ: %s", path, line.number, line)
+	}
+	return nil
+}
+
+// Language returns the name of the lexer that the dump at path was recorded with.
+func Language(path string) (string, error) {
+	dump, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return "", readErr
+	}
+	return dumpLanguage(path, dump)
+}
+
+func dumpLanguage(path string, dump []byte) (string, error) {
+	first, _, _ := bytes.Cut(dump, []byte("\n"))
+	language, ok := strings.CutPrefix(string(first), header)
+	if !ok {
+		return "", fmt.Errorf("%s: the first line doesn't name the lexer", path)
+	}
+	return language, nil
+}
+
+// difference describes the first line at which two dumps differ.
+type difference struct {
+	number    int
+	want, got string
+}
+
+func (d difference) String() string {
+	return fmt.Sprintf("want %s, got %s", d.want, d.got)
+}
+
+func firstDifference(want, got []byte) (difference, bool) {
+	ws := strings.Split(strings.TrimSuffix(string(want), "\n"), "\n")
+	gs := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+	for i := 0; i < max(len(ws), len(gs)); i++ {
+		d := difference{number: i + 1, want: "end of tokens", got: "end of tokens"}
+		if i < len(ws) {
+			d.want = ws[i]
+		}
+		if i < len(gs) {
+			d.got = gs[i]
+		}
+		if d.want != d.got {
+			return d, true
+		}
+	}
+	return difference{}, false
+}
+
+// Fixtures returns the paths of the dumps in dir and its subdirectories.
+func Fixtures(dir string) ([]string, error) {
+	var paths []string
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, entryErr error) error {
+		if entryErr != nil {
+			return entryErr
+		}
+		if !d.IsDir() && strings.HasSuffix(path, Suffix) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, walkErr
+}
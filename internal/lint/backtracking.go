@@ -0,0 +1,360 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/dlclark/regexp2"
+)
+
+// nestedQuantifiers returns the groups in pattern that are repeated by an unbounded quantifier (*, + or {n,})
+// and that contain an unbounded quantifier themselves, such as (a+)* and (?:\s*\w+)+. These can take a time
+// exponential in the length of the text to fail to match. Atomic groups and lookarounds are not reported, and
+// nor are groups whose repetitions can only be told apart one way, such as (?:,\s*\w+)*, where each
+// repetition starts with a character that the repeated atoms inside the group can't match. The patterns are
+// compiled with opts to decide which characters an atom matches.
+func nestedQuantifiers(pattern string, opts regexp2.RegexOptions) []string {
+	type group struct {
+		start     int
+		atomic    bool
+		unbounded bool
+	}
+
+	var found []string
+	stack := []group{{start: -1}}
+	for i := 0; i < len(pattern); i++ {
+		atomEnd := i + 1
+		switch c := pattern[i]; c {
+		case '\\':
+			atomEnd = escapeEnd(pattern, i)
+		case '[':
+			atomEnd = classEnd(pattern, i)
+		case '(':
+			atomic := false
+			for _, p := range []string{"(?>", "(?=", "(?!", "(?<=", "(?<!"} {
+				if strings.HasPrefix(pattern[i:], p) {
+					atomic = true
+				}
+			}
+			stack = append(stack, group{start: i, atomic: atomic})
+			continue
+		case ')':
+			if len(stack) == 1 {
+				continue
+			}
+			g := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			unbounded, end := quantifier(pattern, i+1)
+			if unbounded && g.unbounded && !g.atomic && ambiguousRepeat(pattern[g.start:i+1], opts) {
+				found = append(found, pattern[g.start:i+1])
+			}
+			if unbounded || (g.unbounded && !g.atomic) {
+				stack[len(stack)-1].unbounded = true
+			}
+			i = end - 1
+			continue
+		}
+
+		unbounded, end := quantifier(pattern, atomEnd)
+		if unbounded {
+			stack[len(stack)-1].unbounded = true
+		}
+		i = end - 1
+	}
+	return found
+}
+
+// escapeEnd returns the index after the escape sequence starting at i.
+func escapeEnd(pattern string, i int) int {
+	end := i + 2
+	if end > len(pattern) {
+		return len(pattern)
+	}
+	if (pattern[i+1] == 'p' || pattern[i+1] == 'P') && end < len(pattern) && pattern[end] == '{' {
+		if j := strings.IndexByte(pattern[end:], '}'); j >= 0 {
+			return end + j + 1
+		}
+	}
+	return end
+}
+
+// classEnd returns the index after the character class starting at i.
+func classEnd(pattern string, i int) int {
+	j := i + 1
+	if j < len(pattern) && pattern[j] == '^' {
+		j++
+	}
+	if j < len(pattern) && pattern[j] == ']' {
+		j++
+	}
+	for j < len(pattern) {
+		switch pattern[j] {
+		case '\\':
+			j += 2
+			continue
+		case '[':
+			if strings.HasPrefix(pattern[j:], "[:") {
+				if k := strings.Index(pattern[j:], ":]"); k >= 0 {
+					j += k + 2
+					continue
+				}
+			}
+		case ']':
+			return j + 1
+		}
+		j++
+	}
+	return len(pattern)
+}
+
+// quantifier returns whether the quantifier at i, if any, is unbounded, and the index after it.
+func quantifier(pattern string, i int) (unbounded bool, end int) {
+	if i >= len(pattern) {
+		return false, i
+	}
+	end = i + 1
+	switch pattern[i] {
+	case '*', '+':
+		unbounded = true
+	case '?':
+	case '{':
+		j := strings.IndexByte(pattern[i:], '}')
+		if j < 0 {
+			return false, i
+		}
+		body := pattern[i+1 : i+j]
+		if strings.Trim(body, "0123456789,") != "" || body == "" || body[0] == ',' {
+			return false, i
+		}
+		unbounded = strings.HasSuffix(body, ",")
+		end = i + j + 1
+	default:
+		return false, i
+	}
+	if end < len(pattern) && pattern[end] == '?' {
+		end++
+	}
+	return unbounded, end
+}
+
+// quantifierOptional returns whether the quantifier at i, if any, lets the atom before it match no times.
+func quantifierOptional(pattern string, i int) bool {
+	if i >= len(pattern) {
+		return false
+	}
+	switch pattern[i] {
+	case '*', '?':
+		return true
+	case '{':
+		if _, end := quantifier(pattern, i); end == i {
+			return false
+		}
+		body := pattern[i+1:]
+		return strings.HasPrefix(body, "0}") || strings.HasPrefix(body, "0,")
+	}
+	return false
+}
+
+// item is an atom or a group of a pattern, as parsed by parseAlternatives.
+type item struct {
+	// src is the text of the atom, for an item that isn't a group.
+	src string
+	// zeroWidth is set for anchors, lookarounds and inline options, which match no characters.
+	zeroWidth bool
+	// opaque is set for back references, whose characters can't be known.
+	opaque bool
+	group  bool
+	// alts are the alternatives of a group, each a sequence of items.
+	alts      [][]item
+	optional  bool
+	unbounded bool
+}
+
+// parseAlternatives parses pattern from i up to the closing parenthesis of the group it is in, or the end of
+// pattern, and returns the alternatives found, each a sequence of items, and the index after them.
+func parseAlternatives(pattern string, i int) (alts [][]item, end int) {
+	var items []item
+	for i < len(pattern) {
+		var it item
+		atomEnd := i + 1
+		switch c := pattern[i]; c {
+		case ')':
+			return append(alts, items), i
+		case '|':
+			alts = append(alts, items)
+			items = nil
+			i++
+			continue
+		case '^', '$':
+			it.zeroWidth = true
+		case '\\':
+			atomEnd = escapeEnd(pattern, i)
+			if atomEnd > i+1 {
+				switch e := pattern[i+1]; {
+				case strings.IndexByte("bBAzZG", e) >= 0:
+					it.zeroWidth = true
+				case e >= '1' && e <= '9', e == 'k':
+					it.opaque = true
+				}
+			}
+		case '[':
+			atomEnd = classEnd(pattern, i)
+		case '(':
+			start := i + 1
+			switch {
+			case strings.HasPrefix(pattern[i:], "(?="), strings.HasPrefix(pattern[i:], "(?!"),
+				strings.HasPrefix(pattern[i:], "(?<="), strings.HasPrefix(pattern[i:], "(?<!"):
+				it.zeroWidth = true
+			case strings.HasPrefix(pattern[i:], "(?<"), strings.HasPrefix(pattern[i:], "(?P<"):
+				start = i + strings.IndexByte(pattern[i:], '>') + 1
+			case strings.HasPrefix(pattern[i:], "(?'"):
+				start = i + 3 + strings.IndexByte(pattern[i+3:], '\'') + 1
+			case strings.HasPrefix(pattern[i:], "(?"):
+				// Non-capturing and atomic groups, and inline options with or without a group.
+				start = i + 2
+				for start < len(pattern) && strings.IndexByte("imnsx-", pattern[start]) >= 0 {
+					start++
+				}
+				if start < len(pattern) && (pattern[start] == ':' || pattern[start] == '>') {
+					start++
+				}
+			}
+			it.group = true
+			it.alts, atomEnd = parseAlternatives(pattern, start)
+			if atomEnd < len(pattern) {
+				atomEnd++
+			}
+		}
+		if !it.group {
+			it.src = pattern[i:atomEnd]
+		}
+		it.optional = quantifierOptional(pattern, atomEnd)
+		it.unbounded, i = quantifier(pattern, atomEnd)
+		items = append(items, it)
+	}
+	return append(alts, items), i
+}
+
+// mandatory returns whether it always matches at least one character.
+func (it item) mandatory() bool {
+	if it.zeroWidth || it.optional {
+		return false
+	}
+	if !it.group {
+		return true
+	}
+	for _, alt := range it.alts {
+		if !mandatorySeq(alt) {
+			return false
+		}
+	}
+	return true
+}
+
+// mandatorySeq returns whether the sequence of items always matches at least one character.
+func mandatorySeq(items []item) bool {
+	for _, it := range items {
+		if it.mandatory() {
+			return true
+		}
+	}
+	return false
+}
+
+// atoms appends the atoms of it that can consume characters to list, and returns whether any of them is opaque.
+// If onlyRepeated is set only the atoms under an unbounded quantifier are appended.
+func (it item) atoms(list *[]string, onlyRepeated bool) (opaque bool) {
+	if it.zeroWidth {
+		return false
+	}
+	onlyRepeated = onlyRepeated && !it.unbounded
+	if !it.group {
+		if !onlyRepeated {
+			*list = append(*list, it.src)
+		}
+		return it.opaque && !onlyRepeated
+	}
+	for _, alt := range it.alts {
+		for _, sub := range alt {
+			if sub.atoms(list, onlyRepeated) {
+				opaque = true
+			}
+		}
+	}
+	return opaque
+}
+
+// sampleRunes are the characters that atoms are matched against to decide whether two atoms can match the same
+// character.
+var sampleRunes = func() []rune {
+	runes := []rune("\u00a0é߀λЖ中\u2028😀")
+	for r := rune(0); r < 128; r++ {
+		runes = append(runes, r)
+	}
+	return runes
+}()
+
+// ambiguousRepeat returns whether the repetitions of group, a quantified group containing an unbounded
+// quantifier, may match the same text in more than one way. This is assumed unless each alternative of group
+// always matches a character, and none of the characters that the alternatives start with can be matched by
+// the repeated atoms inside group, so that the start of each repetition is unambiguous.
+func ambiguousRepeat(group string, opts regexp2.RegexOptions) bool {
+	alts, _ := parseAlternatives(group, 0)
+	if len(alts) != 1 || len(alts[0]) != 1 || !alts[0][0].group {
+		return true
+	}
+	body := alts[0][0]
+
+	var leading, repeated []string
+	for _, alt := range body.alts {
+		if !mandatorySeq(alt) {
+			return true
+		}
+		for _, it := range alt {
+			if it.atoms(&leading, false) {
+				return true
+			}
+			if it.mandatory() {
+				break
+			}
+		}
+		for _, it := range alt {
+			if it.atoms(&repeated, true) {
+				return true
+			}
+		}
+	}
+	return overlap(leading, repeated, opts)
+}
+
+// overlap returns whether an atom of a and an atom of b both match one of sampleRunes.
+func overlap(a, b []string, opts regexp2.RegexOptions) bool {
+	opts &= regexp2.IgnoreCase | regexp2.Singleline
+	var ra, rb []*regexp2.Regexp
+	for _, m := range []struct {
+		atoms []string
+		res   *[]*regexp2.Regexp
+	}{{a, &ra}, {b, &rb}} {
+		for _, atom := range m.atoms {
+			re, compileErr := regexp2.Compile(`\A(?:`+atom+`)\z`, opts)
+			if compileErr != nil {
+				return true
+			}
+			*m.res = append(*m.res, re)
+		}
+	}
+	matchesAny := func(res []*regexp2.Regexp, s string) bool {
+		for _, re := range res {
+			// Patterns have no match timeout, so matching can't fail.
+			if ok, _ := re.MatchString(s); ok {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range sampleRunes {
+		if s := string(r); matchesAny(ra, s) && matchesAny(rb, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,300 @@
+// Package lint checks XML lexer definitions for mistakes that the lexer builder doesn't report
+// or only reports when the lexer is first used.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dlclark/regexp2"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+// Kind is the kind of a Problem.
+type Kind int
+
+const (
+	// InvalidPattern is a pattern that doesn't compile.
+	InvalidPattern Kind = iota
+	// Backtracking is a pattern with nested unbounded quantifiers, such as (a+)*, which can take time
+	// exponential in the length of the text to fail to match.
+	Backtracking
+	// Shadowed is a rule that can never match because an earlier rule in the same state always matches
+	// instead.
+	Shadowed
+	// UnusedState is a state that can't be reached from the root state.
+	UnusedState
+)
+
+var kindNames = []string{
+	InvalidPattern: "invalid",
+	Backtracking:   "backtracking",
+	Shadowed:       "shadowed",
+	UnusedState:    "unused",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// ParseKind returns the Kind whose String is name.
+func ParseKind(name string) (Kind, bool) {
+	for k, n := range kindNames {
+		if n == name {
+			return Kind(k), true
+		}
+	}
+	return 0, false
+}
+
+// Problem is a problem found in a lexer definition.
+type Problem struct {
+	Kind Kind
+	// State is the name of the state the problem is in, or empty for a problem with a pattern of the analyse
+	// element, which Rule is the index of.
+	State string
+	// Rule is the index of the rule in the state, or -1 if the problem is with the state as a whole.
+	Rule    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.State == "" {
+		return fmt.Sprintf("analyse pattern %d: %s: %s", p.Rule, p.Kind, p.Message)
+	}
+	if p.Rule < 0 {
+		return fmt.Sprintf("state %q: %s: %s", p.State, p.Kind, p.Message)
+	}
+	return fmt.Sprintf("state %q rule %d: %s: %s", p.State, p.Rule, p.Kind, p.Message)
+}
+
+// Lint returns the problems found in the lexer definition lex.
+func Lint(lex *config.Lexer) []Problem {
+	l := linter{lex: lex, opts: regexpOptions(lex.Config), states: map[string]*config.State{}}
+	for i := range lex.Rules.States {
+		l.states[lex.Rules.States[i].Name] = &lex.Rules.States[i]
+	}
+
+	for _, state := range lex.Rules.States {
+		for i, rule := range state.Rules {
+			l.checkPattern(state.Name, i, rule.Pattern)
+		}
+	}
+	if lex.Config.Analyse != nil {
+		for i, r := range lex.Config.Analyse.Regexes {
+			if _, compileErr := regexp2.Compile(r.Pattern, regexp2.None); compileErr != nil {
+				l.report(InvalidPattern, "", i, "%v", compileErr)
+			}
+		}
+	}
+	for _, state := range lex.Rules.States {
+		l.checkShadowing(state)
+	}
+	l.checkStates()
+	return l.problems
+}
+
+type linter struct {
+	lex      *config.Lexer
+	opts     regexp2.RegexOptions
+	states   map[string]*config.State
+	problems []Problem
+}
+
+func (l *linter) report(kind Kind, state string, rule int, format string, args ...any) {
+	l.problems = append(l.problems, Problem{Kind: kind, State: state, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// regexpOptions returns the options that the lexer builder compiles patterns with.
+func regexpOptions(c config.Config) regexp2.RegexOptions {
+	var opts regexp2.RegexOptions
+	if !c.NotMultiline {
+		opts |= regexp2.Multiline
+	}
+	if c.CaseInsensitive {
+		opts |= regexp2.IgnoreCase
+	}
+	if c.DotAll {
+		opts |= regexp2.Singleline
+	}
+	return opts
+}
+
+func (l *linter) compile(pattern string) (*regexp2.Regexp, error) {
+	return regexp2.Compile(`\A`+pattern, l.opts)
+}
+
+func (l *linter) checkPattern(state string, rule int, pattern string) {
+	if pattern == "" {
+		return
+	}
+	if _, compileErr := l.compile(pattern); compileErr != nil {
+		l.report(InvalidPattern, state, rule, "%v", compileErr)
+		return
+	}
+	for _, group := range nestedQuantifiers(pattern, l.opts) {
+		l.report(Backtracking, state, rule, "the repeated group %s contains an unbounded quantifier", group)
+	}
+}
+
+// flatRule is a rule of a state after the includes are expanded.
+type flatRule struct {
+	config.Rule
+	// from is the state that the rule is defined in.
+	from string
+	// index is the index of the rule in the state it is defined in.
+	index int
+}
+
+// flatten returns the rules of the state with the rules of the included states in place of the includes.
+func (l *linter) flatten(name string, seen map[string]bool) []flatRule {
+	state := l.states[name]
+	if state == nil || seen[name] {
+		return nil
+	}
+	seen[name] = true
+	defer delete(seen, name)
+
+	var rules []flatRule
+	for i, rule := range state.Rules {
+		if rule.Include != nil {
+			rules = append(rules, l.flatten(rule.Include.State, seen)...)
+			continue
+		}
+		rules = append(rules, flatRule{rule, name, i})
+	}
+	return rules
+}
+
+// checkShadowing reports the rules defined in state that can't match because an earlier rule of the state always
+// matches first. A rule is reported if its pattern is the same as an earlier one, or if it only matches a literal
+// string and an earlier rule matches the start of that string without depending on the text that follows it.
+func (l *linter) checkShadowing(state config.State) {
+	rules := l.flatten(state.Name, map[string]bool{})
+	for j, later := range rules {
+		if later.from != state.Name || later.Pattern == "" {
+			continue
+		}
+		lit, isLit := literal(later.Pattern)
+		for _, earlier := range rules[:j] {
+			if earlier.Pattern == "" {
+				continue
+			}
+			if earlier.Pattern == later.Pattern {
+				l.report(Shadowed, state.Name, later.index, "the pattern %q is the same as that of %s", later.Pattern, describe(earlier, state.Name))
+				break
+			}
+			if !isLit || lit == "" || dependsOnContext(earlier.Pattern) {
+				continue
+			}
+			re, compileErr := l.compile(earlier.Pattern)
+			if compileErr != nil {
+				continue
+			}
+			// Patterns have no match timeout, so matching can't fail.
+			if m, _ := re.FindStringMatch(lit); m != nil && m.Length > 0 {
+				l.report(Shadowed, state.Name, later.index, "%q is always matched by %s, %q", lit, describe(earlier, state.Name), earlier.Pattern)
+				break
+			}
+		}
+	}
+}
+
+func describe(r flatRule, state string) string {
+	if r.from == state {
+		return fmt.Sprintf("rule %d", r.index)
+	}
+	return fmt.Sprintf("rule %d of the included state %q", r.index, r.from)
+}
+
+// dependsOnContext returns whether the pattern contains assertions about the text around the match, so that
+// whether it matches the start of a string doesn't show that it matches wherever the string occurs.
+func dependsOnContext(pattern string) bool {
+	for _, s := range []string{"(?=", "(?!", "(?<=", "(?<!", "$", `\b`, `\B`, `\Z`, `\z`} {
+		if strings.Contains(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// literal returns the string that pattern matches if it only matches one string.
+func literal(pattern string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			switch e := pattern[i]; {
+			case e == 'n':
+				b.WriteByte('\n')
+			case e == 't':
+				b.WriteByte('\t')
+			case strings.IndexByte(`\.^$|?*+()[]{}/#-'"&<>:;,!=@%~`+"`", e) >= 0:
+				b.WriteByte(e)
+			default:
+				return "", false
+			}
+		case strings.IndexByte(`.^$|?*+()[]{}\`, c) >= 0:
+			return "", false
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), true
+}
+
+// checkStates reports the states that can't be reached from the root state.
+func (l *linter) checkStates() {
+	reached := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		state := l.states[name]
+		if state == nil || reached[name] {
+			return
+		}
+		reached[name] = true
+		for _, rule := range state.Rules {
+			for _, ref := range references(rule) {
+				visit(ref)
+			}
+		}
+	}
+	visit("root")
+
+	for _, state := range l.lex.Rules.States {
+		if !reached[state.Name] {
+			l.report(UnusedState, state.Name, -1, "the state isn't pushed or included by any state reachable from root")
+		}
+	}
+}
+
+// references returns the names of the states that rule refers to.
+func references(rule config.Rule) []string {
+	var refs []string
+	if rule.Include != nil {
+		refs = append(refs, rule.Include.State)
+	}
+	if rule.Push != nil && rule.Push.State != "" && !strings.HasPrefix(rule.Push.State, "#") {
+		refs = append(refs, rule.Push.State)
+	}
+	if rule.UsingSelf != nil {
+		refs = append(refs, rule.UsingSelf.State)
+	}
+	if rule.Combined != nil {
+		refs = append(refs, rule.Combined.States...)
+	}
+	if rule.ByGroups != nil {
+		for _, e := range rule.ByGroups.ByGroupsElements {
+			if u, ok := e.V.(*config.UsingSelf); ok {
+				refs = append(refs, u.State)
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,131 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/dlclark/regexp2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+func TestLint(t *testing.T) {
+	inp := `
+<lexer>
+  <config>
+    <name>Test</name>
+  </config>
+  <rules>
+    <state name="root">
+      <rule>
+        <include state="whitespace"/>
+      </rule>
+      <rule pattern="\n">
+        <token type="Text"/>
+      </rule>
+      <rule pattern="(\d+\s*)+">
+        <token type="Name"/>
+      </rule>
+      <rule pattern="[a-z">
+        <token type="Name"/>
+      </rule>
+      <rule pattern="&#34;">
+        <token type="LiteralString"/>
+        <push state="string"/>
+      </rule>
+      <rule pattern="(if)\b">
+        <token type="Keyword"/>
+      </rule>
+      <rule pattern="if">
+        <token type="Name"/>
+      </rule>
+    </state>
+    <state name="whitespace">
+      <rule pattern="\s+">
+        <token type="Text"/>
+      </rule>
+    </state>
+    <state name="string">
+      <rule pattern="(?:[^&#34;\\]|\\.)*">
+        <token type="LiteralString"/>
+      </rule>
+      <rule pattern="&#34;">
+        <token type="LiteralString"/>
+        <pop depth="1"/>
+      </rule>
+      <rule pattern="&#34;">
+        <token type="LiteralString"/>
+        <pop depth="1"/>
+      </rule>
+    </state>
+    <state name="unused">
+      <rule pattern="x">
+        <token type="Name"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>
+`
+	lex := mylog.Check2(config.DecodeLexer(strings.NewReader(inp)))
+
+	var got []string
+	for _, p := range Lint(lex) {
+		got = append(got, p.String())
+	}
+	expected := []string{
+		`state "root" rule 2: backtracking: the repeated group (\d+\s*) contains an unbounded quantifier`,
+		`state "root" rule 3: invalid: error parsing regexp: unterminated [] set in ` + "`\\A[a-z`",
+		`state "root" rule 1: shadowed: "\n" is always matched by rule 0 of the included state "whitespace", "\\s+"`,
+		`state "string" rule 2: shadowed: the pattern "\"" is the same as that of rule 1`,
+		`state "unused": unused: the state isn't pushed or included by any state reachable from root`,
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestNestedQuantifiers(t *testing.T) {
+	tests := map[string][]string{
+		`(a+)*`:            {`(a+)`},
+		`(?:\s*\w+)+b`:     {`(?:\s*\w+)`},
+		`((a*)+)+`:         {`(a*)`, `((a*)+)`},
+		`(?:[^"\\]|\\.)*`:  nil,
+		`(?>a+)*`:          nil,
+		`(a+)?`:            nil,
+		`(a{2,})+`:         {`(a{2,})`},
+		`(a{2,3})+`:        nil,
+		`[(a+)*]`:          nil,
+		`\(a+\)*`:          nil,
+		`\p{L}+(?:\p{L})*`: nil,
+		`(a+)*?`:           {`(a+)`},
+		`(?=(a+)*)`:        {`(a+)`},
+		`(?:(?=a+)b)+`:     nil,
+		// Each repetition starts with a character the repeated atoms can't match.
+		`(?:::[a-zA-Z_]\w*)*`:   nil,
+		`(\.[$a-zA-Z_][\w$]*)*`: nil,
+		`(?:,\s*\w+)*`:          nil,
+		`(?:\b-\w+)+`:           nil,
+		`(?<n>;[^;]+)*`:         nil,
+		// The first character can be matched by a repeated atom, the group can match empty, or it has
+		// alternatives or a back reference.
+		`(?:\w+\.)*`:     {`(?:\w+\.)`},
+		`(?:x?\w+)*`:     {`(?:x?\w+)`},
+		`(?:a*b*)+`:      {`(?:a*b*)`},
+		`(?:b+|c)*`:      {`(?:b+|c)`},
+		`(?:(x)\1+)*`:    {`(?:(x)\1+)`},
+		`(?:"[^"]*")+`:   nil,
+		`(?:\.[^.]*)+`:   nil,
+		`(?:[ab][^a]*)+`: {`(?:[ab][^a]*)`},
+		// Every alternative has to be unambiguous.
+		`(?:\\[0-7]+|\\.|x)*`: nil,
+		`(?:\\.|[^"\\]+)*`:    {`(?:\\.|[^"\\]+)`},
+		`(?:a+|b?)+`:          {`(?:a+|b?)`},
+	}
+	for pattern, expected := range tests {
+		assert.Equal(t, expected, nestedQuantifiers(pattern, regexp2.None), pattern)
+	}
+
+	// The characters an atom matches depend on the options.
+	assert.Nil(t, nestedQuantifiers(`(?:A[a-z]*)*`, regexp2.None))
+	assert.Equal(t, []string{`(?:A[a-z]*)`}, nestedQuantifiers(`(?:A[a-z]*)*`, regexp2.IgnoreCase))
+}
@@ -4,11 +4,29 @@ import (
 	"bytes"
 	"fmt"
 
-	"github.com/ddkwork/golibrary/mylog"
 	"github.com/dlclark/regexp2"
 )
 
 type Iterator interface {
+	Next() (Token, error)
+	// Peek returns the token n positions after the next token without consuming any tokens. Peek(0)
+	// returns the token that the next call to Next will return.
+	Peek(n int) (Token, error)
+	// Rewind moves the iterator back by n tokens so that they are returned by Next again. At most
+	// MaxRewind tokens can be rewound.
+	Rewind(n int) error
+	// Diagnostics returns the non-fatal problems found so far while lexing, in the order they were found.
+	Diagnostics() []Diagnostic
+	// Collect calls Next until the end of the input is reached and returns the tokens produced, not
+	// including the final EOFType token. If Next returns an error Collect stops and returns the tokens
+	// produced so far along with the error.
+	Collect() ([]Token, error)
+	State() IteratorState
+	SetState(state IteratorState)
+}
+
+// tokenSource is implemented by each of the stages that an Iterator is built from.
+type tokenSource interface {
 	Next() (Token, error)
 	State() IteratorState
 	SetState(state IteratorState)
@@ -34,12 +52,56 @@ type iterator struct {
 	sublexers []*iterator
 	rules     rules
 	depth     int
+	// registry is used to look up the lexers named by using rules. It may be nil.
+	registry *LexerRegistry
+	opts     iteratorOptions
+	// diags collects the problems found while lexing. It is shared with the sublexers. It may be nil.
+	diags *diagnostics
+	// stackReset is set when the state stack grew too deep and was reset to the root state.
+	// The next token returned is changed to an Error token, and recorded in diags along with the
+	// state and rule that caused the reset.
+	stackReset            bool
+	resetState, resetRule string
+}
+
+// DefaultMaxStackDepth is the default maximum depth of the state stack; see WithMaxStackDepth.
+const DefaultMaxStackDepth = 1000
+
+// Recovery selects how an iterator recovers when no rule of the current state matches the input.
+type Recovery int
+
+const (
+	// RecoverSkipRune returns the unmatched rune as an Error token and continues in the same state.
+	// If the next rune is a newline the stack is reset to the root state. This is the default, and is
+	// how Chroma and Pygments recover.
+	RecoverSkipRune Recovery = iota
+	// RecoverSkipLine returns the rest of the line, not including the newline, as an Error token and
+	// resets the stack to the root state.
+	RecoverSkipLine
+	// RecoverPopToRoot returns the unmatched rune as an Error token and resets the stack to the root state.
+	RecoverPopToRoot
+)
+
+// iteratorOptions are the runtime options of a Lexer that control how its iterators lex.
+type iteratorOptions struct {
+	// maxStackDepth is the maximum number of states on the stack. Zero means no limit.
+	maxStackDepth int
+	recovery      Recovery
+	// eolTokens is set if line endings are returned as separate TextEOL tokens.
+	eolTokens bool
+	// offsetsOnly is set if the Value of the tokens returned is left nil.
+	offsetsOnly bool
+	filters     []Filter
+	// metrics, if not nil, is reported to in place of the Metrics of the registry.
+	metrics Metrics
+	// coverage, if not nil, records the rules that match.
+	coverage *Coverage
 }
 
 func newIterator(text []rune, rulez rules) *iterator {
 	iter := &iterator{
 		text:  text,
-		state: lexerState{stack: newStack()},
+		state: lexerState{stack: newStack(), rules: rulez},
 		rules: rulez,
 	}
 
@@ -97,7 +159,22 @@ func (i *iterator) pushState(state string) error {
 // Next may return a token with type Error but not set error. In this case something went wrong tokenizing
 // but Next will attempt to reset state and keep tokenizing in an attempt to provide _something_ useful for
 // the rest of the input. Callers can decide whether to continue or not in this case.
-func (i *iterator) Next() (Token, error) {
+func (i *iterator) Next() (tok Token, err error) {
+	tok, nextErr := i.next()
+	if nextErr != nil {
+		return tok, nextErr
+	}
+	if i.stackReset {
+		i.stackReset = false
+		if tok.Type != EOFType {
+			tok.Type = Error
+			i.diags.add(DiagnosticStackTooDeep, tok.Start, tok.Length(), i.resetState, i.resetRule)
+		}
+	}
+	return
+}
+
+func (i *iterator) next() (Token, error) {
 	i.pushRootStateIfNeeded()
 
 	switch i.state.stage {
@@ -121,25 +198,15 @@ func (i *iterator) nextInReadyToMatchStage() (tok Token, err error) {
 
 	state := i.state.stack.Top()
 	debugf("iterator.nextInReadyToMatchStage(%d): Matching a full rule in top state %s", i.depth, state.name)
-	match, rule := state.match(i.text[i.state.index:])
+	match, rule, matchErr := state.match(i.text[i.state.index:])
+	if matchErr != nil {
+		i.diags.add(DiagnosticTimeout, i.state.offset+i.state.index, 0, state.name, rule.patternString())
+	}
 	if match == nil {
 		debugf("iterator.nextInReadyToMatchStage(%d): No rule in the rule sequence matched", i.depth)
-		i.state.index++
-		if i.state.index < len(i.text) && i.text[i.state.index] == '\n' {
-			// This idea is taken from Chroma, which also took it from Pygments. To quote:
-			//
-			// "If the RegexLexer encounters a newline that is flagged as an error token, the stack is
-			// emptied and the lexer continues scanning in the 'root' state. This can help producing
-			// error-tolerant highlighting for erroneous input, e.g. when a single-line string is not
-			// closed."
-			//
-			// Basically we keep making progress character by character and try to reset.
-			// TODO: This could be slow for large files; perhaps this should be an option.
-			i.state.stack.Clear()
-			i.pushRootStateIfNeeded()
-		}
-		return Token{Type: Error, Value: nil, Start: i.state.index - 1, End: i.state.index}, nil
+		return i.recoverFromUnmatched(state), nil
 	}
+	i.opts.coverage.hit(rule.origin)
 
 	if rule.byGroups != nil {
 		i.prepareToIterateGroups(rule, match)
@@ -148,25 +215,39 @@ func (i *iterator) nextInReadyToMatchStage() (tok Token, err error) {
 
 	if rule.IsUseSelf() {
 		groupText := i.groupText(match.GroupByNumber(0))
-		i.prepareToUseSublexer(rule, groupText, 0, rule.useSelfState)
+		i.setCapturesFromMatch(match)
+		i.prepareToUseSublexer(rule, groupText, 0, i.rules, rule.useSelfState)
 		return i.Next()
 	}
 
-	if rule.tok == 0 {
+	typ := rule.tok
+	if rule.IsUsing() {
+		groupText := i.groupText(match.GroupByNumber(0))
+		if rulez, ok := i.lookupUsing(rule.usingLexer); ok {
+			i.setCapturesFromMatch(match)
+			i.prepareToUseSublexer(rule, groupText, 0, rulez, "root")
+			return i.Next()
+		}
+		typ = Text
+	}
+
+	if typ == 0 {
 		debugf("iterator.nextInReadyToMatchStage(%d): rule provides no token\n", i.depth)
 		tok = Token{}
 	} else {
 		debugf("iterator.nextInReadyToMatchStage(%d): will return token for entire match\n", i.depth)
 		// Use entire match
-		tok = i.tokenOfEntireMatch(rule.tok, match)
+		tok = i.tokenOfEntireMatch(typ, match)
 		g := match.GroupByNumber(0)
 		debugf("iterator.nextInReadyToMatchStage(%d): Moving index from %d to %d (some text there is: '%s')", i.depth, i.state.index, i.state.index+g.Length,
 			aLittleText(i.text, i.state.index+g.Length))
 		i.state.index += g.Length
 	}
-	mylog.Check(i.handleRuleState(rule))
+	if stateErr := i.handleRuleState(rule); stateErr != nil {
+		return Token{}, stateErr
+	}
 
-	if rule.tok == 0 {
+	if typ == 0 {
 		debugf("iterator.nextInReadyToMatchStage(%d): recursing to generate token\n", i.depth)
 		return i.Next()
 	}
@@ -175,6 +256,44 @@ func (i *iterator) nextInReadyToMatchStage() (tok Token, err error) {
 	return
 }
 
+// recoverFromUnmatched skips over input that no rule of the state matched, returning it as an Error
+// token, and adjusts the stack as selected by the Recovery option.
+func (i *iterator) recoverFromUnmatched(state state) Token {
+	start := i.state.index
+	end := start + 1
+	if i.opts.recovery == RecoverSkipLine {
+		for end < len(i.text) && i.text[end-1] != '\n' && i.text[end] != '\n' {
+			end++
+		}
+	}
+	i.diags.add(DiagnosticUnmatched, i.state.offset+start, end-start, state.name, "")
+	i.state.index = end
+
+	reset := false
+	switch i.opts.recovery {
+	case RecoverSkipRune:
+		// This idea is taken from Chroma, which also took it from Pygments. To quote:
+		//
+		// "If the RegexLexer encounters a newline that is flagged as an error token, the stack is
+		// emptied and the lexer continues scanning in the 'root' state. This can help producing
+		// error-tolerant highlighting for erroneous input, e.g. when a single-line string is not
+		// closed."
+		//
+		// Basically we keep making progress character by character and try to reset.
+		reset = i.state.index < len(i.text) && i.text[i.state.index] == '\n'
+	case RecoverSkipLine, RecoverPopToRoot:
+		reset = true
+	}
+
+	if reset {
+		i.state.stack.Clear()
+		i.pushRootStateIfNeeded()
+		i.diags.add(DiagnosticStackReset, i.state.offset+i.state.index, 0, state.name, "")
+	}
+
+	return Token{Type: Error, Value: i.text[start:end], Start: i.state.offset + start, End: i.state.offset + end}
+}
+
 func (i *iterator) prepareToIterateGroups(matchingRule *rule, match *regexp2.Match) {
 	i.state.rule = matchingRule
 	i.setCapturesFromMatch(match)
@@ -202,19 +321,31 @@ func (it *iterator) nextInWithinGroupsStage() (tok Token, err error) {
 	groupText := text[capture.start:capture.end()]
 	if byGroup.IsUseSelf() {
 		debugf("Lexer.nextInWithinGroupsStage(%d): bygroups %d is a use-self. Creating sub lexer\n", it.depth, it.state.groupIndex)
-		it.prepareToUseSublexer(it.state.rule, groupText, capture.start, byGroup.useSelfState)
+		it.prepareToUseSublexer(it.state.rule, groupText, capture.start, it.rules, byGroup.useSelfState)
 		return it.Next()
 	}
 
+	typ := byGroup.tok
+	if byGroup.IsUsing() {
+		if rulez, ok := it.lookupUsing(byGroup.usingLexer); ok {
+			debugf("Lexer.nextInWithinGroupsStage(%d): bygroups %d is a using. Creating sub lexer\n", it.depth, it.state.groupIndex)
+			it.prepareToUseSublexer(it.state.rule, groupText, capture.start, rulez, "root")
+			return it.Next()
+		}
+		typ = Text
+	}
+
 	start, end := it.boundsOfGroup(capture.start, capture.length)
 	debugf("iterator.nextInWithinGroupsStage(%d): bygroups %d: returning token\n", it.depth, it.state.groupIndex)
-	tok = Token{Type: byGroup.tok, Value: groupText, Start: start, End: end}
+	tok = Token{Type: typ, Value: groupText, Start: start, End: end}
 
 	it.state.groupIndex++
 
 	if it.state.groupIndex >= len(it.state.byGroups) {
 		debugf("iterator.nextInWithinGroupsStage(%d): reached end of the groups, will switch to full match stage\n", it.depth)
-		mylog.Check(it.handleRuleState(it.state.rule))
+		if stateErr := it.handleRuleState(it.state.rule); stateErr != nil {
+			return Token{}, stateErr
+		}
 
 		it.state.stage = stageReadyToMatch
 		it.state.index += it.state.groups[0].length // Move past the length of the match
@@ -224,9 +355,12 @@ func (it *iterator) nextInWithinGroupsStage() (tok Token, err error) {
 	return tok, nil
 }
 
-func (it *iterator) prepareToUseSublexer(rule *rule, groupText []rune, captureStart int, state string) {
-	lex := newIterator(groupText, it.rules)
-	lex.setOffset(it.state.index + captureStart)
+func (it *iterator) prepareToUseSublexer(rule *rule, groupText []rune, captureStart int, rulez rules, state string) {
+	lex := newIterator(groupText, rulez)
+	lex.setOffset(it.state.offset + it.state.index + captureStart)
+	lex.registry = it.registry
+	lex.opts = it.opts
+	lex.diags = it.diags
 	lex.depth = it.depth + 1
 	lex.pushState(state)
 	it.state.stage = stageRunningSublexer
@@ -235,15 +369,14 @@ func (it *iterator) prepareToUseSublexer(rule *rule, groupText []rune, captureSt
 }
 
 func (it *iterator) completeGroupIteration() error {
-	mylog.Check(it.handleRuleState(it.state.rule))
+	if stateErr := it.handleRuleState(it.state.rule); stateErr != nil {
+		return stateErr
+	}
 
 	it.state.stage = stageReadyToMatch
-	if len(it.state.byGroups) == 0 {
-		// byGroups has zero elements when this is a usingself that is not within groups; it's against the complete match of the rule's pattern
-		it.state.index += len(it.text)
-	} else {
-		it.state.index += it.state.groups[0].length // Move past the length of the match
-	}
+	// When this is a usingself or using that is not within groups byGroups has zero elements,
+	// but the groups are still set from the complete match of the rule's pattern.
+	it.state.index += it.state.groups[0].length // Move past the length of the match
 	it.clearGroupIterationInfo()
 	return nil
 }
@@ -256,7 +389,10 @@ func (it *iterator) clearGroupIterationInfo() {
 }
 
 func (it *iterator) nextInSublexer() (tok Token, err error) {
-	tok = mylog.Check2(it.sublexers[len(it.sublexers)-1].Next())
+	tok, nextErr := it.sublexers[len(it.sublexers)-1].Next()
+	if nextErr != nil {
+		return tok, nextErr
+	}
 
 	// On an error, we need to clean up all sublexers.
 	// Each parent lexer will clean up it's first child lexer.
@@ -270,8 +406,9 @@ func (it *iterator) nextInSublexer() (tok Token, err error) {
 		debugf("iterator.nextInSublexer(%d): Setting groupindex to %d (%d/%d)\n", it.depth, it.state.groupIndex, it.state.groupIndex+1, len(it.state.byGroups))
 		if it.state.groupIndex >= len(it.state.byGroups) {
 			debugf("iterator.nextInSublexer(%d): Reached end of groups, will switch to full match stage\n", it.depth)
-			mylog.Check(it.completeGroupIteration())
-
+			if groupErr := it.completeGroupIteration(); groupErr != nil {
+				return Token{}, groupErr
+			}
 		}
 		return it.Next()
 	}
@@ -279,6 +416,24 @@ func (it *iterator) nextInSublexer() (tok Token, err error) {
 	return
 }
 
+// lookupUsing finds the rules of the lexer named by a using rule. ok is false if there is no
+// registry, no lexer with that name, or the lexer is not defined by rules (i.e. it is a
+// delegating or composite lexer); in that case the text is emitted as Text.
+func (it *iterator) lookupUsing(name string) (rulez rules, ok bool) {
+	if it.registry == nil {
+		debugf("iterator.lookupUsing(%d): no registry to find lexer %s in", it.depth, name)
+		return
+	}
+
+	lex := it.registry.Get(name)
+	if lex == nil || lex.delegation != nil || lex.composition != nil {
+		debugf("iterator.lookupUsing(%d): no rule-based lexer named %s found", it.depth, name)
+		return
+	}
+
+	return lex.rules, true
+}
+
 func aLittleText(r []rune, index int) string {
 	if index >= len(r) {
 		return ""
@@ -335,6 +490,15 @@ func (it *iterator) handleRuleState(rule *rule) error {
 	if !ok {
 		return fmt.Errorf("syn.iterator: a rule refers to a state %s that doesn't exist", rule.pushState)
 	}
+	if it.opts.maxStackDepth > 0 && it.state.stack.Len() >= it.opts.maxStackDepth {
+		debugf("iterator.handleRuleState(%d): stack is too deep to push state %s; resetting to root", it.depth, rule.pushState)
+		it.resetState, it.resetRule = it.state.stack.Top().name, rule.patternString()
+		it.state.stack.Clear()
+		it.pushRootStateIfNeeded()
+		it.stackReset = true
+		return nil
+	}
+
 	debugf("iterator.handleRuleState(%d): pushing state %s", it.depth, rule.pushState)
 	it.state.stack.Push(s)
 	return nil
@@ -365,15 +529,17 @@ func (it *iterator) State() IteratorState {
 func (it *iterator) SetState(s IteratorState) {
 	state := s.(lexerStates)
 
-	// The lexers and sublexers all use the same rules because the only way to make
-	// a sublexer is through usingself (for now).
-	// So we can just clone the rules from the base lexer to all the sublexers.
+	// Sublexers created by usingself share the rules of the base lexer, but those created by
+	// using have the rules of another lexer, so the rules are kept in each sublexer's state.
 	it.state = state[0]
 
 	it.sublexers = make([]*iterator, len(state)-1)
 	for i, state := range state[1:] {
 		// TODO: we can't set the text that we're parsing as part of the state
-		it.sublexers[i] = newIterator(it.text, it.rules)
+		it.sublexers[i] = newIterator(it.text, state.rules)
+		it.sublexers[i].registry = it.registry
+		it.sublexers[i].opts = it.opts
+		it.sublexers[i].diags = it.diags
 		it.depth = i + 1
 		it.sublexers[i].state = state
 	}
@@ -411,6 +577,7 @@ type lexerState struct {
 	byGroups   []byGroupElement // The "by groups" items defined in the rule that specify how to handle each group from the match
 	rule       *rule            // Rule we are matching the groups for
 	offsetIter offsetIterator
+	rules      rules // Rules of the lexer this state belongs to
 }
 
 func (ls lexerState) equal(o *lexerState) bool {
@@ -29,8 +29,27 @@ import (
 type Lexer struct {
 	config *config.Lexer
 	rules  rules
+	// registry is the registry the lexer was registered with. It is used to find the lexers
+	// referred to by using rules.
+	registry *LexerRegistry
+	// delegation is set when this is a delegating lexer; see NewDelegatingLexer.
+	delegation *delegation
+	// composition is set when this is a composite lexer; see NewCompositeLexer.
+	composition *composition
+	// matchTimeout is the maximum time a rule's pattern may spend matching.
+	matchTimeout time.Duration
+	iterOpts     iteratorOptions
+	indent       indentRules
+	// analyse holds the compiled patterns of the Analyse of the lexer's definition.
+	analyse []*regexp2.Regexp
+	// lazy is set if the Lexer is a placeholder for a lexer registered with LexerRegistry.RegisterLazy.
+	lazy *lazyLoad
 }
 
+// DefaultMatchTimeout is the maximum time a rule's pattern may spend matching before the match
+// is abandoned, unless changed using WithMatchTimeout.
+const DefaultMatchTimeout = 250 * time.Millisecond
+
 func newLexer(r rules) *Lexer {
 	return &Lexer{
 		rules: r,
@@ -38,36 +57,98 @@ func newLexer(r rules) *Lexer {
 }
 
 // NewLexerFromXML creates a new lexer given an XML file containing a definition of a lexer.
-func NewLexerFromXMLFile(xmlLexerConfigFile string) (*Lexer, error) {
+func NewLexerFromXMLFile(xmlLexerConfigFile string, opts ...Option) (*Lexer, error) {
 	f := mylog.Check2(os.Open(xmlLexerConfigFile))
 
-	return NewLexerFromXML(f)
+	return NewLexerFromXML(f, opts...)
 }
 
 // NewLexerFromXML creates a new lexer given an XML file containing a definition of a lexer. The file is opened
 // using the specified FS.
-func NewLexerFromXMLFS(fsys fs.FS, xmlLexerConfigFile string) (*Lexer, error) {
+func NewLexerFromXMLFS(fsys fs.FS, xmlLexerConfigFile string, opts ...Option) (*Lexer, error) {
 	f := mylog.Check2(fsys.Open(xmlLexerConfigFile))
-	return NewLexerFromXML(f)
+	return NewLexerFromXML(f, opts...)
 }
 
-// NewLexerFromXML creates a new lexer given an XML definition of a lexer.
-func NewLexerFromXML(rdr io.Reader) (*Lexer, error) {
-	lexModel := mylog.Check2(config.DecodeLexer(rdr))
+// SchemaVersion is the newest version of the schema of XML lexer definitions that this version of syn supports.
+// Definitions give the version they are written for in the version attribute of the lexer element, and loading a
+// definition written for a newer version fails. Definitions without the attribute are taken to be version 1.
+const SchemaVersion = config.SchemaVersion
+
+// NewLexerFromXML creates a new lexer given an XML definition of a lexer. The options opts are applied as by
+// Lexer.With, but without building the lexer a second time.
+func NewLexerFromXML(rdr io.Reader, opts ...Option) (*Lexer, error) {
+	lexModel, decodeErr := config.DecodeLexer(rdr)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
 	bld := newLexerBuilder(lexModel)
-	lex := mylog.Check2(bld.Build())
+	bld.apply(opts)
+	lex, buildErr := bld.Build()
+	if buildErr != nil {
+		return nil, buildErr
+	}
 	debugf("NewLexerFromXML: lexer rules:\n%s\n", lex.rules)
 	return lex, nil
 }
 
-func (l *Lexer) Tokenise(text []rune) Iterator {
-	return l.tokeniseAt(text, nil)
+// Tokenise returns an Iterator over the tokens of text. Options given apply only to this call; see Lexer.With.
+func (l *Lexer) Tokenise(text []rune, opts ...Option) Iterator {
+	if len(opts) > 0 {
+		l = l.withCallOptions(opts)
+	}
+
+	src, diags := l.tokenise(text)
+	src = l.reshape(src)
+	if m := l.metrics(); m != nil {
+		src = meter(src, m, l.config.Config.Name, text, diags)
+	}
+	return newLookahead(src, diags)
+}
+
+// tokenise returns the tokens of text as produced by the rules, before the changes to the form of the tokens
+// requested by options such as WithEOLTokens are made.
+func (l *Lexer) tokenise(text []rune) (tokenSource, *diagnostics) {
+	if l.delegation != nil {
+		it := l.delegation.tokenise(text)
+		return it, it.diags
+	}
+	if l.composition != nil {
+		it := l.composition.tokenise(text, l.registry)
+		return it, it.diags
+	}
+	diags := newDiagnostics(text)
+	return l.tokeniseAt(text, nil, diags), diags
+}
+
+// reshape wraps it so that the tokens are returned in the form the lexer's options ask for.
+func (l *Lexer) reshape(it tokenSource) tokenSource {
+	if l.iterOpts.eolTokens {
+		it = splitEOL(it)
+	}
+	if len(l.iterOpts.filters) > 0 {
+		it = applyFilters(it, l.iterOpts.filters)
+	}
+	if l.iterOpts.offsetsOnly {
+		it = dropValues(it)
+	}
+	return it
+}
+
+// TokensAll lexes the entire text and returns all the tokens. It is a shorthand for calling Collect on the
+// Iterator returned by Tokenise.
+func (l *Lexer) TokensAll(text []rune, opts ...Option) ([]Token, error) {
+	return l.Tokenise(text, opts...).Collect()
 }
 
 // tokeniseAt is currently broken. It only works when state is nil.
-func (l *Lexer) tokeniseAt(text []rune, state IteratorState) Iterator {
+func (l *Lexer) tokeniseAt(text []rune, state IteratorState, diags *diagnostics) tokenSource {
 	stripped, offsetMap := ensureLF(text)
+	diags.transitions = offsetMap.transitions
 	innerIter := newIterator(stripped, l.rules)
+	innerIter.diags = diags
+	innerIter.registry = l.registry
+	innerIter.opts = l.iterOpts
 	// TODO: when we use coalesce and we save the state, the coalescer state is actually
 	// 1 or more tokens ahead of what has been returned during iteration so far, and the
 	// coalescer's stored token(s) match the previous unmodified text.
@@ -90,6 +171,21 @@ func (l *Lexer) tokeniseAt(text []rune, state IteratorState) Iterator {
 	return outerIter
 }
 
+// Config returns the identifying information of the lexer: its name, aliases, filenames and MIME types, along
+// with the editing information from its definition.
+func (l *Lexer) Config() LexerConfig {
+	c := l.config.Config
+	return LexerConfig{
+		Name:      c.Name,
+		Aliases:   c.Aliases,
+		Filenames: c.Filenames,
+		MimeTypes: c.MimeTypes,
+		Priority:  c.Priority,
+		Analyse:   analyseFromConfig(c.Analyse),
+		Editing:   editingFromConfig(c.Editing),
+	}
+}
+
 func (l *Lexer) cfg() *config.Lexer {
 	return l.config
 }
@@ -103,13 +199,43 @@ func newLexerBuilder(cfg *config.Lexer) lexerBuilder {
 	return lexerBuilder{
 		cfg: cfg,
 		lexer: &Lexer{
-			rules:  newRules(),
-			config: cfg,
+			rules:        newRules(),
+			config:       cfg,
+			matchTimeout: DefaultMatchTimeout,
+			iterOpts: iteratorOptions{
+				maxStackDepth: DefaultMaxStackDepth,
+			},
 		},
 	}
 }
 
+// apply sets the options of the lexer to be built to the defaults with opts applied.
+func (lb *lexerBuilder) apply(opts []Option) {
+	if len(opts) == 0 {
+		return
+	}
+	o := lexerOptions{
+		cfg:          lb.cfg.Config,
+		matchTimeout: lb.lexer.matchTimeout,
+		iterOpts:     lb.lexer.iterOpts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	lb.cfg.Config = o.cfg
+	lb.lexer.matchTimeout = o.matchTimeout
+	lb.lexer.iterOpts = o.iterOpts
+}
+
 func (lb *lexerBuilder) Build() (*Lexer, error) {
+	if globErr := checkFilenames(lb.cfg.Config); globErr != nil {
+		return nil, globErr
+	}
+	analyse, analyseErr := compileAnalyse(lb.cfg.Config.Analyse, lb.lexer.matchTimeout)
+	if analyseErr != nil {
+		return nil, analyseErr
+	}
+	lb.lexer.analyse = analyse
 	mylog.CheckIgnore(lb.validate())
 	mylog.Check(lb.build())
 
@@ -163,7 +289,7 @@ func (r lexerBuilder) makeMissingError(missing []string) error {
 func (lb *lexerBuilder) build() error {
 	for _, xmlState := range lb.cfg.Rules.States {
 
-		seq := mylog.Check2(lb.ruleSequence(xmlState.Rules))
+		seq := mylog.Check2(lb.ruleSequence(xmlState.Name, xmlState.Rules))
 
 		s := state{xmlState.Name, seq}
 		lb.lexer.rules.AddState(s)
@@ -173,31 +299,34 @@ func (lb *lexerBuilder) build() error {
 		mylog.Check(lb.createCombinedStates(&xmlState))
 	}
 
+	lb.lexer.indent = mylog.Check2(newIndentRules(lb.cfg.Config.Editing))
+
 	return nil
 }
 
-func (lb *lexerBuilder) ruleSequence(crs []config.Rule) ([]rule, error) {
+func (lb *lexerBuilder) ruleSequence(stateName string, crs []config.Rule) ([]rule, error) {
 	rules := make([]rule, len(crs))
 	for i, cr := range crs {
 		mylog.Check(lb.checkRule(&cr))
 
-		r := mylog.Check2(lb.makeRule(cr.Pattern))
+		r := mylog.Check2(lb.makeRule(cr.Pattern, lb.regexpOptions()))
 
 		lb.updatePushForCombinedState(&r, &cr)
 		mylog.Check(lb.setRuleFieldsFrom(&r, &cr))
+		r.origin = RuleRef{Lexer: lb.cfg.Config.Name, State: stateName, Index: i}
 
 		rules[i] = r
 	}
 	return rules, nil
 }
 
-func (lb *lexerBuilder) makeRule(pattern string) (r rule, err error) {
+func (lb *lexerBuilder) makeRule(pattern string, opts regexp2.RegexOptions) (r rule, err error) {
 	pat := `\A` + pattern
 
 	var re *regexp2.Regexp
-	re = mylog.Check2(regexp2.Compile(pat, regexp2.Multiline))
+	re = mylog.Check2(regexp2.Compile(pat, opts))
 
-	re.MatchTimeout = time.Millisecond * 250
+	re.MatchTimeout = lb.lexer.matchTimeout
 
 	r = rule{
 		pattern: re,
@@ -205,6 +334,22 @@ func (lb *lexerBuilder) makeRule(pattern string) (r rule, err error) {
 	return
 }
 
+// regexpOptions returns the options used to compile the patterns of all rules, as
+// selected by the flags in the lexer's config.
+func (lb *lexerBuilder) regexpOptions() regexp2.RegexOptions {
+	var opts regexp2.RegexOptions
+	if !lb.cfg.Config.NotMultiline {
+		opts |= regexp2.Multiline
+	}
+	if lb.cfg.Config.CaseInsensitive {
+		opts |= regexp2.IgnoreCase
+	}
+	if lb.cfg.Config.DotAll {
+		opts |= regexp2.Singleline
+	}
+	return opts
+}
+
 // updatePushForCombinedState helps to handle the <combined> element. The combined element
 // under a rule requests the lexer to combine all the rules from two states to make a new
 // state, and then have the rule push that state. This function replaces the push statement
@@ -214,7 +359,7 @@ func (lb *lexerBuilder) updatePushForCombinedState(r *rule, cr *config.Rule) {
 		return
 	}
 
-	stateName := lb.combinedStateName(cr.Combined)
+	stateName := combinedStateName(cr.Combined)
 	r.pushState = stateName
 }
 
@@ -235,7 +380,7 @@ func (lb *lexerBuilder) createCombinedStateInRule(stateName string, cr *config.R
 		return nil
 	}
 
-	combinedStateName := lb.combinedStateName(cr.Combined)
+	combinedStateName := combinedStateName(cr.Combined)
 	if lb.lexer.rules.Contains(combinedStateName) {
 		return nil
 	}
@@ -259,7 +404,8 @@ func (lb *lexerBuilder) createCombinedStateInRule(stateName string, cr *config.R
 	return nil
 }
 
-func (lb *lexerBuilder) combinedStateName(c *config.Combined) string {
+// combinedStateName returns the name of the state made for the combined element c.
+func combinedStateName(c *config.Combined) string {
 	var buf bytes.Buffer
 	buf.WriteString("__combined_")
 	buf.WriteString(strings.Join(c.States, "__"))
@@ -295,6 +441,8 @@ func (lb *lexerBuilder) setRuleFieldsFrom(r *rule, cr *config.Rule) error {
 				ge.tok = typ
 			case *config.UsingSelf:
 				ge.useSelfState = v.State
+			case *config.Using:
+				ge.usingLexer = v.Lexer
 			}
 			r.byGroups = append(r.byGroups, ge)
 		}
@@ -304,6 +452,10 @@ func (lb *lexerBuilder) setRuleFieldsFrom(r *rule, cr *config.Rule) error {
 		r.useSelfState = cr.UsingSelf.State
 	}
 
+	if cr.Using != nil {
+		r.usingLexer = cr.Using.Lexer
+	}
+
 	return nil
 }
 
@@ -336,6 +488,12 @@ func (lb *lexerBuilder) checkRule(r *config.Rule) error {
 		}
 	}
 
+	if r.Using != nil {
+		if r.Token != nil || r.ByGroups != nil || r.UsingSelf != nil {
+			return fmt.Errorf("a rule has a Using and either a Token, ByGroups or UsingSelf")
+		}
+	}
+
 	if r.Combined != nil && (r.Push != nil || r.Pop != nil || r.Include != nil) {
 		return fmt.Errorf("a rule has both a Combined and either a Push, Pop or Include")
 	}
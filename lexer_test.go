@@ -1,9 +1,15 @@
 package syn
 
 import (
+	"errors"
+	"image/color"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
 
 	"github.com/ddkwork/golibrary/mylog"
+	"github.com/dlclark/regexp2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,7 +31,6 @@ int main() {
 
 	input := []rune(prog)
 	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
-	assert.Nil(err)
 	assert.NotNil(lex)
 
 	// DebugLogger = log.New(os.Stdout, "", 0)
@@ -120,7 +125,6 @@ def f():
 
 	input := []rune(prog)
 	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/python.xml"))
-	assert.Nil(err)
 	assert.NotNil(lex)
 
 	// DebugLogger = log.New(os.Stdout, "", 0)
@@ -378,7 +382,6 @@ func TestLexerCRLF(t *testing.T) {
 
 	input := []rune(prog)
 	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
-	assert.Nil(err)
 	assert.NotNil(lex)
 
 	// DebugLogger = log.New(os.Stdout, "", 0)
@@ -449,7 +452,6 @@ text
 
 	input := []rune(doc)
 	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/markdown.xml"))
-	assert.Nil(err)
 	assert.NotNil(lex)
 
 	// DebugLogger = log.New(os.Stdout, "", 0)
@@ -486,7 +488,6 @@ int var3;
 
 	input := []rune(prog)
 	lex, err := NewLexerFromXMLFile("lexers/embedded/c.xml")
-	assert.Nil(err)
 	assert.NotNil(lex)
 	if err != nil {
 		t.FailNow()
@@ -505,13 +506,11 @@ int var3;
 	}
 
 	tokens, err := tokenizeAtMost(it1, 5)
-	assert.Nil(err)
 	// make sure we're in the right place
 	assert.Equal(expected, tokens)
 
 	// tokenize it2 until just after line 1
 	tokens, err = tokenizeAtMost(it2, 5)
-	assert.Nil(err)
 	// make sure we're in the right place
 	assert.Equal(expected, tokens)
 
@@ -528,7 +527,6 @@ int var3;
 	}
 
 	tokens, err = tokenizeAtMost(it2, 5)
-	assert.Nil(err)
 	// make sure we're in the right place
 	assert.Equal(expected, tokens)
 
@@ -539,7 +537,6 @@ int var3;
 	// should then be equal
 	itTmp := lex.TokeniseAt(input, it1.State())
 	tokens, err = tokenizeAtMost(itTmp, 5)
-	assert.Nil(err)
 	assert.Equal(expected, tokens)
 	assert.True(it2.State().Equal(itTmp.State()))
 
@@ -559,7 +556,6 @@ int var3;
 	// make a new iterator starting at end end of line 1 (it1) and when it gets to the same point as it2 they should be equal
 	itTmp = lex.TokeniseAt(input, it1.State())
 	tokens, err = tokenizeAtMost(itTmp, 6)
-	assert.Nil(err)
 	expected = []Token{
 		{Type: KeywordType, Value: []rune("char"), Start: 10, End: 14},
 		{Type: Operator, Value: []rune("*"), Start: 14, End: 15},
@@ -576,3 +572,1000 @@ int var3;
 
 }
 */
+
+func TestJSX(t *testing.T) {
+	prog := "return (\n  <a href={url} {...rest}>\n    Hi {n < 2 ? <b/> : name} &amp;\n  </a>\n);\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/react.xml"))
+
+	tokens := mylog.Check2(tokenize(lex.Tokenise([]rune(prog))))
+
+	dumpTokens(t, tokens)
+
+	expected := []Token{
+		{Type: Keyword, Value: []rune("return"), Start: 0, End: 6},
+		{Type: Text, Value: []rune(" "), Start: 6, End: 7},
+		{Type: Punctuation, Value: []rune("("), Start: 7, End: 8},
+		{Type: Text, Value: []rune("\n  "), Start: 8, End: 11},
+		{Type: Punctuation, Value: []rune("<"), Start: 11, End: 12},
+		{Type: NameTag, Value: []rune("a"), Start: 12, End: 13},
+		{Type: Text, Value: []rune(" "), Start: 13, End: 14},
+		{Type: NameAttribute, Value: []rune("href"), Start: 14, End: 18},
+		{Type: Operator, Value: []rune("="), Start: 18, End: 19},
+		{Type: Punctuation, Value: []rune("{"), Start: 19, End: 20},
+		{Type: NameOther, Value: []rune("url"), Start: 20, End: 23},
+		{Type: Punctuation, Value: []rune("}"), Start: 23, End: 24},
+		{Type: Text, Value: []rune(" "), Start: 24, End: 25},
+		{Type: Punctuation, Value: []rune("{..."), Start: 25, End: 29},
+		{Type: NameOther, Value: []rune("rest"), Start: 29, End: 33},
+		{Type: Punctuation, Value: []rune("}>"), Start: 33, End: 35},
+		{Type: Text, Value: []rune("\n    Hi "), Start: 35, End: 43},
+		{Type: Punctuation, Value: []rune("{"), Start: 43, End: 44},
+		{Type: NameOther, Value: []rune("n"), Start: 44, End: 45},
+		{Type: Text, Value: []rune(" "), Start: 45, End: 46},
+		{Type: Operator, Value: []rune("<"), Start: 46, End: 47},
+		{Type: Text, Value: []rune(" "), Start: 47, End: 48},
+		{Type: LiteralNumberInteger, Value: []rune("2"), Start: 48, End: 49},
+		{Type: Text, Value: []rune(" "), Start: 49, End: 50},
+		{Type: Operator, Value: []rune("?"), Start: 50, End: 51},
+		{Type: Text, Value: []rune(" "), Start: 51, End: 52},
+		{Type: Punctuation, Value: []rune("<"), Start: 52, End: 53},
+		{Type: NameTag, Value: []rune("b"), Start: 53, End: 54},
+		{Type: Punctuation, Value: []rune("/>"), Start: 54, End: 56},
+		{Type: Text, Value: []rune(" "), Start: 56, End: 57},
+		{Type: Operator, Value: []rune(":"), Start: 57, End: 58},
+		{Type: Text, Value: []rune(" "), Start: 58, End: 59},
+		{Type: NameOther, Value: []rune("name"), Start: 59, End: 63},
+		{Type: Punctuation, Value: []rune("}"), Start: 63, End: 64},
+		{Type: Text, Value: []rune(" "), Start: 64, End: 65},
+		{Type: NameEntity, Value: []rune("&amp;"), Start: 65, End: 70},
+		{Type: Text, Value: []rune("\n  "), Start: 70, End: 73},
+		{Type: Punctuation, Value: []rune("</"), Start: 73, End: 75},
+		{Type: NameTag, Value: []rune("a"), Start: 75, End: 76},
+		{Type: Punctuation, Value: []rune(">"), Start: 76, End: 77},
+		{Type: Text, Value: []rune("\n"), Start: 77, End: 78},
+		{Type: Punctuation, Value: []rune(");"), Start: 78, End: 80},
+		{Type: Text, Value: []rune("\n"), Start: 80, End: 81},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestTSX(t *testing.T) {
+	prog := "const f = (x: number) => <><p>{x}</p></>;\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/tsx.xml"))
+
+	tokens := mylog.Check2(tokenize(lex.Tokenise([]rune(prog))))
+
+	dumpTokens(t, tokens)
+
+	expected := []Token{
+		{Type: KeywordReserved, Value: []rune("const"), Start: 0, End: 5},
+		{Type: Text, Value: []rune(" "), Start: 5, End: 6},
+		{Type: NameOther, Value: []rune("f"), Start: 6, End: 7},
+		{Type: Text, Value: []rune(" "), Start: 7, End: 8},
+		{Type: Operator, Value: []rune("="), Start: 8, End: 9},
+		{Type: Text, Value: []rune(" "), Start: 9, End: 10},
+		{Type: Punctuation, Value: []rune("("), Start: 10, End: 11},
+		{Type: NameOther, Value: []rune("x"), Start: 11, End: 12},
+		{Type: Operator, Value: []rune(":"), Start: 12, End: 13},
+		{Type: Text, Value: []rune(" "), Start: 13, End: 14},
+		{Type: KeywordType, Value: []rune("number"), Start: 14, End: 20},
+		{Type: Punctuation, Value: []rune(")"), Start: 20, End: 21},
+		{Type: Text, Value: []rune(" "), Start: 21, End: 22},
+		{Type: Operator, Value: []rune("=>"), Start: 22, End: 24},
+		{Type: Text, Value: []rune(" "), Start: 24, End: 25},
+		{Type: Punctuation, Value: []rune("<><"), Start: 25, End: 28},
+		{Type: NameTag, Value: []rune("p"), Start: 28, End: 29},
+		{Type: Punctuation, Value: []rune(">{"), Start: 29, End: 31},
+		{Type: NameOther, Value: []rune("x"), Start: 31, End: 32},
+		{Type: Punctuation, Value: []rune("}</"), Start: 32, End: 35},
+		{Type: NameTag, Value: []rune("p"), Start: 35, End: 36},
+		{Type: Punctuation, Value: []rune("></>;"), Start: 36, End: 41},
+		{Type: Text, Value: []rune("\n"), Start: 41, End: 42},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestTypeScript(t *testing.T) {
+	prog := "import type { A } from \"./a\";\n@Component({ selector: \"x\" })\nexport class Foo<T extends A> {\n  private n?: number = ok ? 1 : 2;\n  get(k: `id-${string}`): T | undefined {\n    return f<T>(k) as T;\n  }\n}\ntype P = { readonly a: number; b?: (x: string) => void };\nenum Color { Red = 1 }\nlet c: P = { a: n > 0 ? x : y } satisfies P;\nswitch (c) {\n  case a: break;\n}\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/typescript.xml"))
+
+	tokens := mylog.Check2(tokenize(lex.Tokenise([]rune(prog))))
+
+	dumpTokens(t, tokens)
+
+	expected := []Token{
+		{Type: KeywordReserved, Value: []rune("import"), Start: 0, End: 6},
+		{Type: Text, Value: []rune(" "), Start: 6, End: 7},
+		{Type: Keyword, Value: []rune("type"), Start: 7, End: 11},
+		{Type: Text, Value: []rune(" "), Start: 11, End: 12},
+		{Type: Punctuation, Value: []rune("{"), Start: 12, End: 13},
+		{Type: Text, Value: []rune(" "), Start: 13, End: 14},
+		{Type: NameOther, Value: []rune("A"), Start: 14, End: 15},
+		{Type: Text, Value: []rune(" "), Start: 15, End: 16},
+		{Type: Punctuation, Value: []rune("}"), Start: 16, End: 17},
+		{Type: Text, Value: []rune(" "), Start: 17, End: 18},
+		{Type: KeywordReserved, Value: []rune("from"), Start: 18, End: 22},
+		{Type: Text, Value: []rune(" "), Start: 22, End: 23},
+		{Type: LiteralStringDouble, Value: []rune("\"./a\""), Start: 23, End: 28},
+		{Type: Punctuation, Value: []rune(";"), Start: 28, End: 29},
+		{Type: Text, Value: []rune("\n"), Start: 29, End: 30},
+		{Type: NameDecorator, Value: []rune("@Component"), Start: 30, End: 40},
+		{Type: Punctuation, Value: []rune("({"), Start: 40, End: 42},
+		{Type: Text, Value: []rune(" "), Start: 42, End: 43},
+		{Type: NameOther, Value: []rune("selector"), Start: 43, End: 51},
+		{Type: Operator, Value: []rune(":"), Start: 51, End: 52},
+		{Type: Text, Value: []rune(" "), Start: 52, End: 53},
+		{Type: LiteralStringDouble, Value: []rune("\"x\""), Start: 53, End: 56},
+		{Type: Text, Value: []rune(" "), Start: 56, End: 57},
+		{Type: Punctuation, Value: []rune("})"), Start: 57, End: 59},
+		{Type: Text, Value: []rune("\n"), Start: 59, End: 60},
+		{Type: KeywordReserved, Value: []rune("export"), Start: 60, End: 66},
+		{Type: Text, Value: []rune(" "), Start: 66, End: 67},
+		{Type: KeywordDeclaration, Value: []rune("class"), Start: 67, End: 72},
+		{Type: Text, Value: []rune(" "), Start: 72, End: 73},
+		{Type: NameClass, Value: []rune("Foo"), Start: 73, End: 76},
+		{Type: Punctuation, Value: []rune("<"), Start: 76, End: 77},
+		{Type: NameClass, Value: []rune("T"), Start: 77, End: 78},
+		{Type: Text, Value: []rune(" "), Start: 78, End: 79},
+		{Type: Keyword, Value: []rune("extends"), Start: 79, End: 86},
+		{Type: Text, Value: []rune(" "), Start: 86, End: 87},
+		{Type: NameClass, Value: []rune("A"), Start: 87, End: 88},
+		{Type: Punctuation, Value: []rune(">"), Start: 88, End: 89},
+		{Type: Text, Value: []rune(" "), Start: 89, End: 90},
+		{Type: Punctuation, Value: []rune("{"), Start: 90, End: 91},
+		{Type: Text, Value: []rune("\n  "), Start: 91, End: 94},
+		{Type: KeywordReserved, Value: []rune("private"), Start: 94, End: 101},
+		{Type: Text, Value: []rune(" "), Start: 101, End: 102},
+		{Type: NameOther, Value: []rune("n"), Start: 102, End: 103},
+		{Type: Operator, Value: []rune("?:"), Start: 103, End: 105},
+		{Type: Text, Value: []rune(" "), Start: 105, End: 106},
+		{Type: KeywordType, Value: []rune("number"), Start: 106, End: 112},
+		{Type: Text, Value: []rune(" "), Start: 112, End: 113},
+		{Type: Operator, Value: []rune("="), Start: 113, End: 114},
+		{Type: Text, Value: []rune(" "), Start: 114, End: 115},
+		{Type: NameOther, Value: []rune("ok"), Start: 115, End: 117},
+		{Type: Text, Value: []rune(" "), Start: 117, End: 118},
+		{Type: Operator, Value: []rune("?"), Start: 118, End: 119},
+		{Type: Text, Value: []rune(" "), Start: 119, End: 120},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 120, End: 121},
+		{Type: Text, Value: []rune(" "), Start: 121, End: 122},
+		{Type: Operator, Value: []rune(":"), Start: 122, End: 123},
+		{Type: Text, Value: []rune(" "), Start: 123, End: 124},
+		{Type: LiteralNumberInteger, Value: []rune("2"), Start: 124, End: 125},
+		{Type: Punctuation, Value: []rune(";"), Start: 125, End: 126},
+		{Type: Text, Value: []rune("\n  "), Start: 126, End: 129},
+		{Type: KeywordReserved, Value: []rune("get"), Start: 129, End: 132},
+		{Type: Punctuation, Value: []rune("("), Start: 132, End: 133},
+		{Type: NameOther, Value: []rune("k"), Start: 133, End: 134},
+		{Type: Operator, Value: []rune(":"), Start: 134, End: 135},
+		{Type: Text, Value: []rune(" "), Start: 135, End: 136},
+		{Type: LiteralStringBacktick, Value: []rune("`id-"), Start: 136, End: 140},
+		{Type: LiteralStringInterpol, Value: []rune("${"), Start: 140, End: 142},
+		{Type: KeywordType, Value: []rune("string"), Start: 142, End: 148},
+		{Type: LiteralStringInterpol, Value: []rune("}"), Start: 148, End: 149},
+		{Type: LiteralStringBacktick, Value: []rune("`"), Start: 149, End: 150},
+		{Type: Punctuation, Value: []rune(")"), Start: 150, End: 151},
+		{Type: Operator, Value: []rune(":"), Start: 151, End: 152},
+		{Type: Text, Value: []rune(" "), Start: 152, End: 153},
+		{Type: NameClass, Value: []rune("T"), Start: 153, End: 154},
+		{Type: Text, Value: []rune(" "), Start: 154, End: 155},
+		{Type: Operator, Value: []rune("|"), Start: 155, End: 156},
+		{Type: Text, Value: []rune(" "), Start: 156, End: 157},
+		{Type: KeywordType, Value: []rune("undefined"), Start: 157, End: 166},
+		{Type: Text, Value: []rune(" "), Start: 166, End: 167},
+		{Type: Punctuation, Value: []rune("{"), Start: 167, End: 168},
+		{Type: Text, Value: []rune("\n    "), Start: 168, End: 173},
+		{Type: Keyword, Value: []rune("return"), Start: 173, End: 179},
+		{Type: Text, Value: []rune(" "), Start: 179, End: 180},
+		{Type: NameOther, Value: []rune("f"), Start: 180, End: 181},
+		{Type: Punctuation, Value: []rune("<"), Start: 181, End: 182},
+		{Type: NameClass, Value: []rune("T"), Start: 182, End: 183},
+		{Type: Punctuation, Value: []rune(">("), Start: 183, End: 185},
+		{Type: NameOther, Value: []rune("k"), Start: 185, End: 186},
+		{Type: Punctuation, Value: []rune(")"), Start: 186, End: 187},
+		{Type: Text, Value: []rune(" "), Start: 187, End: 188},
+		{Type: Keyword, Value: []rune("as"), Start: 188, End: 190},
+		{Type: Text, Value: []rune(" "), Start: 190, End: 191},
+		{Type: NameClass, Value: []rune("T"), Start: 191, End: 192},
+		{Type: Punctuation, Value: []rune(";"), Start: 192, End: 193},
+		{Type: Text, Value: []rune("\n  "), Start: 193, End: 196},
+		{Type: Punctuation, Value: []rune("}"), Start: 196, End: 197},
+		{Type: Text, Value: []rune("\n"), Start: 197, End: 198},
+		{Type: Punctuation, Value: []rune("}"), Start: 198, End: 199},
+		{Type: Text, Value: []rune("\n"), Start: 199, End: 200},
+		{Type: KeywordDeclaration, Value: []rune("type"), Start: 200, End: 204},
+		{Type: Text, Value: []rune(" "), Start: 204, End: 205},
+		{Type: NameClass, Value: []rune("P"), Start: 205, End: 206},
+		{Type: Text, Value: []rune(" "), Start: 206, End: 207},
+		{Type: Operator, Value: []rune("="), Start: 207, End: 208},
+		{Type: Text, Value: []rune(" "), Start: 208, End: 209},
+		{Type: Punctuation, Value: []rune("{"), Start: 209, End: 210},
+		{Type: Text, Value: []rune(" "), Start: 210, End: 211},
+		{Type: Keyword, Value: []rune("readonly"), Start: 211, End: 219},
+		{Type: Text, Value: []rune(" "), Start: 219, End: 220},
+		{Type: NameOther, Value: []rune("a"), Start: 220, End: 221},
+		{Type: Operator, Value: []rune(":"), Start: 221, End: 222},
+		{Type: Text, Value: []rune(" "), Start: 222, End: 223},
+		{Type: KeywordType, Value: []rune("number"), Start: 223, End: 229},
+		{Type: Punctuation, Value: []rune(";"), Start: 229, End: 230},
+		{Type: Text, Value: []rune(" "), Start: 230, End: 231},
+		{Type: NameOther, Value: []rune("b"), Start: 231, End: 232},
+		{Type: Operator, Value: []rune("?:"), Start: 232, End: 234},
+		{Type: Text, Value: []rune(" "), Start: 234, End: 235},
+		{Type: Punctuation, Value: []rune("("), Start: 235, End: 236},
+		{Type: NameOther, Value: []rune("x"), Start: 236, End: 237},
+		{Type: Operator, Value: []rune(":"), Start: 237, End: 238},
+		{Type: Text, Value: []rune(" "), Start: 238, End: 239},
+		{Type: KeywordType, Value: []rune("string"), Start: 239, End: 245},
+		{Type: Punctuation, Value: []rune(")"), Start: 245, End: 246},
+		{Type: Text, Value: []rune(" "), Start: 246, End: 247},
+		{Type: Punctuation, Value: []rune("=>"), Start: 247, End: 249},
+		{Type: Text, Value: []rune(" "), Start: 249, End: 250},
+		{Type: KeywordType, Value: []rune("void"), Start: 250, End: 254},
+		{Type: Text, Value: []rune(" "), Start: 254, End: 255},
+		{Type: Punctuation, Value: []rune("};"), Start: 255, End: 257},
+		{Type: Text, Value: []rune("\n"), Start: 257, End: 258},
+		{Type: KeywordDeclaration, Value: []rune("enum"), Start: 258, End: 262},
+		{Type: Text, Value: []rune(" "), Start: 262, End: 263},
+		{Type: NameClass, Value: []rune("Color"), Start: 263, End: 268},
+		{Type: Text, Value: []rune(" "), Start: 268, End: 269},
+		{Type: Punctuation, Value: []rune("{"), Start: 269, End: 270},
+		{Type: Text, Value: []rune(" "), Start: 270, End: 271},
+		{Type: NameOther, Value: []rune("Red"), Start: 271, End: 274},
+		{Type: Text, Value: []rune(" "), Start: 274, End: 275},
+		{Type: Operator, Value: []rune("="), Start: 275, End: 276},
+		{Type: Text, Value: []rune(" "), Start: 276, End: 277},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 277, End: 278},
+		{Type: Text, Value: []rune(" "), Start: 278, End: 279},
+		{Type: Punctuation, Value: []rune("}"), Start: 279, End: 280},
+		{Type: Text, Value: []rune("\n"), Start: 280, End: 281},
+		{Type: KeywordDeclaration, Value: []rune("let"), Start: 281, End: 284},
+		{Type: Text, Value: []rune(" "), Start: 284, End: 285},
+		{Type: NameOther, Value: []rune("c"), Start: 285, End: 286},
+		{Type: Operator, Value: []rune(":"), Start: 286, End: 287},
+		{Type: Text, Value: []rune(" "), Start: 287, End: 288},
+		{Type: NameClass, Value: []rune("P"), Start: 288, End: 289},
+		{Type: Text, Value: []rune(" "), Start: 289, End: 290},
+		{Type: Operator, Value: []rune("="), Start: 290, End: 291},
+		{Type: Text, Value: []rune(" "), Start: 291, End: 292},
+		{Type: Punctuation, Value: []rune("{"), Start: 292, End: 293},
+		{Type: Text, Value: []rune(" "), Start: 293, End: 294},
+		{Type: NameOther, Value: []rune("a"), Start: 294, End: 295},
+		{Type: Operator, Value: []rune(":"), Start: 295, End: 296},
+		{Type: Text, Value: []rune(" "), Start: 296, End: 297},
+		{Type: NameOther, Value: []rune("n"), Start: 297, End: 298},
+		{Type: Text, Value: []rune(" "), Start: 298, End: 299},
+		{Type: Operator, Value: []rune(">"), Start: 299, End: 300},
+		{Type: Text, Value: []rune(" "), Start: 300, End: 301},
+		{Type: LiteralNumberInteger, Value: []rune("0"), Start: 301, End: 302},
+		{Type: Text, Value: []rune(" "), Start: 302, End: 303},
+		{Type: Operator, Value: []rune("?"), Start: 303, End: 304},
+		{Type: Text, Value: []rune(" "), Start: 304, End: 305},
+		{Type: NameOther, Value: []rune("x"), Start: 305, End: 306},
+		{Type: Text, Value: []rune(" "), Start: 306, End: 307},
+		{Type: Operator, Value: []rune(":"), Start: 307, End: 308},
+		{Type: Text, Value: []rune(" "), Start: 308, End: 309},
+		{Type: NameOther, Value: []rune("y"), Start: 309, End: 310},
+		{Type: Text, Value: []rune(" "), Start: 310, End: 311},
+		{Type: Punctuation, Value: []rune("}"), Start: 311, End: 312},
+		{Type: Text, Value: []rune(" "), Start: 312, End: 313},
+		{Type: Keyword, Value: []rune("satisfies"), Start: 313, End: 322},
+		{Type: Text, Value: []rune(" "), Start: 322, End: 323},
+		{Type: NameClass, Value: []rune("P"), Start: 323, End: 324},
+		{Type: Punctuation, Value: []rune(";"), Start: 324, End: 325},
+		{Type: Text, Value: []rune("\n"), Start: 325, End: 326},
+		{Type: Keyword, Value: []rune("switch"), Start: 326, End: 332},
+		{Type: Text, Value: []rune(" "), Start: 332, End: 333},
+		{Type: Punctuation, Value: []rune("("), Start: 333, End: 334},
+		{Type: NameOther, Value: []rune("c"), Start: 334, End: 335},
+		{Type: Punctuation, Value: []rune(")"), Start: 335, End: 336},
+		{Type: Text, Value: []rune(" "), Start: 336, End: 337},
+		{Type: Punctuation, Value: []rune("{"), Start: 337, End: 338},
+		{Type: Text, Value: []rune("\n  "), Start: 338, End: 341},
+		{Type: Keyword, Value: []rune("case"), Start: 341, End: 345},
+		{Type: Text, Value: []rune(" "), Start: 345, End: 346},
+		{Type: NameOther, Value: []rune("a"), Start: 346, End: 347},
+		{Type: Operator, Value: []rune(":"), Start: 347, End: 348},
+		{Type: Text, Value: []rune(" "), Start: 348, End: 349},
+		{Type: Keyword, Value: []rune("break"), Start: 349, End: 354},
+		{Type: Punctuation, Value: []rune(";"), Start: 354, End: 355},
+		{Type: Text, Value: []rune("\n"), Start: 355, End: 356},
+		{Type: Punctuation, Value: []rune("}"), Start: 356, End: 357},
+		{Type: Text, Value: []rune("\n"), Start: 357, End: 358},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestPeekAndRewind(t *testing.T) {
+	prog := "int x = 1;"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+	all := mylog.Check2(tokenize(lex.Tokenise([]rune(prog))))
+
+	it := lex.Tokenise([]rune(prog))
+
+	tok := mylog.Check2(it.Peek(0))
+	assert.Equal(all[0], tok)
+	tok = mylog.Check2(it.Peek(2))
+	assert.Equal(all[2], tok)
+
+	tok = mylog.Check2(it.Next())
+	assert.Equal(all[0], tok)
+	tok = mylog.Check2(it.Peek(0))
+	assert.Equal(all[1], tok)
+
+	// Peeking past the end returns EOF
+	tok = mylog.Check2(it.Peek(len(all) + 5))
+	assert.Equal(EOFType, tok.Type)
+
+	// Saving and restoring the state while tokens are buffered must not lose them
+	state := it.State()
+	rest := mylog.Check2(tokenize(it))
+	assert.Equal(all[1:], rest)
+
+	it.SetState(state)
+	rest = mylog.Check2(tokenize(it))
+	assert.Equal(all[1:], rest)
+
+	assert.Nil(it.Rewind(3))
+	rest = mylog.Check2(tokenize(it))
+	assert.Equal(all[len(all)-3:], rest)
+
+	assert.NotNil(it.Rewind(len(all)))
+	assert.NotNil(it.Rewind(-1))
+}
+
+func TestTokensAll(t *testing.T) {
+	prog := "int x = 1;\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	expected := mylog.Check2(tokenize(lex.Tokenise([]rune(prog))))
+
+	tokens, err := lex.TokensAll([]rune(prog))
+	assert.Nil(err)
+	assert.Equal(expected, tokens)
+
+	it := lex.Tokenise([]rune(prog))
+	mylog.Check2(it.Next())
+	tokens, err = it.Collect()
+	assert.Nil(err)
+	assert.Equal(expected[1:], tokens)
+
+	tokens = mylog.Check2(lex.TokensAll([]rune{}))
+	assert.Nil(err)
+	assert.Empty(tokens)
+}
+
+// failingSource is a tokenSource that returns its tokens and then fails.
+type failingSource struct {
+	tokens []Token
+	err    error
+}
+
+func (f *failingSource) Next() (Token, error) {
+	if len(f.tokens) == 0 {
+		return Token{}, f.err
+	}
+	tok := f.tokens[0]
+	f.tokens = f.tokens[1:]
+	return tok, nil
+}
+
+func (f *failingSource) State() IteratorState { return nil }
+
+func (f *failingSource) SetState(IteratorState) {}
+
+func TestCollectError(t *testing.T) {
+	assert := assert.New(t)
+
+	lexErr := errors.New("lexing failed")
+	partial := []Token{{Type: Keyword, Value: []rune("int"), Start: 0, End: 3}}
+
+	it := newLookahead(&failingSource{tokens: append([]Token(nil), partial...), err: lexErr}, &diagnostics{})
+	tokens, collectErr := it.Collect()
+	assert.Equal(lexErr, collectErr)
+	assert.Equal(partial, tokens)
+
+	it = newLookahead(&failingSource{err: lexErr}, &diagnostics{})
+	_, peekErr := it.Peek(0)
+	assert.Equal(lexErr, peekErr)
+}
+
+// brokenLexer returns a lexer that fails when it pushes the state "nested", which is removed after the lexer has
+// been checked.
+func brokenLexer() *Lexer {
+	lex := mylog.Check2(NewLexerFromXML(strings.NewReader(nestingLexer)))
+	delete(lex.rules.rules, "nested")
+	return lex
+}
+
+func TestTokensAllError(t *testing.T) {
+	assert := assert.New(t)
+
+	tokens, lexErr := brokenLexer().TokensAll([]rune("a (b"))
+	assert.EqualError(lexErr, "syn.iterator: a rule refers to a state nested that doesn't exist")
+	assert.Equal([]Token{{Type: Name, Value: []rune("a"), Start: 0, End: 1}}, tokens)
+}
+
+func TestLexerWith(t *testing.T) {
+	prog := "INT x;"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	ci := lex.With(WithCaseInsensitive(true), WithMatchTimeout(time.Second))
+	tokens := mylog.Check2(ci.TokensAll([]rune(prog)))
+	assert.Equal(KeywordType, tokens[0].Type)
+
+	// The original lexer is unchanged
+	tokens = mylog.Check2(lex.TokensAll([]rune(prog)))
+	assert.Equal(Name, tokens[0].Type)
+
+	tokens = mylog.Check2(lex.Clone().TokensAll([]rune(prog)))
+	assert.Equal(Name, tokens[0].Type)
+	assert.Equal(lex.cfg().Config.Name, lex.Clone().cfg().Config.Name)
+}
+
+func TestNewLexerFromXMLOptions(t *testing.T) {
+	prog := "INT x;"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml", WithCaseInsensitive(true), WithMatchTimeout(time.Second)))
+	assert.Equal(time.Second, lex.matchTimeout)
+	tokens := mylog.Check2(lex.TokensAll([]rune(prog)))
+	assert.Equal(KeywordType, tokens[0].Type)
+}
+
+// nestingLexer is a lexer definition that nests states on each opening parenthesis.
+const nestingLexer = `<lexer>
+  <config>
+    <name>nest</name>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern="\(">
+        <token type="Punctuation"/>
+        <push state="nested"/>
+      </rule>
+      <rule pattern="\w+">
+        <token type="Name"/>
+      </rule>
+    </state>
+    <state name="nested">
+      <rule pattern="\(">
+        <token type="Punctuation"/>
+        <push state="nested"/>
+      </rule>
+      <rule pattern="\)">
+        <token type="Punctuation"/>
+        <pop depth="1"/>
+      </rule>
+      <rule pattern="\w+">
+        <token type="Keyword"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>`
+
+func TestMaxStackDepth(t *testing.T) {
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXML(strings.NewReader(nestingLexer))).With(WithMaxStackDepth(3))
+
+	tokens := mylog.Check2(lex.TokensAll([]rune("((a(b")))
+
+	expected := []Token{
+		{Type: Punctuation, Value: []rune("(("), Start: 0, End: 2},
+		{Type: Keyword, Value: []rune("a"), Start: 2, End: 3},
+		{Type: Error, Value: []rune("("), Start: 3, End: 4},
+		{Type: Name, Value: []rune("b"), Start: 4, End: 5},
+	}
+
+	assert.Equal(expected, tokens)
+}
+
+func TestDiagnostics(t *testing.T) {
+	prog := "a  b\r\n!\n((c(d"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXML(strings.NewReader(nestingLexer))).With(WithMaxStackDepth(3))
+
+	it := lex.Tokenise([]rune(prog))
+	mylog.Check2(it.Collect())
+
+	expected := []Diagnostic{
+		{Kind: DiagnosticUnmatched, Offset: 1, Length: 2, Line: 0, Column: 1, State: "root"},
+		{Kind: DiagnosticUnmatched, Offset: 4, Length: 3, Line: 0, Column: 4, State: "root"},
+		{Kind: DiagnosticStackReset, Offset: 7, Length: 0, Line: 1, Column: 1, State: "root"},
+		{Kind: DiagnosticUnmatched, Offset: 7, Length: 1, Line: 1, Column: 1, State: "root"},
+		{Kind: DiagnosticStackTooDeep, Offset: 11, Length: 1, Line: 2, Column: 3, State: "nested", Rule: `\(`},
+	}
+
+	assert.Equal(expected, it.Diagnostics())
+	assert.Equal(`3:4: stack too deep in state nested (rule /\(/)`, it.Diagnostics()[4].String())
+}
+
+func TestRecovery(t *testing.T) {
+	prog := "((a !b\nc"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXML(strings.NewReader(nestingLexer)))
+
+	tokens := mylog.Check2(lex.TokensAll([]rune(prog)))
+	expected := []Token{
+		{Type: Punctuation, Value: []rune("(("), Start: 0, End: 2},
+		{Type: Keyword, Value: []rune("a"), Start: 2, End: 3},
+		{Type: Error, Value: []rune(" !"), Start: 3, End: 5},
+		{Type: Keyword, Value: []rune("b"), Start: 5, End: 6},
+		{Type: Error, Value: []rune("\n"), Start: 6, End: 7},
+		{Type: Keyword, Value: []rune("c"), Start: 7, End: 8},
+	}
+	assert.Equal(expected, tokens)
+
+	tokens = mylog.Check2(lex.TokensAll([]rune(prog), WithRecovery(RecoverSkipLine)))
+	expected = []Token{
+		{Type: Punctuation, Value: []rune("(("), Start: 0, End: 2},
+		{Type: Keyword, Value: []rune("a"), Start: 2, End: 3},
+		{Type: Error, Value: []rune(" !b\n"), Start: 3, End: 7},
+		{Type: Name, Value: []rune("c"), Start: 7, End: 8},
+	}
+	assert.Equal(expected, tokens)
+
+	tokens = mylog.Check2(lex.With(WithRecovery(RecoverPopToRoot)).TokensAll([]rune(prog)))
+	expected = []Token{
+		{Type: Punctuation, Value: []rune("(("), Start: 0, End: 2},
+		{Type: Keyword, Value: []rune("a"), Start: 2, End: 3},
+		{Type: Error, Value: []rune(" !"), Start: 3, End: 5},
+		{Type: Name, Value: []rune("b"), Start: 5, End: 6},
+		{Type: Error, Value: []rune("\n"), Start: 6, End: 7},
+		{Type: Name, Value: []rune("c"), Start: 7, End: 8},
+	}
+	assert.Equal(expected, tokens)
+}
+
+func TestEOLTokens(t *testing.T) {
+	prog := "/* a\r\n b */\r\nint x;  \n\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	tokens := mylog.Check2(lex.TokensAll([]rune(prog), WithEOLTokens(true)))
+	expected := []Token{
+		{Type: CommentMultiline, Value: []rune("/* a"), Start: 0, End: 4},
+		{Type: TextEOL, Value: []rune("\r\n"), Start: 4, End: 6},
+		{Type: CommentMultiline, Value: []rune(" b */"), Start: 6, End: 11},
+		{Type: TextEOL, Value: []rune("\r\n"), Start: 11, End: 13},
+		{Type: KeywordType, Value: []rune("int"), Start: 13, End: 16},
+		{Type: Text, Value: []rune(" "), Start: 16, End: 17},
+		{Type: Name, Value: []rune("x"), Start: 17, End: 18},
+		{Type: Punctuation, Value: []rune(";"), Start: 18, End: 19},
+		{Type: Text, Value: []rune("  "), Start: 19, End: 21},
+		{Type: TextEOL, Value: []rune("\n"), Start: 21, End: 22},
+		{Type: TextEOL, Value: []rune("\n"), Start: 22, End: 23},
+	}
+	assert.Equal(expected, tokens)
+
+	tokens = mylog.Check2(lex.TokensAll([]rune(prog)))
+	for _, tok := range tokens {
+		assert.NotEqual(TextEOL, tok.Type)
+	}
+}
+
+func TestOffsetsOnly(t *testing.T) {
+	prog := []rune("int x = 1;\r\n// done\n")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	expected := mylog.Check2(lex.TokensAll(prog))
+	tokens := mylog.Check2(lex.TokensAll(prog, WithOffsetsOnly(true)))
+
+	assert.Equal(len(expected), len(tokens))
+	for i, tok := range tokens {
+		assert.Nil(tok.Value)
+		assert.Equal(expected[i].Type, tok.Type)
+		assert.Equal(expected[i].Start, tok.Start)
+		assert.Equal(expected[i].End, tok.End)
+		assert.Equal(expected[i].Value, tok.ValueIn(prog))
+	}
+}
+
+func TestSelectionRanges(t *testing.T) {
+	prog := []rune("int f(int a) { return g(\"a (b\\n\", a[1]); }\n")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	ranges := mylog.Check2(lex.SelectionRanges(prog, 28))
+	expected := []Range{
+		{24, 29}, // "a (b
+		{24, 32}, // "a (b\n"
+		{24, 38}, // "a (b\n", a[1]
+		{23, 39}, // ("a (b\n", a[1])
+		{14, 41}, //  return g("a (b\n", a[1]);
+		{13, 42}, // { return g("a (b\n", a[1]); }
+		{0, 43},
+	}
+	assert.Equal(expected, ranges)
+
+	ranges = mylog.Check2(lex.SelectionRanges(prog, 36))
+	expected = []Range{
+		{36, 37}, // 1
+		{35, 38}, // [1]
+		{24, 38},
+		{23, 39},
+		{14, 41},
+		{13, 42},
+		{0, 43},
+	}
+	assert.Equal(expected, ranges)
+
+	ranges, selectErr := brokenLexer().SelectionRanges([]rune("a (b"), 0)
+	assert.Error(selectErr)
+	assert.Nil(ranges)
+}
+
+func TestBracketDepthFilter(t *testing.T) {
+	prog := "f(a[(1)]);}"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+	lex = lex.With(WithFilters(NewBracketDepthFilter()))
+
+	expected := []Token{
+		{Type: NameFunction, Value: []rune("f"), Start: 0, End: 1},
+		{Type: Punctuation, Value: []rune("("), Start: 1, End: 2, Meta: BracketDepth(0)},
+		{Type: Name, Value: []rune("a"), Start: 2, End: 3},
+		{Type: Punctuation, Value: []rune("["), Start: 3, End: 4, Meta: BracketDepth(1)},
+		{Type: Punctuation, Value: []rune("("), Start: 4, End: 5, Meta: BracketDepth(2)},
+		{Type: LiteralNumberInteger, Value: []rune("1"), Start: 5, End: 6},
+		{Type: Punctuation, Value: []rune(")"), Start: 6, End: 7, Meta: BracketDepth(2)},
+		{Type: Punctuation, Value: []rune("]"), Start: 7, End: 8, Meta: BracketDepth(1)},
+		{Type: Punctuation, Value: []rune(")"), Start: 8, End: 9, Meta: BracketDepth(0)},
+		{Type: Punctuation, Value: []rune(";"), Start: 9, End: 10},
+		{Type: Punctuation, Value: []rune("}"), Start: 10, End: 11, Meta: BracketDepth(-1)},
+	}
+
+	// Each Iterator uses its own clone of the filter, so lexing twice gives the same result.
+	for i := 0; i < 2; i++ {
+		tokens := mylog.Check2(lex.TokensAll([]rune(prog)))
+		assert.Equal(expected, tokens)
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	prog := "// See https://example.com/a?b=1 (or www.example.org).\ns = \"mail bob.smith@example.co.uk\";"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	tokens := mylog.Check2(lex.TokensAll([]rune(prog), WithFilters(NewLinkFilter())))
+	expected := []Token{
+		{Type: CommentSingle, Value: []rune("// See "), Start: 0, End: 7},
+		{Type: TextLink, Value: []rune("https://example.com/a?b=1"), Start: 7, End: 32, Meta: "https://example.com/a?b=1"},
+		{Type: CommentSingle, Value: []rune(" (or "), Start: 32, End: 37},
+		{Type: TextLink, Value: []rune("www.example.org"), Start: 37, End: 52, Meta: "http://www.example.org"},
+		{Type: CommentSingle, Value: []rune(").\n"), Start: 52, End: 55},
+		{Type: Name, Value: []rune("s"), Start: 55, End: 56},
+		{Type: Text, Value: []rune(" "), Start: 56, End: 57},
+		{Type: Operator, Value: []rune("="), Start: 57, End: 58},
+		{Type: Text, Value: []rune(" "), Start: 58, End: 59},
+		{Type: LiteralStringAffix, Value: []rune(""), Start: 59, End: 59},
+		{Type: LiteralString, Value: []rune("\"mail "), Start: 59, End: 65},
+		{Type: TextLink, Value: []rune("bob.smith@example.co.uk"), Start: 65, End: 88, Meta: "mailto:bob.smith@example.co.uk"},
+		{Type: LiteralString, Value: []rune("\""), Start: 88, End: 89},
+		{Type: Punctuation, Value: []rune(";"), Start: 89, End: 90},
+	}
+	assert.Equal(expected, tokens)
+}
+
+func TestColorFilter(t *testing.T) {
+	prog := "a { color: #f80; background: rgba(10, 20, 30, 50%); }\n"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/css.xml"))
+
+	tokens := mylog.Check2(lex.TokensAll([]rune(prog), WithFilters(NewColorFilter())))
+	expected := []Token{
+		{Type: NameTag, Value: []rune("a"), Start: 0, End: 1},
+		{Type: Text, Value: []rune(" "), Start: 1, End: 2},
+		{Type: Punctuation, Value: []rune("{"), Start: 2, End: 3},
+		{Type: Text, Value: []rune(" "), Start: 3, End: 4},
+		{Type: Keyword, Value: []rune("color"), Start: 4, End: 9},
+		{Type: Text, Value: []rune(""), Start: 9, End: 9},
+		{Type: Punctuation, Value: []rune(":"), Start: 9, End: 10},
+		{Type: Text, Value: []rune(" "), Start: 10, End: 11},
+		{Type: LiteralColor, Value: []rune("#f80"), Start: 11, End: 15, Meta: color.NRGBA{R: 0xff, G: 0x88, B: 0x0, A: 0xff}},
+		{Type: Punctuation, Value: []rune(";"), Start: 15, End: 16},
+		{Type: Text, Value: []rune(" "), Start: 16, End: 17},
+		{Type: Keyword, Value: []rune("background"), Start: 17, End: 27},
+		{Type: Text, Value: []rune(""), Start: 27, End: 27},
+		{Type: Punctuation, Value: []rune(":"), Start: 27, End: 28},
+		{Type: Text, Value: []rune(" "), Start: 28, End: 29},
+		{Type: LiteralColor, Value: []rune("rgba(10, 20, 30, 50%)"), Start: 29, End: 50, Meta: color.NRGBA{R: 0xa, G: 0x14, B: 0x1e, A: 0x80}},
+		{Type: Punctuation, Value: []rune(";"), Start: 50, End: 51},
+		{Type: Text, Value: []rune(" "), Start: 51, End: 52},
+		{Type: Punctuation, Value: []rune("}"), Start: 52, End: 53},
+		{Type: Text, Value: []rune("\n"), Start: 53, End: 54},
+	}
+	assert.Equal(expected, tokens)
+
+	// The tokens held while looking for the end of an unclosed function are returned at the end of the input.
+	prog = "a { color: hsl(120, 50%"
+	expected = mylog.Check2(lex.TokensAll([]rune(prog)))
+	tokens = mylog.Check2(lex.TokensAll([]rune(prog), WithFilters(NewColorFilter())))
+	assert.Equal(expected, tokens)
+}
+
+func TestSpellCheckFilter(t *testing.T) {
+	prog := "#include <teh.h>\n// recieve teh data\nteh(\"teh\");\n"
+
+	assert := assert.New(t)
+
+	check := func(text []rune, start int) (misspellings []Misspelling) {
+		for _, w := range strings.FieldsFunc(string(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if w != "teh" && w != "recieve" {
+				continue
+			}
+			i := strings.Index(string(text), w)
+			text = text[i+len(w):]
+			misspellings = append(misspellings, Misspelling{Start: start + i, End: start + i + len(w)})
+			start += i + len(w)
+		}
+		return
+	}
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	tokens := mylog.Check2(lex.TokensAll([]rune(prog), WithFilters(NewSpellCheckFilter(check))))
+	expected := []Token{
+		{Type: CommentPreproc, Value: []rune("#include"), Start: 0, End: 8},
+		{Type: Text, Value: []rune(" "), Start: 8, End: 9},
+		{Type: CommentPreprocFile, Value: []rune("<teh.h>"), Start: 9, End: 16},
+		{Type: CommentPreproc, Value: []rune("\n"), Start: 16, End: 17},
+		{Type: CommentSingle, Value: []rune("// "), Start: 17, End: 20},
+		{Type: CommentSingle, Value: []rune("recieve"), Start: 20, End: 27, Meta: Misspelling{Start: 20, End: 27}},
+		{Type: CommentSingle, Value: []rune(" "), Start: 27, End: 28},
+		{Type: CommentSingle, Value: []rune("teh"), Start: 28, End: 31, Meta: Misspelling{Start: 28, End: 31}},
+		{Type: CommentSingle, Value: []rune(" data\n"), Start: 31, End: 37},
+		{Type: NameFunction, Value: []rune("teh"), Start: 37, End: 40},
+		{Type: Punctuation, Value: []rune("("), Start: 40, End: 41},
+		{Type: LiteralStringAffix, Value: []rune(""), Start: 41, End: 41},
+		{Type: LiteralString, Value: []rune("\""), Start: 41, End: 42},
+		{Type: LiteralString, Value: []rune("teh"), Start: 42, End: 45, Meta: Misspelling{Start: 42, End: 45}},
+		{Type: LiteralString, Value: []rune("\""), Start: 45, End: 46},
+		{Type: Punctuation, Value: []rune(");"), Start: 46, End: 48},
+		{Type: Text, Value: []rune("\n"), Start: 48, End: 49},
+	}
+	assert.Equal(expected, tokens)
+}
+
+func TestSearch(t *testing.T) {
+	prog := []rune("// password: see below\npassword = get(\"password\\n\", \"pass\" \"word\");\n")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml"))
+
+	matches := mylog.Check2(lex.Search(prog, "password", ScopeStrings))
+	assert.Equal([]Range{{39, 47}}, matches)
+
+	matches = mylog.Check2(lex.Search(prog, "password", ScopeCode))
+	assert.Equal([]Range{{23, 31}}, matches)
+
+	matches = mylog.Check2(lex.Search(prog, "password", ScopeCode|ScopeComments))
+	assert.Equal([]Range{{3, 11}, {23, 31}}, matches)
+
+	tokens := mylog.Check2(lex.TokensAll(prog))
+	matches = mylog.Check2(SearchRegexp(prog, tokens, regexp2.MustCompile(`\w+\\n`, regexp2.None), ScopeAll))
+	assert.Equal([]Range{{39, 49}}, matches)
+
+	matches, searchErr := brokenLexer().Search([]rune("a (b"), "b", ScopeAll)
+	assert.Error(searchErr)
+	assert.Nil(matches)
+}
+
+func TestEditing(t *testing.T) {
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/html.xml"))
+
+	expected := Editing{
+		BlockComments:  []Delimiters{{"<!--", "-->"}},
+		Brackets:       []Delimiters{{"<", ">"}},
+		Quotes:         []string{`"`, "'"},
+		IncreaseIndent: `<(?![/!?])(?!(?i:area|base|br|col|embed|hr|img|input|link|meta|source|track|wbr)\b)[^<>]*(?<!/)>\s*$`,
+		DecreaseIndent: `^\s*</`,
+	}
+	assert.Equal(expected, lex.Editing())
+	assert.Equal(expected, lex.Config().Editing)
+
+	lex = mylog.Check2(NewLexerFromXMLFile("lexers/embedded/go.xml"))
+	assert.Equal([]string{"//"}, lex.Editing().LineComments)
+	assert.Equal([]string{`"`, "'", "`"}, lex.Editing().Quotes)
+
+	// A delegating lexer uses the editing information of its language lexer unless its config sets it.
+	del := NewDelegatingLexer(LexerConfig{Name: "Go HTML"}, lex, mylog.Check2(NewLexerFromXMLFile("lexers/embedded/html.xml")))
+	assert.Equal(expected, del.Editing())
+}
+
+func TestIndent(t *testing.T) {
+	assert := assert.New(t)
+
+	lines := func(lex *Lexer, text []rune) [][]Token {
+		var lines [][]Token
+		var line []Token
+		for _, tok := range mylog.Check2(lex.TokensAll(text)) {
+			line = append(line, tok)
+			if tok.Type == TextEOL {
+				lines = append(lines, line)
+				line = nil
+			}
+		}
+		return append(lines, line)
+	}
+
+	text := []rune("func f() {\n\tif s == \"{\" { // }\n\t\tx = 1 // {\n\t}\n}")
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/go.xml"))
+	lex = lex.With(WithEOLTokens(true))
+	l := lines(lex, text)
+
+	var indents []string
+	var outdents []bool
+	for _, line := range l {
+		indents = append(indents, lex.NextLineIndent(text, line, "\t"))
+		outdents = append(outdents, lex.ShouldOutdent(text, line))
+	}
+	assert.Equal([]string{"\t", "\t\t", "\t\t", "\t", ""}, indents)
+	assert.Equal([]bool{false, false, false, true, true}, outdents)
+
+	text = []rune("def f(x):\n    return x  # :\n")
+	lex = mylog.Check2(NewLexerFromXMLFile("lexers/embedded/python.xml"))
+	lex = lex.With(WithEOLTokens(true))
+	l = lines(lex, text)
+	assert.Equal("    ", lex.NextLineIndent(text, l[0], "    "))
+	assert.Equal("    ", lex.NextLineIndent(text, l[1], "    "))
+
+	// A lexer without indent rules keeps the indentation of the line.
+	lex = mylog.Check2(NewLexerFromXMLFile("lexers/embedded/ini.xml"))
+	text = []rune("  a = {\n")
+	assert.Equal("  ", lex.NextLineIndent(text, mylog.Check2(lex.TokensAll(text)), "\t"))
+	assert.False(lex.ShouldOutdent(text, mylog.Check2(lex.TokensAll(text))))
+}
+
+func TestEscapeFilter(t *testing.T) {
+	prog := "s := \"a\\tb %5.2f%%\\n\" + `\\t%d`"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/go.xml"))
+	filter := mylog.Check2(NewEscapeFilter(GoEscapes))
+
+	tokens := mylog.Check2(lex.TokensAll([]rune(prog), WithFilters(filter)))
+	expected := []Token{
+		{Type: NameOther, Value: []rune("s"), Start: 0, End: 1},
+		{Type: Text, Value: []rune(" "), Start: 1, End: 2},
+		{Type: Operator, Value: []rune(":="), Start: 2, End: 4},
+		{Type: Text, Value: []rune(" "), Start: 4, End: 5},
+		{Type: LiteralString, Value: []rune("\"a"), Start: 5, End: 7},
+		{Type: LiteralStringEscape, Value: []rune("\\t"), Start: 7, End: 9},
+		{Type: LiteralString, Value: []rune("b "), Start: 9, End: 11},
+		{Type: LiteralStringFormat, Value: []rune("%5.2f"), Start: 11, End: 16},
+		{Type: LiteralStringFormat, Value: []rune("%%"), Start: 16, End: 18},
+		{Type: LiteralStringEscape, Value: []rune("\\n"), Start: 18, End: 20},
+		{Type: LiteralString, Value: []rune("\""), Start: 20, End: 21},
+		{Type: Text, Value: []rune(" "), Start: 21, End: 22},
+		{Type: Operator, Value: []rune("+"), Start: 22, End: 23},
+		{Type: Text, Value: []rune(" "), Start: 23, End: 24},
+		{Type: LiteralString, Value: []rune("`\\t%d`"), Start: 24, End: 30},
+	}
+	assert.Equal(expected, tokens)
+}
+
+func TestEscapeFilterBadSyntax(t *testing.T) {
+	filter, filterErr := NewEscapeFilter(EscapeSyntax{Escapes: `\\[`})
+	assert.Error(t, filterErr)
+	assert.Nil(t, filter)
+}
+
+func TestNumberFilter(t *testing.T) {
+	prog := "0xFFL 1_000 017 1.5e3f 10L 2d"
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/csharp.xml"))
+
+	var types []TokenType
+	for _, tok := range mylog.Check2(lex.TokensAll([]rune(prog), WithFilters(NewNumberFilter()))) {
+		if tok.Type != Text {
+			types = append(types, tok.Type)
+		}
+	}
+	expected := []TokenType{
+		LiteralNumberHex,
+		LiteralNumberInteger,
+		LiteralNumberOct,
+		LiteralNumberFloat,
+		LiteralNumberIntegerLong,
+		LiteralNumberFloat,
+	}
+	assert.Equal(expected, types)
+
+	for s, typ := range map[string]TokenType{
+		"0x_FF":    LiteralNumberHex,
+		"0b1010_1": LiteralNumberBin,
+		"0o17":     LiteralNumberOct,
+		"0":        LiteralNumberInteger,
+		"089":      LiteralNumberInteger,
+		"1'000":    LiteralNumberInteger,
+		"42u64":    LiteralNumberInteger,
+		".5":       LiteralNumberFloat,
+		"1e-9":     LiteralNumberFloat,
+		"0x1.8p3":  LiteralNumberFloat,
+	} {
+		got, ok := classifyNumber(s)
+		assert.True(ok, s)
+		assert.Equal(typ, got, s)
+	}
+	for _, s := range []string{"1_", "_1", "0x", "0b2", "1.2.3", "1e", "0x1.8", "12#ff"} {
+		_, ok := classifyNumber(s)
+		assert.False(ok, s)
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		lexer      string
+		prog       string
+		doc, other []string
+	}{
+		{"java.xml", "/** Doc. */\n/* Not. */\n/**/\n", []string{"/** Doc. */"}, []string{"/* Not. */", "/**/"}},
+		{"c.xml", "/// Doc.\n//// Banner.\n/*! Doc. */\nint x;\n", []string{"/// Doc.\n", "/*! Doc. */"}, []string{"//// Banner.\n"}},
+		{"rust.xml", "//! Doc.\n/// Doc.\n// Not.\nfn f() {}\n", []string{"//! Doc.\n/// Doc.\n"}, []string{"// Not.\n"}},
+		{"r.xml", "#' Doc.\n# Not.\n", []string{"#' Doc."}, []string{"# Not."}},
+	}
+
+	for _, tc := range tests {
+		lex := mylog.Check2(NewLexerFromXMLFile("lexers/embedded/" + tc.lexer))
+		var doc, other []string
+		for _, tok := range mylog.Check2(lex.TokensAll([]rune(tc.prog))) {
+			switch {
+			case tok.Type == CommentDoc:
+				doc = append(doc, string(tok.Value))
+			case tok.Type.InCategory(Comment):
+				other = append(other, string(tok.Value))
+			}
+		}
+		assert.Equal(tc.doc, doc, tc.lexer)
+		assert.Equal(tc.other, other, tc.lexer)
+	}
+}
@@ -0,0 +1,104 @@
+package lexers
+
+import (
+	"slices"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// compositeLexers lists the lexers for literate formats, documents with source blocks and fixed-form Fortran,
+// which divide a document among lexers loaded from the embedded definitions. They are registered if all of the lexers they use are in the registry.
+var compositeLexers = []struct {
+	config  syn.LexerConfig
+	segment syn.Segmenter
+	uses    []string
+}{
+	{
+		config: syn.LexerConfig{
+			Name:    "Literate Markdown",
+			Aliases: []string{"literate-markdown", "markdown+code"},
+		},
+		segment: syn.FencedCodeSegmenter("Markdown"),
+		uses:    []string{"Markdown"},
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "Literate AsciiDoc",
+			Aliases:   []string{"literate-asciidoc", "asciidoc+code"},
+			Filenames: []string{"*.adoc", "*.asciidoc"},
+			Priority:  2,
+		},
+		segment: syn.AsciiDocSegmenter("AsciiDoc"),
+		uses:    []string{"AsciiDoc"},
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "Literate reStructuredText",
+			Aliases:   []string{"literate-rst", "rst+code"},
+			Filenames: []string{"*.rst", "*.rest"},
+			Priority:  2,
+		},
+		segment: syn.RSTSegmenter("reStructuredText"),
+		uses:    []string{"reStructuredText"},
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "Literate Org",
+			Aliases:   []string{"literate-org", "org+code"},
+			Filenames: []string{"*.org"},
+			Priority:  2,
+		},
+		segment: syn.OrgSegmenter("Org"),
+		uses:    []string{"Org"},
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "Python Percent Script",
+			Aliases:   []string{"python-percent", "pct-py"},
+			Filenames: []string{"*.pct.py"},
+			Priority:  2,
+		},
+		segment: syn.PercentCellSegmenter("Python", "Markdown", "#"),
+		uses:    []string{"Python", "Markdown"},
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "CWEB",
+			Aliases:   []string{"cweb"},
+			Filenames: []string{"*.w"},
+		},
+		segment: syn.CWEBSegmenter("TeX", "C"),
+		uses:    []string{"TeX", "C"},
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "Literate Haskell",
+			Aliases:   []string{"literate-haskell", "lhaskell", "lhs"},
+			Filenames: []string{"*.lhs"},
+			MimeTypes: []string{"text/x-literate-haskell"},
+		},
+		segment: syn.LiterateSegmenter("Haskell", "TeX"),
+		uses:    []string{"Haskell", "TeX"},
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "FortranFixed",
+			Aliases:   []string{"fortranfixed", "f77"},
+			Filenames: []string{"*.f", "*.F", "*.for", "*.FOR", "*.ftn"},
+			MimeTypes: []string{"text/x-fortran-fixed"},
+		},
+		segment: syn.FixedFormSegmenter("Fortran"),
+		uses:    []string{"Fortran"},
+	},
+}
+
+func registerCompositeLexers(reg *syn.LexerRegistry) {
+	names := reg.Names(false)
+	for _, c := range compositeLexers {
+		missing := func(name string) bool { return !slices.Contains(names, name) }
+		if slices.ContainsFunc(c.uses, missing) {
+			continue
+		}
+		reg.Register(syn.NewCompositeLexer(c.config, c.segment))
+	}
+}
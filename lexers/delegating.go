@@ -0,0 +1,73 @@
+package lexers
+
+import (
+	"slices"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// delegatingLexers lists the lexers made up of a language lexer, usually a template language, that is
+// embedded in the text handled by a root lexer. They are created from lexers loaded from the embedded
+// definitions, and are registered after them. Like the lexers in GlobalLexerRegistry, they are only created when
+// they are first used.
+var delegatingLexers = []struct {
+	config   syn.LexerConfig
+	root     string
+	language string
+}{
+	{
+		config: syn.LexerConfig{
+			Name:      "Go HTML Template",
+			Aliases:   []string{"go-html-template"},
+			Filenames: []string{"*.gohtml", "*.html.tmpl"},
+			Priority:  2,
+		},
+		root:     "HTML",
+		language: "Go Text Template",
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "HTML+Django/Jinja",
+			Aliases:   []string{"html+django", "html+jinja", "htmldjango"},
+			Filenames: []string{"*.html.j2", "*.html.jinja", "*.html.jinja2", "*.djhtml"},
+			MimeTypes: []string{"text/html+django", "text/html+jinja"},
+			Priority:  2,
+		},
+		root:     "HTML",
+		language: "Django/Jinja",
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "YAML+Jinja",
+			Aliases:   []string{"yaml+jinja", "salt", "sls"},
+			Filenames: []string{"*.sls", "*.yaml.j2", "*.yml.j2", "*.yaml.jinja2", "*.yml.jinja2"},
+			MimeTypes: []string{"text/x-yaml+jinja", "text/x-sls"},
+			Priority:  2,
+		},
+		root:     "YAML",
+		language: "Django/Jinja",
+	},
+	{
+		config: syn.LexerConfig{
+			Name:      "RHTML",
+			Aliases:   []string{"rhtml", "html+erb", "html+ruby"},
+			Filenames: []string{"*.rhtml", "*.html.erb", "*.erb"},
+			MimeTypes: []string{"text/html+ruby"},
+			Priority:  2,
+		},
+		root:     "HTML",
+		language: "ERB",
+	},
+}
+
+func registerDelegatingLexers(reg *syn.LexerRegistry) {
+	names := reg.Names(false)
+	for _, d := range delegatingLexers {
+		if !slices.Contains(names, d.root) || !slices.Contains(names, d.language) {
+			continue
+		}
+		reg.RegisterLazy(d.config, func() (*syn.Lexer, error) {
+			return syn.NewDelegatingLexer(d.config, reg.Get(d.root), reg.Get(d.language)), nil
+		})
+	}
+}
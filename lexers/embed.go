@@ -0,0 +1,8 @@
+//go:build !syn_minimal
+
+package lexers
+
+import "embed"
+
+//go:embed embedded
+var embedded embed.FS
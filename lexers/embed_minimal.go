@@ -0,0 +1,12 @@
+//go:build syn_minimal
+
+package lexers
+
+import "embed"
+
+// With the syn_minimal build tag only the definitions of a few common languages are embedded, to reduce the
+// size of programs that don't need the rest. Other definitions can be embedded by the program and loaded
+// using NewRegistry.
+//
+//go:embed embedded/c.xml embedded/go.xml embedded/markdown.xml embedded/python.xml
+var embedded embed.FS
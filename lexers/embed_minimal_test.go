@@ -0,0 +1,36 @@
+//go:build syn_minimal
+
+package lexers
+
+import (
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+)
+
+func TestMinimalLexers(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := []string{"C", "Go", "Literate Markdown", "Markdown", "Python", "Python Percent Script"}
+	assert.Equal(expected, GlobalLexerRegistry.Names(false))
+	assert.Nil(GlobalLexerRegistry.Get("Rust"))
+
+	programs := map[string]string{
+		"C":                     "int main(void) { return 0; }\n",
+		"Go":                    "package main\n\nfunc main() {}\n",
+		"Literate Markdown":     "# Title\n\n```go\npackage main\n```\n",
+		"Markdown":              "# Title\n\n*emphasis*\n",
+		"Python":                "def f(x):\n    return x + 1\n",
+		"Python Percent Script": "# %%\nx = 1\n",
+	}
+	for name, prog := range programs {
+		tokens := mylog.Check2(GlobalLexerRegistry.Get(name).TokensAll([]rune(prog)))
+		assert.NotEmpty(tokens, name)
+		for _, tok := range tokens {
+			assert.NotEqual(syn.Error, tok.Type, "%s: %v", name, tok)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+//go:build ignore
+
+// gen_metadata writes metadata.json, the index of the definitions in the embedded directory that
+// GlobalLexerRegistry is built from. Run it with go generate after changing a definition's name, aliases,
+// filenames, MIME types, priority or analyse patterns.
+package main
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/ddkwork/golibrary/mylog"
+
+	"github.com/jeffwilliams/syn"
+)
+
+func main() {
+	fsys := os.DirFS("embedded")
+	paths := mylog.Check2(fs.Glob(fsys, "*.xml"))
+
+	var index []syn.LexerMetadata
+	for _, path := range paths {
+		index = append(index, mylog.Check2(syn.LexerMetadataFromXMLFS(fsys, path)))
+	}
+
+	f := mylog.Check2(os.Create("metadata.json"))
+	defer f.Close()
+	mylog.Check(syn.WriteLexerMetadata(f, index))
+}
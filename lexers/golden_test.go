@@ -0,0 +1,30 @@
+package lexers
+
+import (
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+
+	"github.com/jeffwilliams/syn/internal/golden"
+)
+
+// TestGolden checks the token dumps in testdata, which are written by syn record. After changing a grammar on
+// purpose, rewrite the dumps of the files it affects with syn record -w and check the differences. Dumps of
+// lexers that aren't embedded, as with the syn_minimal build tag, are skipped.
+func TestGolden(t *testing.T) {
+	dumps := mylog.Check2(golden.Fixtures("testdata"))
+	for _, dump := range dumps {
+		t.Run(dump, func(t *testing.T) {
+			language, languageErr := golden.Language(dump)
+			if languageErr != nil {
+				t.Fatal(languageErr)
+			}
+			if GlobalLexerRegistry.Get(language) == nil {
+				t.Skipf("the %s lexer isn't embedded", language)
+			}
+			if verifyErr := golden.Verify(GlobalLexerRegistry, dump); verifyErr != nil {
+				t.Error(verifyErr)
+			}
+		})
+	}
+}
@@ -2,7 +2,8 @@
 package lexers
 
 import (
-	"embed"
+	"bytes"
+	_ "embed"
 	"io/fs"
 
 	"github.com/ddkwork/golibrary/mylog"
@@ -10,25 +11,101 @@ import (
 	"github.com/jeffwilliams/syn"
 )
 
-//go:embed embedded
-var embedded embed.FS
+//go:generate go run gen_metadata.go
 
-// GlobalLexerRegistry is the global LexerRegistry of Lexers.
-var GlobalLexerRegistry = func() *syn.LexerRegistry {
+// metadata is the index of the definitions in the embedded directory, written by gen_metadata.go. It must be
+// regenerated when a definition's name, aliases, filenames, MIME types, priority or analyse patterns change.
+//
+//go:embed metadata.json
+var metadata []byte
+
+// GlobalLexerRegistry is the global LexerRegistry of Lexers. Its lexers are found using an index of the embedded
+// definitions, and each is only built when it is first returned, so that programs start quickly.
+var GlobalLexerRegistry = newIndexedRegistry(Definitions(), metadata)
+
+// NewRegistry returns a LexerRegistry of the lexers defined by the XML files in the root of fsys, with the same
+// default filters as the lexers in GlobalLexerRegistry, and the delegating lexers whose lexers are among them.
+// This allows a program to embed only the definitions it needs, when built with the syn_minimal build tag so
+// that the full set isn't also embedded.
+func NewRegistry(fsys fs.FS) *syn.LexerRegistry {
 	reg := syn.NewLexerRegistry()
-	// index(reg)
-	paths := mylog.Check2(fs.Glob(embedded, "embedded/*.xml"))
+	paths := mylog.Check2(fs.Glob(fsys, "*.xml"))
 
 	for _, path := range paths {
-		//		mylog.Trace("xml path", path)
-		lex := mylog.Check2(syn.NewLexerFromXMLFS(embedded, path))
 		// TODO: save the errors here and allow retrieving them
+		m := mylog.Check2(syn.LexerMetadataFromXMLFS(fsys, path))
+		reg.Register(mylog.Check2(loadLexer(fsys, path, m.Name)))
+	}
+	registerDelegatingLexers(reg)
+	registerCompositeLexers(reg)
+	return reg
+}
 
-		reg.Register(lex)
+// newIndexedRegistry returns a LexerRegistry of the lexers described by the index in data, which are loaded from
+// fsys when they are first used. Lexers whose definitions aren't in fsys are left out.
+func newIndexedRegistry(fsys fs.FS, data []byte) *syn.LexerRegistry {
+	reg := syn.NewLexerRegistry()
+	index := mylog.Check2(syn.ReadLexerMetadata(bytes.NewReader(data)))
 
+	for _, m := range index {
+		if _, statErr := fs.Stat(fsys, m.Path); statErr != nil {
+			continue
+		}
+		path, name := m.Path, m.Name
+		reg.RegisterLazy(m.LexerConfig(), func() (*syn.Lexer, error) {
+			return loadLexer(fsys, path, name)
+		})
 	}
+	registerDelegatingLexers(reg)
+	registerCompositeLexers(reg)
 	return reg
-}()
+}
+
+// loadLexer builds the lexer named name defined at path in fsys, with its default filters.
+func loadLexer(fsys fs.FS, path, name string) (*syn.Lexer, error) {
+	var opts []syn.Option
+	if f := defaultFilters[name]; f != nil {
+		opts = append(opts, syn.WithFilters(f()...))
+	}
+	return syn.NewLexerFromXMLFS(fsys, path, opts...)
+}
+
+// ClassifyNumbers returns a copy of lex, a lexer of GlobalLexerRegistry, that also passes its tokens through the
+// filter returned by syn.NewNumberFilter, before the lexer's default filters.
+func ClassifyNumbers(lex *syn.Lexer) *syn.Lexer {
+	filters := []syn.Filter{syn.NewNumberFilter()}
+	if f := defaultFilters[lex.Config().Name]; f != nil {
+		filters = append(filters, f()...)
+	}
+	return lex.With(syn.WithFilters(filters...))
+}
+
+// defaultFilters holds, by lexer name, functions returning the filters used by default by the lexers
+// in GlobalLexerRegistry. They can be replaced by using Lexer.With.
+var defaultFilters = map[string]func() []syn.Filter{
+	"CSS":    colorFilters,
+	"SCSS":   colorFilters,
+	"Sass":   colorFilters,
+	"Stylus": colorFilters,
+
+	"C":          escapeFilters(syn.CEscapes),
+	"C++":        escapeFilters(syn.CEscapes),
+	"C#":         escapeFilters(syn.CSharpEscapes),
+	"Go":         escapeFilters(syn.GoEscapes),
+	"Java":       escapeFilters(syn.JavaEscapes),
+	"JavaScript": escapeFilters(syn.JavaScriptEscapes),
+	"TypeScript": escapeFilters(syn.JavaScriptEscapes),
+}
+
+func colorFilters() []syn.Filter {
+	return []syn.Filter{syn.NewColorFilter()}
+}
+
+func escapeFilters(syntax syn.EscapeSyntax) func() []syn.Filter {
+	return func() []syn.Filter {
+		return []syn.Filter{mylog.Check2(syn.NewEscapeFilter(syntax))}
+	}
+}
 
 // Names of all lexers, optionally including aliases.
 func Names(withAliases bool) []string {
@@ -49,3 +126,17 @@ func MatchMimeType(mimeType string) *syn.Lexer {
 func Match(filename string) *syn.Lexer {
 	return GlobalLexerRegistry.Match(filename)
 }
+
+// Analyse returns the lexer that recognises text by its content, for text whose filename is unknown or matches no
+// lexer. Returns nil when no lexer recognises it.
+func Analyse(text string) *syn.Lexer {
+	return GlobalLexerRegistry.Analyse(text)
+}
+
+// Definitions returns the file system holding the XML definitions of the lexers in GlobalLexerRegistry,
+// other than the delegating lexers, which are defined in Go. By default these are all of the definitions in the
+// embedded directory; when built with the syn_minimal build tag they are only those for C, Go, Markdown and
+// Python.
+func Definitions() fs.FS {
+	return mylog.Check2(fs.Sub(embedded, "embedded"))
+}
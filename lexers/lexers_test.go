@@ -0,0 +1,27 @@
+package lexers
+
+import (
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+)
+
+func TestClassifyNumbers(t *testing.T) {
+	assert := assert.New(t)
+
+	// The default filters are kept.
+	tokens := mylog.Check2(ClassifyNumbers(GlobalLexerRegistry.Get("C")).TokensAll([]rune(`puts("a\n");`)))
+	assert.Contains(tokens, syn.Token{Type: syn.LiteralStringEscape, Value: []rune(`\n`), Start: 7, End: 9})
+
+	cmake := GlobalLexerRegistry.Get("CMake")
+	if cmake == nil {
+		t.Skip("the CMake lexer isn't embedded")
+	}
+	prog := []rune("cmake_minimum_required(VERSION 3.20)\n")
+	version := syn.Token{Type: syn.LiteralNumberFloat, Value: []rune("3.20"), Start: 31, End: 35}
+	assert.NotContains(mylog.Check2(cmake.TokensAll(prog)), version)
+	assert.Contains(mylog.Check2(ClassifyNumbers(cmake).TokensAll(prog)), version)
+}
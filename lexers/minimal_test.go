@@ -0,0 +1,24 @@
+//go:build !syn_minimal
+
+package lexers
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// TestMinimal runs the tests of the lexers embedded with the syn_minimal build tag, so that a build with the tag
+// is checked by the usual go test.
+func TestMinimal(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building with the syn_minimal tag is slow")
+	}
+	goTool, lookErr := exec.LookPath("go")
+	if lookErr != nil {
+		t.Skip("the go command isn't available")
+	}
+	out, runErr := exec.Command(goTool, "test", "-tags", "syn_minimal", "-run", "^TestMinimalLexers$", ".").CombinedOutput()
+	if runErr != nil {
+		t.Fatalf("go test -tags syn_minimal: %v\n%s", runErr, out)
+	}
+}
@@ -0,0 +1,17 @@
+"""Starlark rules for the stock report."""
+
+def _report_impl(ctx):
+    out = ctx.actions.declare_file(ctx.label.name + ".txt")
+    lines = ["%s: %d" % (k, v) for k, v in ctx.attr.items.items() if v >= 0]
+    if not lines:
+        fail("no items in %r" % ctx.label)
+    ctx.actions.write(out, "\n".join(lines) + '\t')
+    return [DefaultInfo(files = depset([out]))]
+
+stock_report = rule(
+    implementation = _report_impl,
+    attrs = {
+        "items": attr.string_dict(),
+        "template": attr.string(default = r"\d+"),
+    },
+)
@@ -0,0 +1,50 @@
+--  Stock keeping with Ada 2012 aspects.
+pragma Ada_2012;
+with Ada.Text_IO; use Ada.Text_IO;
+with Ada.Containers.Vectors;
+
+package body Stock is
+
+   type Count is range 0 .. 16#FFFF#;
+   type Shelf is (North, South, East) with Size => 8;
+   subtype Small is Count range 0 .. 2#1010#;
+
+   type Item is record
+      Name  : String (1 .. 8) := (others => ' ');
+      Units : Count := 0;
+   end record;
+
+   Max_Units : constant := 1_000;
+   Ratio     : constant Float := 2.5E-1;
+
+   package Item_Vectors is new Ada.Containers.Vectors
+     (Index_Type => Positive, Element_Type => Item);
+
+   function Total (Items : Item_Vectors.Vector) return Count
+     with Pre => not Items.Is_Empty
+   is
+      Sum : Count := Count'First;
+   begin
+      for I of Items loop
+         Sum := Sum + I.Units;
+      end loop;
+      return Sum;
+   end Total;
+
+   procedure Report (Items : in out Item_Vectors.Vector; Verbose : Boolean := False) is
+      pragma Inline (Report);
+   begin
+      <<Again>>
+      if Items.Length > 0 and then Verbose then
+         Put_Line ("Total: " & Count'Image (Total (Items)) & " ""units""");
+      elsif Items'Length = 0 or else not Verbose then
+         null;
+      end if;
+      Outer : for S in Shelf'Range loop
+         exit Outer when S = East;
+      end loop Outer;
+   exception
+      when Constraint_Error => raise;
+   end Report;
+
+end Stock;
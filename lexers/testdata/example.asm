@@ -0,0 +1,34 @@
+; Sum the stock counts (NASM, Intel syntax).
+%define NITEMS 3
+%include "macros.inc"          ; shared helpers
+
+        bits 64
+        default rel
+        global  _start
+        extern  printf
+
+section .data
+counts: dd 3, 0x0C, 0b1010, 17o, 0FFh
+msg     db  "total: %d", 10, 0
+len     equ $ - msg
+
+section .bss
+total   resq 1
+
+%macro  exit 1
+        mov     rax, 60
+        mov     rdi, %1
+        syscall
+%endmacro
+
+section .text
+_start:
+        xor     eax, eax
+        lea     rsi, [rel counts]
+        mov     ecx, NITEMS
+.loop:  add     eax, dword [rsi + rcx*4 - 4]
+        loop    .loop
+        mov     qword [total], rax
+        movaps  xmm0, [rsi]
+        lock inc dword [rsi]
+        exit    0
@@ -0,0 +1,9 @@
+#include <stdio.h>
+
+/** The answer. */
+static const int answer = 42;
+
+int main(void) {
+	printf("%d\n", answer);
+	return 0;
+}
@@ -0,0 +1,46 @@
+// Schema for the stock service.
+package stock
+
+import (
+	"strings"
+	"list"
+)
+
+#Port: int & >0 & <=65535
+
+#Service: {
+	name!:    string & =~"^[a-z][a-z0-9-]*$"
+	image:    string | *"example/app:latest"
+	replicas: *1 | int
+	ports: [...#Port] & list.MinItems(1)
+	env?: [string]: string
+	memory:   "512Mi" | "1Gi"
+	limit:    2.5G
+	_hidden:  true
+	tier:     "web" | "worker" @go(Tier)
+	labels: {
+		app: name
+		"app.kubernetes.io/name": strings.ToLower(name)
+	} @json("labels,omitempty")
+}
+
+services: [Name=string]: #Service & {name: Name}
+
+services: web: {
+	ports: [8080, 9090]
+	env: GREETING: "hello from \(strings.ToUpper(services.web.name))"
+	let n = len(ports)
+	summary: """
+		\(n) ports
+		"quoted"
+		"""
+	pattern: #"\d+ \#(n)"#
+	mask:    0x1F
+	blob:    'raw\x00bytes'
+}
+
+for k, v in services if v.replicas > 1 {
+	"scaled-\(k)": v.replicas * 2
+}
+
+never: _|_ | null
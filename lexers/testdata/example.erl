@@ -0,0 +1,51 @@
+%% A small counter server.
+-module(counter).
+-behaviour(gen_server).
+
+-export([start_link/0, bump/1, pack/2]).
+-export_type([state/0]).
+
+-include_lib("kernel/include/logger.hrl").
+
+-define(TIMEOUT, 5000).
+-define(LOG(Fmt, Args), logger:info(Fmt, Args)).
+
+-record(state, {count = 0 :: non_neg_integer(), name = 'no name' :: atom()}).
+-type state() :: #state{}.
+-opaque token() :: {token, binary()}.
+
+-spec bump(pid()) -> {ok, integer()} | {error, timeout}.
+bump(Pid) ->
+    gen_server:call(Pid, bump, ?TIMEOUT).
+
+-spec pack(integer(), binary()) -> binary().
+pack(Version, Payload) when is_binary(Payload), Version >= 1 ->
+    Size = byte_size(Payload),
+    <<Version:8/unsigned-integer, Size:16/big, Payload/binary, 16#FF, $\n>>.
+
+start_link() ->
+    gen_server:start_link({local, ?MODULE}, ?MODULE, [], []).
+
+init([]) ->
+    ?LOG("starting ~p with timeout ~w~n", [?MODULE, ?TIMEOUT]),
+    {ok, #state{}}.
+
+handle_call(bump, _From, #state{count = N} = S) ->
+    Next = S#state{count = N + 1},
+    {reply, {ok, Next#state.count}, Next};
+handle_call(Other, _From, S) ->
+    Map = #{request => Other, at => erlang:monotonic_time()},
+    {reply, {error, Map#{seen := true}}, S}.
+
+split(<<Len:4, Rest/bits>>) ->
+    Evens = [X || X <- lists:seq(1, Len), X rem 2 =:= 0],
+    Bits = << <<B:1>> || <<B:1>> <= Rest >>,
+    F = fun lists:reverse/1,
+    G = fun(L) -> length(L) * 2.5e0 end,
+    try F(Evens) of
+        R -> {R, G(R), Bits, fun helper/0}
+    catch
+        error:Reason:Stack -> {error, Reason, Stack}
+    end.
+
+helper() -> receive stop -> ok after 100 -> 'timed out' end.
@@ -0,0 +1,71 @@
+defmodule Inventory.Item do
+  @moduledoc """
+  An item kept in stock, with #{length(@fields)} fields.
+
+      iex> Inventory.Item.new("bolt") |> Map.get(:name)
+      "bolt"
+  """
+  @moduledoc since: "1.2.0"
+
+  use GenServer
+  alias Inventory.{Store, Audit}
+  require Logger
+
+  @fields [:name, :count, :tags]
+  @default_count 0
+  @type t :: %__MODULE__{name: String.t(), count: non_neg_integer(), tags: [atom()]}
+  @enforce_keys [:name]
+  defstruct name: nil, count: @default_count, tags: []
+
+  @doc ~S"""
+  Returns a new item. Text like #{this} is not interpolated.
+  """
+  @spec new(String.t(), keyword()) :: t()
+  def new(name, opts \\ []) when is_binary(name) do
+    %__MODULE__{name: name, count: Keyword.get(opts, :count, @default_count)}
+  end
+
+  @doc false
+  defp valid?(%__MODULE__{count: count}) when count >= 0, do: true
+  defp valid?(_), do: false
+
+  def parse(line) do
+    case Regex.run(~r/^(?<name>\w+)\s*:\s*(\d+)$/iu, line) do
+      [_, name, count] -> {:ok, new(name, count: String.to_integer(count))}
+      nil -> {:error, "bad line: #{inspect(line)}"}
+    end
+  end
+
+  def labels(%{tags: tags} = item) do
+    words = ~w(fresh used returned)a
+    date = ~D[2024-01-31]
+    sigil = ~s{a "string" with #{item.name}}
+    bin = <<byte::8, rest::binary>> = <<1, 2, 3>>
+    chars = ~c'chars'
+    Enum.map(tags, &Atom.to_string/1) ++ Enum.map(words, fn w -> "#{w}" end) ++ [date, sigil, bin, rest, chars]
+  end
+
+  def restock(%__MODULE__{} = item, by \\ 1) do
+    Logger.info("restocking #{item.name} by #{by}")
+    update_in(item.count, &(&1 + by))
+  rescue
+    e in ArithmeticError -> {:error, Exception.message(e)}
+  end
+
+  defmacro log(msg) do
+    quote do: Logger.debug(unquote(msg))
+  end
+
+  @impl true
+  def handle_call({:get, key}, _from, state), do: {:reply, Map.get(state, key), state}
+  def handle_cast(:"quoted atom", state), do: {:noreply, %{state | count: 0x1F + 1_000 + 0b101 + 1.5e3}}
+  def check(x), do: x |> is_nil() |> Kernel.not() and ?a <= 97
+
+  def banner do
+    text = """
+    Stock report: #{DateTime.utc_now()}\tok
+    """
+    pattern = ~R/#{not_interpolated}\d+/
+    {text, pattern, ~W|a b c|, 'charlist #{1}', :+, :"atom #{text}"}
+  end
+end
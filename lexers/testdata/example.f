@@ -0,0 +1,18 @@
+C     Sum the stock counts, the old-fashioned way.
+* Columns 73 onwards hold sequence numbers.
+#define NITEMS 3
+      PROGRAM STOCK
+      INTEGER COUNTS(3), TOTAL, I
+      DATA COUNTS /3, 12, 0/
+      TOTAL = 0
+      DO 10 I = 1, 3
+         TOTAL = TOTAL + COUNTS(I)
+   10 CONTINUE
+      IF (TOTAL .GT. 10 .AND. .TRUE.) WRITE (*, 20) 'TOTAL',
+     &   TOTAL, 2.5D0
+   20 FORMAT (A, I5, F6.2)
+      PRINT *, 'IT''S DONE'                                             STK00010
+	PRINT *, 'TAB FORMAT',
+	1  TOTAL
+   ! An indented comment
+      END
@@ -0,0 +1,23 @@
+! Stock totals in free-form Fortran.
+module stock
+  implicit none
+  integer, parameter :: dp = kind(1.0d0)
+contains
+  pure function total(counts) result(s)
+    integer, intent(in) :: counts(:)
+    integer :: s
+    s = sum(counts)  ! the intrinsic
+  end function total
+end module stock
+
+program main
+  use stock, only: total
+  implicit none
+  integer :: counts(3) = [3, 12, 0]
+  real(dp) :: ratio = 2.5e-1_dp
+  character(len=*), parameter :: name = "shelf ""A"""
+  if (total(counts) > 10 .and. .not. .false.) then
+    print '(A, I0)', 'It''s over: ', &
+      total(counts)
+  end if
+end program main
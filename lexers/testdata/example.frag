@@ -0,0 +1,56 @@
+#version 450 core
+#extension GL_ARB_separate_shader_objects : enable
+#include "common.glsl"
+#define PI 3.14159265
+#define SQUARE(x) ((x) * \
+                   (x))
+#if defined(USE_FOG) && FOG_LEVEL > 1
+#pragma optimize(on)
+#endif
+
+/* Lighting inputs
+   from the vertex stage. */
+layout(location = 0) in vec3 fragNormal;
+layout(location = 1) in vec2 fragUV;
+layout(location = 0) out vec4 outColor;
+
+layout(set = 0, binding = 1) uniform sampler2D albedo;
+layout(std140, binding = 0) uniform Light {
+    vec3 direction;
+    float intensity;
+    mat4x3 shadowMatrix;
+} light;
+
+layout(push_constant) uniform Push { uint flags; } pc;
+layout(binding = 2, rgba8) uniform writeonly image2D target;
+
+flat in ivec2 cell;
+noperspective centroid in float depth;
+precision highp float;
+
+struct Material {
+    vec4 tint;
+    bool emissive;
+};
+
+const uint MASK = 0xFFu;
+const double EPS = 1e-6lf;
+
+float lambert(vec3 n, vec3 l) {
+    return max(dot(normalize(n), -l), 0.0);
+}
+
+void main() {
+    vec4 base = texture(albedo, fragUV) * .5f;
+    float d = lambert(fragNormal, light.direction);
+    if (gl_FrontFacing && (pc.flags & 1u) != 0u) {
+        base.rgb = mix(base.rgb, vec3(1.0), smoothstep(0.2, 0.8, d));
+    } else {
+        discard;
+    }
+    for (int i = 0; i < 4; ++i) {
+        base.a *= float(i) / 4.0;
+    }
+    imageStore(target, ivec2(gl_FragCoord.xy), base);
+    outColor = base.a > 0.5 ? base : vec4(0);
+}
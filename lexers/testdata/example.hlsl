@@ -0,0 +1,75 @@
+#include "Common.hlsli"
+#define THREADS 8
+#if defined(USE_SHADOWS) && SHADOW_TAPS > 1
+#pragma pack_matrix(row_major)
+#endif
+
+/* Per-frame constants,
+   bound to slot b0. */
+cbuffer Frame : register(b0, space1)
+{
+    float4x4 viewProj : packoffset(c0);
+    float3 lightDir   : packoffset(c4.xyz);
+    float  time       : packoffset(c4.w);
+};
+
+Texture2D<float4> albedo : register(t0);
+SamplerState linearSampler : register(s0);
+RWStructuredBuffer<uint> counters : register(u1);
+[[vk::binding(2, 0)]] Texture2D normalMap;
+
+struct VSInput
+{
+    float3 position : POSITION;
+    float2 uv       : TEXCOORD0;
+    uint   id       : SV_VertexID;
+};
+
+struct PSInput
+{
+    float4 position : SV_Position;
+    float2 uv       : TEXCOORD0;
+    nointerpolation uint material : MATERIAL;
+};
+
+PSInput VSMain(VSInput input)
+{
+    PSInput output;
+    output.position = mul(viewProj, float4(input.position, 1.0f));
+    output.uv = input.uv * 0.5h + .25;
+    output.material = input.id & 0xFFu;
+    return output;
+}
+
+[earlydepthstencil]
+float4 PSMain(PSInput input, bool front : SV_IsFrontFace) : SV_Target0
+{
+    float4 color = albedo.Sample(linearSampler, input.uv);
+    [branch] if (!front)
+    {
+        discard;
+    }
+    [unroll(4)]
+    for (int i = 0; i < 4; ++i)
+    {
+        color.rgb *= saturate(dot(lightDir, float3(0, 1, 0)));
+    }
+    return color.a > 0.5 ? color : float4(0, 0, 0, 1);
+}
+
+[RootSignature("RootFlags(0), CBV(b0)")]
+[numthreads(THREADS, THREADS, 1)]
+void CSMain(uint3 id : SV_DispatchThreadID, uint index : SV_GroupIndex)
+{
+    InterlockedAdd(counters[0], 1);
+    GroupMemoryBarrierWithGroupSync();
+}
+
+technique11 Render
+{
+    pass P0
+    {
+        SetVertexShader(CompileShader(vs_5_0, VSMain()));
+        SetPixelShader(CompileShader(ps_5_0, PSMain()));
+    }
+}
@@ -0,0 +1,54 @@
+{-# LANGUAGE LambdaCase, ScopedTypeVariables #-}
+{-# OPTIONS_GHC -Wall #-}
+-- | A small inventory of parts.
+module Inventory
+  ( Part (..)
+  , Stock
+  , restock
+  , (<+>)
+  ) where
+
+import qualified Data.Map.Strict as Map
+import Data.List (sortBy, (\\))
+import Data.Ord (comparing)
+import Control.Monad hiding (forM)
+
+{- A block comment {- with a nested one -} still
+   continues here. -}
+
+data Part = Part
+  { partName  :: !String
+  , partCount :: {-# UNPACK #-} !Int
+  } deriving (Show, Eq)
+
+newtype Stock = Stock (Map.Map String Part)
+
+infixl 6 <+>
+
+-- | Combine two stocks, adding the counts of shared parts.
+(<+>) :: Stock -> Stock -> Stock
+Stock a <+> Stock b = Stock (Map.unionWith merge a b)
+  where
+    merge p q = p { partCount = partCount p + partCount q }
+
+{-# INLINE restock #-}
+restock :: forall a. Integral a => String -> a -> Stock -> Stock
+restock name n (Stock m) =
+  Stock $ Map.insertWith (\_ old -> old { partCount = partCount old + fromIntegral n }) name (Part name (fromIntegral n)) m
+
+report :: Stock -> [String]
+report (Stock m) = map line . sortBy (comparing partName) $ Map.elems m
+  where
+    line p = partName p ++ ": " ++ show (partCount p) ++ "\t\x2713"
+
+classify :: Int -> String
+classify = \case
+  0 -> "empty"
+  n | n `mod` 2 == 0 -> "even"
+    | otherwise -> 'o' : "dd"
+
+main :: IO ()
+main = do
+  let s = restock "bolt" (1_000 :: Int) (Stock Map.empty) <+> restock "nut" 0x1F (Stock Map.empty)
+  mapM_ putStrLn (report s)
+  print ([1, 2, 3] \\ [2], 2.5e-3 :: Double, classify 3, '\n', Just 1 >>= pure . (+ 1))
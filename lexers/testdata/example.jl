@@ -0,0 +1,55 @@
+#= Inventory helpers, with a
+   #= nested =# block comment. =#
+module Stock
+
+using Printf: @printf
+import Base: show, +
+
+export Item, total
+
+"""
+    Item(name, count)
+
+A line of stock.
+"""
+struct Item{T<:Real}
+    name::String
+    count::T
+end
+
+abstract type Shelf end
+mutable struct Bin <: Shelf
+    items::Vector{Item}
+end
+
+# Multiple dispatch on the argument types.
+total(i::Item) = i.count
+total(b::Bin)::Int = sum(total, b.items; init = 0)
+total(xs::AbstractVector{<:Item}) where {T} = mapreduce(total, +, xs)
+
+function Base.show(io::IO, i::Item{T}) where {T<:Integer}
+    print(io, "$(i.name) × $(i.count) \$ ", typeof(i))
+    return nothing
+end
+
+describe(i) = """
+    Item "$(i.name)"
+    count: $(i.count)
+    """
+
+macro twice(ex)
+    return :($(esc(ex)); $(esc(ex)))
+end
+
+let b = Bin([Item("bolt", 3), Item("nut", 0x0c)])
+    @show total(b)
+    @printf("%d items\n", length(b.items))
+    @twice println('x', '\n', 1.5e-3, 1_000, 2im)
+    r = r"^bo\w+$"i
+    occursin(r, "bolt") && println(raw"C:\stock", b"bytes", `ls -l $dir`)
+    xs = [i.count for i in b.items if i.count > 0]'
+    ys = xs .+ 1 .|> sqrt
+    f = x -> x^2
+end
+
+end # module
@@ -0,0 +1,6 @@
+/** Greets name. */
+export function greet(name) {
+  const message = `hello, ${name}`;
+  console.log(message, /w+/g.test(name));
+  return 1.5e3;
+}
@@ -0,0 +1,50 @@
+@file:JvmName("Inventory")
+package com.example.stock
+
+import kotlinx.coroutines.*
+
+/** An item kept in stock. */
+data class Item(val name: String, var count: Int = 0)
+
+sealed interface Event {
+    object Empty : Event
+    data class Restocked(val item: Item, val by: Int) : Event
+}
+
+fun Item.describe(): String = "${name.uppercase()} x$count (${if (count > 0) "in stock" else "out"})"
+
+@Target(AnnotationTarget.FUNCTION)
+annotation class Audited
+
+class Store<T : Any>(private val limit: Long = 10_000L) {
+    private val items = mutableMapOf<String, Item>()
+    val size get() = items.size
+
+    @Audited
+    suspend fun restock(name: String, by: Int = 1): Event {
+        delay(100)
+        val item = items.getOrPut(name) { Item(name) }
+        item.count += by
+        return Event.Restocked(item, by)
+    }
+
+    fun report() = """
+        |Items: ${items.values.joinToString { it.describe() }}
+        |Raw $ sign and "quotes" kept: ""${'$'}x""
+    """.trimMargin()
+}
+
+fun main() = runBlocking {
+    val store = Store<Item>()
+    val (a, b) = Pair(0x1F, 0b1010)
+    val jobs = List(3) { i ->
+        launch { store.restock("bolt", i + a + b) }
+    }
+    jobs.forEach { it.join() }
+    outer@ for (c in 'a'..'c') {
+        if (c == 'b') break@outer
+    }
+    listOf(1.5e3, .25, 2f).forEach { if (it > 1) return@forEach; println(it) }
+    val obj = object : Runnable { override fun run() = println(store.report()) }
+    obj.run()
+}
@@ -0,0 +1,45 @@
+; ModuleID = 'stock.c'
+source_filename = "stock.c"
+target datalayout = "e-m:e-i64:64-i128:128-n32:64-S128"
+target triple = "aarch64-unknown-linux-gnu"
+
+%struct.Item = type { i32, double, [8 x i8] }
+
+@.str = private unnamed_addr constant [11 x i8] c"total: %d\0A\00", align 1
+@counts = dso_local global <4 x i32> zeroinitializer, align 16
+
+; Function Attrs: nounwind uwtable
+define dso_local i32 @sum(ptr noundef %items, i32 noundef %n) #0 !dbg !10 {
+entry:
+  %cmp = icmp sgt i32 %n, 0
+  br i1 %cmp, label %loop, label %exit
+
+loop:                                             ; preds = %loop, %entry
+  %i = phi i32 [ 0, %entry ], [ %next, %loop ]
+  %acc = phi i32 [ 0, %entry ], [ %add, %loop ]
+  %p = getelementptr inbounds %struct.Item, ptr %items, i32 %i, i32 0
+  %0 = load i32, ptr %p, align 4, !tbaa !14
+  %add = add nsw i32 %acc, %0
+  %next = add nuw nsw i32 %i, 1
+  %done = icmp eq i32 %next, %n
+  br i1 %done, label %exit, label %loop, !llvm.loop !18
+
+exit:
+  %r = phi i32 [ 0, %entry ], [ %add, %loop ]
+  %1 = call i32 (ptr, ...) @printf(ptr noundef @.str, i32 noundef %r), !dbg !20
+  %f = fmul fast double 1.500000e+00, 0x3FF8000000000000
+  ret i32 %r
+}
+
+declare i32 @printf(ptr noundef, ...) #1
+
+attributes #0 = { nounwind uwtable "frame-pointer"="non-leaf" }
+attributes #1 = { "no-trapping-math"="true" }
+
+!llvm.dbg.cu = !{!0}
+!llvm.module.flags = !{!2, !3}
+!0 = distinct !DICompileUnit(language: DW_LANG_C11, file: !1, producer: "clang", emissionKind: FullDebug)
+!1 = !DIFile(filename: "stock.c", directory: "/src")
+!2 = !{i32 7, !"Dwarf Version", i32 5}
+!10 = distinct !DISubprogram(name: "sum", scope: !1, line: 3, flags: DIFlagPrototyped, spFlags: DISPFlagDefinition | DISPFlagOptimized, unit: !0)
+!20 = !DILocation(line: 7, column: 3, scope: !10)
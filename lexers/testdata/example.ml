@@ -0,0 +1,42 @@
+(** Stock keeping, with a (* nested *) comment in the docs. *)
+
+module type STORE = sig
+  type t
+  val add : t -> string -> int -> t
+end
+
+module Store : STORE = struct
+  module M = Map.Make (String)
+
+  type t = int M.t [@@deriving show]
+
+  let add s name n =
+    let old = Option.value ~default:0 (M.find_opt name s) in
+    M.add name (old + n) s
+end
+
+type event =
+  [ `Added of string * int
+  | `Removed of string
+  | `Cleared ]
+[@@deriving yojson]
+
+let describe : event -> string = function
+  | `Added (name, n) -> Printf.sprintf "added %d x %s\n" n name
+  | `Removed name -> "removed " ^ name
+  | `Cleared -> {|cleared "all" items|}
+
+let rec sum ?(acc = 0L) = function
+  | [] -> acc
+  | x :: rest -> sum ~acc:(Int64.add acc x) rest
+
+let%test "sum" = sum [ 1L; 2L; 0x10L ] = 19L
+
+let () =
+  let ratio = 2.5e-1 *. float_of_int 4 in
+  let c = '\n' and q = '\'' in
+  let open Store in
+  ignore (add (Obj.magic ()) "bolt" 3 [@warning "-8"]);
+  [%ext print_endline] (describe (`Added ("nut", 12)));
+  Printf.printf "%f %c %c\n" ratio c q;
+  List.iter (fun (e : event) -> print_endline (describe e)) [ `Cleared; `Removed "x" ]
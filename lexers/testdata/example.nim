@@ -0,0 +1,50 @@
+## A small inventory module.
+import std/[strutils, tables]
+from std/os import getEnv
+
+type
+  Item* = object
+    name*: string
+    count: int
+  Kind = enum
+    kTool, kPart
+  Shelf[T] = ref object of RootObj
+    items: seq[T]
+
+const limit {.intdefine.} = 100
+
+proc `$`*(it: Item): string =
+  result = it.name & " x" & $it.count
+
+proc restock(it: var Item, n = 1) {.inline, raises: [ValueError].} =
+  if it.count + n > limit:
+    raise newException(ValueError, "too many " & it.name)
+  inc it.count, n
+
+func double(x: int): int {.noSideEffect.} = x * 2
+
+iterator pairsOf(s: Shelf[Item]): (int, Item) =
+  for i, it in s.items:
+    yield (i, it)
+
+proc printf(fmt: cstring) {.importc: "printf", header: "<stdio.h>", varargs.}
+
+let usage = """
+Usage: stock [count]
+  Restocks every "item" by count.
+"""
+let pattern = r"\d+\s"
+let raw = fmt"""{limit} items"""
+
+var stock = initTable[string, Item]()
+stock["bolt"] = Item(name: "bolt", count: 0x1F)
+for key, it in stock.mpairs:
+  try:
+    restock(it, 3'i32)
+  except ValueError as e:
+    echo "failed: ", e.msg, '\n'
+  finally:
+    discard double(2)
+echo 1.5e3, " ", 0b1010, " ", 3.0'f32, " $$1 $1" % ["x"]
+#[ a block
+   comment ]#
@@ -0,0 +1,57 @@
+#+build linux, darwin
+package stock
+
+import "core:fmt"
+import "core:strings"
+
+/* An inventory, /* with a nested */ comment. */
+MAX_ITEMS :: 1_000
+
+Kind :: enum u8 { Tool, Part }
+
+Item :: struct {
+	name:  string,
+	count: int,
+	kind:  Kind,
+}
+
+Store :: struct($T: typeid, $N: int) {
+	items: [N]T,
+	used:  int,
+}
+
+Stock_Error :: distinct union { string }
+
+@(private)
+restock :: proc(s: ^Store($T, $N), name: string, by := 1) -> (ok: bool) #no_bounds_check {
+	for &it in s.items[:s.used] {
+		if it.name == name {
+			it.count += by
+			return true
+		}
+	}
+	if s.used >= N do return false
+	s.items[s.used] = Item{name = name, count = by}
+	s.used += 1
+	return true
+}
+
+main :: proc() {
+	s: Store(Item, 4)
+	data := #load("items.txt", string)
+	defer delete(data)
+	for line in strings.split_lines_iterator(&data) {
+		restock(&s, line) or_continue
+	}
+	x: f32 = 1.5e3
+	mask := 0x1F &~ 0b1010
+	r := 'é'
+	path := `C:\stock\data`
+	value: int = ---
+	#assert(size_of(Item) > 0)
+	switch k := s.items[0].kind; k {
+	case .Tool: fmt.println("tool")
+	case .Part: fmt.printf("part %v\n", x)
+	}
+	for i in 0..<len(s.items) { fmt.println(i, r, path, mask, value) }
+}
@@ -0,0 +1,12 @@
+import sys
+
+
+class Greeter:
+    """Greets people."""
+
+    def greet(self, name: str) -> None:
+        print(f"hello, {name}", file=sys.stderr)
+
+
+if __name__ == "__main__":
+    Greeter().greet("world")
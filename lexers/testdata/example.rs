@@ -0,0 +1,6 @@
+/// Greets name.
+fn greet(name: &str) -> usize {
+    let n = 1_000u32;
+    println!("hello, {}", name);
+    n as usize
+}
@@ -0,0 +1,26 @@
+# Sum the stock counts (GAS, AT&T syntax).
+#include "config.h"
+        .section .data
+counts: .long 3, 0x0C, 0b1010, 017
+msg:    .asciz "total: %d\n"
+        .set NITEMS, 3
+len = . - msg
+
+        .text
+        .globl main
+        .type main, @function
+main:
+        pushq   %rbp
+        movq    %rsp, %rbp          /* frame */
+        xorl    %eax, %eax
+        leaq    counts(%rip), %rsi
+        movl    $NITEMS, %ecx
+1:      addl    -4(%rsi,%rcx,4), %eax
+        loop    1b
+        movl    %eax, %esi; leaq msg(%rip), %rdi
+        call    printf@PLT
+        movl    $0x0, %eax
+        lock incl (%rsi)
+        popq    %rbp
+        ret
+        .size main, .-main
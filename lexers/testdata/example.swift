@@ -0,0 +1,48 @@
+import SwiftUI
+
+/// A counter that clamps its value.
+@propertyWrapper
+struct Clamped<Value: Comparable> {
+    private var value: Value
+    let range: ClosedRange<Value>
+
+    var wrappedValue: Value {
+        get { value }
+        set { value = min(max(newValue, range.lowerBound), range.upperBound) }
+    }
+}
+
+actor Inventory {
+    private var items: [String: Int] = [:]
+
+    func restock(_ name: String, by count: Int = 1) async throws -> Int {
+        guard count > 0 else { throw StockError.invalid(count) }
+        items[name, default: 0] += count
+        return items[name]!
+    }
+}
+
+@available(iOS 15, macOS 12, *)
+struct CounterView: View {
+    @State private var count = 0
+    @Binding var limit: Int
+
+    var body: some View {
+        Stepper("Count: \(count) of \(String(limit * 2))", value: $count, in: 0...10)
+    }
+}
+
+@MainActor
+func report(_ inv: Inventory) async {
+    let n = try? await inv.restock("bolt", by: 0x1F)
+    let usage = """
+        Usage: stock "name" [count]
+          restocked \(n ?? 0) items\
+        """
+    let pattern = #"\d+\s"#
+    let raw = #"count: \#(n ?? 0) \n"#
+    /* outer /* nested */ still a comment */
+    if #available(iOS 16, *) {
+        print(usage, pattern, raw, 1.5e3, 0b1010, 1_000, $0)
+    }
+}
@@ -0,0 +1,51 @@
+# Web tier.
+terraform {
+  required_version = ">= 1.5"
+}
+
+variable "instance_count" {
+  type    = number
+  default = 2
+}
+
+locals {
+  tags = { Name = "web-${var.env}", Owner = "ops" }
+  ids  = [for i, s in aws_subnet.private : s.id if i < 3]
+  zones = {
+    for z in data.aws_availability_zones.all.names : z => upper(z)
+  }
+}
+
+resource "aws_instance" "web" {
+  count         = var.instance_count
+  ami           = data.aws_ami.ubuntu.id
+  instance_type = count.index == 0 ? "t3.large" : "t3.micro"
+  subnet_id     = element(local.ids, count.index)
+  tags          = merge(local.tags, { Index = "${count.index + 1}" })
+
+  /* Boot script. */
+  user_data = <<-EOT
+    #!/bin/sh
+    echo "hello from ${lookup(local.tags, "Name", "none")}"
+    %{ if var.env == "prod" ~}
+    systemctl enable monitoring
+    %{ endif ~}
+    cost=$${PRICE}
+  EOT
+
+  dynamic "ebs_block_device" {
+    for_each = var.volumes
+    content {
+      device_name = ebs_block_device.value.name
+      volume_size = ebs_block_device.value.size * 1.5
+    }
+  }
+
+  lifecycle {
+    ignore_changes = [tags["Owner"], ami]
+  }
+}
+
+output "addresses" {
+  value = aws_instance.web[*].private_ip // all of them
+}
@@ -0,0 +1,50 @@
+#import "@preview/cetz:0.2.2": canvas, draw
+#set page(paper: "a4", margin: (x: 2.5cm, y: 2cm))
+#set text(font: "Linux Libertine", size: 11pt, lang: "en")
+#show heading.where(level: 1): it => block(below: 1em, it)
+
+#let title = "Widget Service"
+#let scale(x, factor: 2) = x * factor
+
+= Introduction <intro>
+
+The *Widget Service* handles _thousands_ of requests with `O(1)` lookups,
+as @intro explains. See https://example.com/widget for details --- or not.
+Escapes like \#, \$ and \u{1F600} work. // a line comment
+
+/* A block comment
+   /* nested */ */
+
+== Math
+
+The area is $A = pi r^2$ and in display form:
+
+$ sum_(k=0)^n k = (n(n+1)) / 2 "for all" n >= 0 $
+
+- First item with #title
+- Second item: #scale(3, factor: 4)
++ Numbered step
+/ Term: its definition
+
+#if title.len() > 3 [
+  Long title: #title.
+] else [
+  Short.
+]
+
+#for i in range(3) {
+  [Item #i]
+}
+
+#figure(
+  table(
+    columns: (1fr, auto),
+    [*Option*], [*Default*],
+    [port], [8080],
+  ),
+  caption: [Default options],
+) <options>
+
+```go
+func main() {}
+```
@@ -0,0 +1,44 @@
+;; A stock counter exported to the host.
+(module
+  (import "env" "log" (func $log (param i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 16) "total:\20\u{1F4E6}\n")
+
+  (type $pair (struct (field $count (mut i32)) (field $price f64)))
+  (global $total (mut i64) (i64.const 0))
+
+  (; Sums the counts stored at $ptr.
+     (; Block comments nest. ;) ;)
+  (func $sum (export "sum") (param $ptr i32) (param $len i32) (result i32)
+    (local $acc i32)
+    (block $done
+      (loop $next
+        (br_if $done (i32.eqz (local.get $len)))
+        (local.set $acc
+          (i32.add (local.get $acc)
+                   (i32.load offset=4 align=4 (local.get $ptr))))
+        local.get $ptr
+        i32.const 8
+        i32.add
+        local.set $ptr
+        (local.set $len (i32.sub (local.get $len) (i32.const 1)))
+        (br $next)))
+    (call $log (i32.const 16) (local.get $acc))
+    (if (result i32) (i32.gt_s (local.get $acc) (i32.const 0x7fff_ffff))
+      (then (unreachable))
+      (else (local.get $acc))))
+
+  (func $scale (param f64) (result f64)
+    (f64.mul (local.get 0) (f64.const 1.5e3))
+    (drop (f32.const -nan:0x200000))
+    (v128.const i32x4 1 2 3 4)
+    f64x2.splat
+    i32x4.extract_lane 0
+    drop)
+
+  (@custom "meta" "v1")
+  (start $init)
+  (func $init (global.set $total (i64.const -1_000)))
+  (elem declare func $sum)
+  (table 2 funcref)
+  (func (result (ref null func)) (ref.func $sum)))
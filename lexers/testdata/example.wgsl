@@ -0,0 +1,64 @@
+enable f16;
+
+/* Uniforms shared by both stages.
+   /* Block comments nest. */ */
+struct Camera {
+    viewProj: mat4x4f,
+    @align(16) position: vec3<f32>,
+}
+
+struct VertexOut {
+    @builtin(position) clip: vec4f,
+    @location(0) @interpolate(flat) material: u32,
+    @location(1) uv: vec2<f32>,
+}
+
+alias Color = vec4h;
+
+@group(0) @binding(0) var<uniform> camera: Camera;
+@group(0) @binding(1) var<storage, read_write> particles: array<vec4<f32>>;
+@group(1) @binding(0) var albedo: texture_2d<f32>;
+@group(1) @binding(1) var linearSampler: sampler;
+@group(1) @binding(2) var output: texture_storage_2d<rgba8unorm, write>;
+
+var<workgroup> tile: array<f32, 64>;
+var<private> seed: u32 = 0x9E3779B9u;
+
+const PI: f32 = 3.14159;
+override blockSize = 64u;
+const_assert blockSize % 8 == 0;
+
+@vertex
+fn vs_main(@builtin(vertex_index) index: u32, @location(0) pos: vec3f) -> VertexOut {
+    var out: VertexOut;
+    let world = camera.viewProj * vec4f(pos, 1.0);
+    out.clip = world;
+    out.material = index & 0xffu;
+    out.uv = pos.xy * 0.5h + vec2(0.5);
+    return out;
+}
+
+@fragment
+fn fs_main(in: VertexOut) -> @location(0) vec4f {
+    let color = textureSample(albedo, linearSampler, in.uv);
+    if color.a < 0.1 {
+        discard;
+    }
+    return vec4f(color.rgb * cos(PI), 1e-3f);
+}
+
+@compute @workgroup_size(8, 8, 1)
+fn cs_main(@builtin(global_invocation_id) id: vec3<u32>) {
+    let p: ptr<function, u32> = &seed;
+    for (var i = 0i; i < 4; i++) {
+        *p = (*p ^ u32(i)) << 1u;
+    }
+    loop {
+        if *p > 100u { break; }
+        continuing {
+            *p += bitcast<u32>(0x1p4f);
+        }
+    }
+    workgroupBarrier();
+    textureStore(output, id.xy, vec4f(0.0));
+}
@@ -0,0 +1,41 @@
+//! A small command line tool.
+const std = @import("std");
+const Allocator = std.mem.Allocator;
+
+/// Errors returned by parse.
+pub const ParseError = error{ InvalidDigit, Overflow };
+
+const Point = struct {
+    x: i32 = 0,
+    y: u7,
+};
+
+fn parse(comptime T: type, text: []const u8) ParseError!T {
+    var result: T = 0;
+    for (text) |c| {
+        const digit = switch (c) {
+            '0'...'9' => c - '0',
+            else => return error.InvalidDigit,
+        };
+        result = std.math.mul(T, result, 10) catch return ParseError.Overflow;
+        result += @intCast(digit);
+    }
+    return result;
+}
+
+pub fn main() !void {
+    const help =
+        \\usage: tool [number]
+        \\  prints the number doubled
+    ;
+    const n = parse(u64, "42") catch |err| {
+        std.debug.print("error: {s}\n", .{@errorName(err)});
+        return err;
+    };
+    const color: enum { red, green } = .green;
+    outer: while (true) : (n += 1) {
+        if (n > 0x1F_FF) break :outer;
+    }
+    std.debug.print("{d} {any} {s}\n", .{ n * 2, color, help });
+    _ = 1.5e3 + 0o17 + 0b1010 + '\u{1F600}';
+}
@@ -0,0 +1,11 @@
+// Package hello greets.
+package hello
+
+import "fmt"
+
+const answer = 0x2A
+
+// Greet prints a greeting for name.
+func Greet(name string) {
+	fmt.Printf("hello, %s\n", name)
+}
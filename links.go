@@ -0,0 +1,68 @@
+package syn
+
+import (
+	"strings"
+
+	"github.com/dlclark/regexp2"
+)
+
+var linkPattern = regexp2.MustCompile(
+	`\b(?:(?:https?|ftp|file)://|www\.)[^\s<>"'`+"`"+`]*[^\s<>"'`+"`"+`.,;:!?)\]}]`+
+		`|\b(?:mailto:)?[\w.+-]+@[\w-]+(?:\.[\w-]+)*\.[A-Za-z]{2,}\b`,
+	regexp2.None)
+
+// NewLinkFilter returns a Filter that splits the URLs and email addresses in Comment and String tokens out
+// into tokens of type TextLink, so that they can be rendered as links. The Meta of each TextLink token is the
+// string that the link refers to: the URL, with http:// added to those starting with www., or the email
+// address with mailto: added.
+func NewLinkFilter() Filter {
+	return FilterFunc(filterLinks)
+}
+
+func filterLinks(tok Token) []Token {
+	if !tok.Type.InCategory(Comment) && !tok.Type.InSubCategory(LiteralString) {
+		return []Token{tok}
+	}
+
+	var toks []Token
+	piece := func(typ TokenType, start, end int, meta any) {
+		toks = append(toks, Token{
+			Type:  typ,
+			Value: tok.Value[start:end],
+			Start: tok.Start + start,
+			End:   tok.Start + end,
+			Meta:  meta,
+		})
+	}
+
+	last := 0
+	// The pattern has no match timeout, so matching can't fail.
+	m, _ := linkPattern.FindRunesMatch(tok.Value)
+	for ; m != nil; m, _ = linkPattern.FindNextMatch(m) {
+		if m.Index > last {
+			piece(tok.Type, last, m.Index, tok.Meta)
+		}
+		piece(TextLink, m.Index, m.Index+m.Length, linkTarget(m.String()))
+		last = m.Index + m.Length
+	}
+
+	if last == 0 {
+		return []Token{tok}
+	}
+	if last < len(tok.Value) {
+		piece(tok.Type, last, len(tok.Value), tok.Meta)
+	}
+	return toks
+}
+
+// linkTarget returns the URL that the link text refers to.
+func linkTarget(link string) string {
+	switch {
+	case strings.HasPrefix(link, "www."):
+		return "http://" + link
+	case strings.Contains(link, "://") || strings.HasPrefix(link, "mailto:"):
+		return link
+	default:
+		return "mailto:" + link
+	}
+}
@@ -0,0 +1,578 @@
+package syn
+
+import (
+	"slices"
+	"strings"
+)
+
+// lines returns the offsets of the start of each line of text, followed by len(text).
+func lines(text []rune) []int {
+	offsets := []int{0}
+	for i, r := range text {
+		if r == '\n' && i+1 < len(text) {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return append(offsets, len(text))
+}
+
+// lexerSegment returns a segment of text lexed by the lexer called name in registry, or a Text token if there
+// is no such lexer.
+func lexerSegment(registry *LexerRegistry, name string, start, end int, continues bool) Segment {
+	s := Segment{Start: start, End: end, Type: Text, Continues: continues}
+	if registry != nil && name != "" {
+		s.Lexer = registry.Get(name)
+	}
+	return s
+}
+
+// FencedCodeSegmenter returns a Segmenter for Markdown documents, which lexes the fenced code blocks whose info
+// string names a lexer in the registry, such as ```go, with that lexer, and the rest of the document with the
+// lexer called doc. The parts of the document around the code blocks are lexed as one text, so the lexer for
+// doc sees each of these code blocks as empty.
+func FencedCodeSegmenter(doc string) Segmenter {
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		var segs []Segment
+		docStart := 0
+		addDoc := func(end int) {
+			segs = append(segs, lexerSegment(registry, doc, docStart, end, len(segs) > 0))
+		}
+
+		offsets := lines(text)
+		for i := 0; i < len(offsets)-1; i++ {
+			fence, info := openingFence(string(text[offsets[i]:offsets[i+1]]))
+			if fence == "" {
+				continue
+			}
+			// Find the closing fence, or let the block run to the end of the document.
+			j := i + 1
+			for ; j < len(offsets)-1; j++ {
+				if isClosingFence(string(text[offsets[j]:offsets[j+1]]), fence) {
+					break
+				}
+			}
+			code := lexerSegment(registry, info, offsets[i+1], offsets[j], false)
+			if code.Lexer != nil {
+				addDoc(offsets[i+1])
+				segs = append(segs, code)
+				docStart = offsets[j]
+			}
+			i = j
+		}
+		addDoc(len(text))
+		return segs
+	}
+}
+
+// openingFence returns the fence, such as ``` or ~~~~, that line opens a fenced code block with, and the first
+// word of its info string.
+func openingFence(line string) (fence, info string) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return "", ""
+	}
+	n := len(trimmed) - len(strings.TrimLeft(trimmed, trimmed[:1]))
+	if n < 3 {
+		return "", ""
+	}
+	fence, rest := trimmed[:n], trimmed[n:]
+	if fence[0] == '`' && strings.Contains(rest, "`") {
+		return "", ""
+	}
+	if fields := strings.Fields(rest); len(fields) > 0 {
+		info = strings.TrimLeft(strings.Trim(fields[0], "{}"), ".")
+	}
+	return fence, info
+}
+
+// isClosingFence returns whether line closes a fenced code block opened by fence.
+func isClosingFence(line, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	rest := strings.TrimLeft(trimmed, fence[:1])
+	return len(trimmed)-len(rest) >= len(fence) && strings.TrimSpace(rest) == ""
+}
+
+// codeBlock is a block of code in a document, from the offset start to end, in the language called lang.
+type codeBlock struct {
+	start, end int
+	lang       string
+}
+
+// codeBlockSegments returns the segments of a document with the code blocks, in order, lexed with the lexers in
+// the registry called their languages and the rest of the document lexed as one text with the lexer called doc.
+// Code blocks whose language has no lexer are left to doc.
+func codeBlockSegments(text []rune, registry *LexerRegistry, doc string, blocks []codeBlock) []Segment {
+	var segs []Segment
+	docStart := 0
+	for _, b := range blocks {
+		code := lexerSegment(registry, b.lang, b.start, b.end, false)
+		if code.Lexer == nil {
+			continue
+		}
+		segs = append(segs, lexerSegment(registry, doc, docStart, b.start, len(segs) > 0))
+		segs = append(segs, code)
+		docStart = b.end
+	}
+	return append(segs, lexerSegment(registry, doc, docStart, len(text), len(segs) > 0))
+}
+
+// AsciiDocSegmenter returns a Segmenter for AsciiDoc documents, which lexes the source blocks whose language
+// names a lexer in the registry with that lexer, and the rest of the document with the lexer called doc. A
+// source block has a block attribute line such as [source,python] or [,python], optionally followed by a block
+// title, and is delimited by ---- or .... lines; without delimiters it is the paragraph that follows. Fenced
+// code blocks such as ```python are source blocks too.
+func AsciiDocSegmenter(doc string) Segmenter {
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		var blocks []codeBlock
+		offsets := lines(text)
+		n := len(offsets) - 1
+		line := func(i int) string { return string(text[offsets[i]:offsets[i+1]]) }
+
+		for i := 0; i < n; i++ {
+			if fence, info := openingFence(line(i)); fence != "" && fence[0] == '`' {
+				j := i + 1
+				for ; j < n && !isClosingFence(line(j), fence); j++ {
+				}
+				blocks = append(blocks, codeBlock{offsets[i+1], offsets[j], info})
+				i = j
+				continue
+			}
+			lang := sourceBlockLanguage(line(i))
+			if lang == "" {
+				continue
+			}
+			j := i + 1
+			for ; j < n && isBlockTitle(line(j)); j++ {
+			}
+			if j == n {
+				break
+			}
+			delim := strings.TrimRight(line(j), " \t\r\n")
+			if len(delim) < 4 || strings.Trim(delim, "-") != "" && strings.Trim(delim, ".") != "" {
+				// The source block is the paragraph up to the next blank line.
+				k := j
+				for ; k < n && strings.TrimSpace(line(k)) != ""; k++ {
+				}
+				blocks = append(blocks, codeBlock{offsets[j], offsets[k], lang})
+				i = k
+				continue
+			}
+			k := j + 1
+			for ; k < n && strings.TrimRight(line(k), " \t\r\n") != delim; k++ {
+			}
+			blocks = append(blocks, codeBlock{offsets[j+1], offsets[k], lang})
+			i = k
+		}
+		return codeBlockSegments(text, registry, doc, blocks)
+	}
+}
+
+// sourceBlockLanguage returns the language of an AsciiDoc block attribute line for a source block, such as
+// [source,python] or [source%linenums,go,indent=0], or "" if line isn't one.
+func sourceBlockLanguage(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return ""
+	}
+	attrs := strings.Split(line[1:len(line)-1], ",")
+	style, _, _ := strings.Cut(attrs[0], "%")
+	if i := strings.IndexAny(style, "#."); i >= 0 {
+		style = style[:i]
+	}
+	if len(attrs) < 2 || strings.TrimSpace(style) != "source" && strings.TrimSpace(style) != "" {
+		return ""
+	}
+	lang := strings.TrimSpace(attrs[1])
+	if strings.Contains(lang, "=") {
+		return ""
+	}
+	return lang
+}
+
+// isBlockTitle returns whether line is an AsciiDoc block title, such as .Example.
+func isBlockTitle(line string) bool {
+	return len(line) > 1 && line[0] == '.' && !strings.ContainsRune(". \t\r\n", rune(line[1]))
+}
+
+// RSTSegmenter returns a Segmenter for reStructuredText documents, which lexes the contents of code-block, code
+// and sourcecode directives whose language names a lexer in the registry with that lexer, and the rest of the
+// document with the lexer called doc. After a highlight directive, literal blocks introduced by a paragraph
+// ending in :: and code directives without a language are lexed in the language it names.
+func RSTSegmenter(doc string) Segmenter {
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		var blocks []codeBlock
+		offsets := lines(text)
+		n := len(offsets) - 1
+		line := func(i int) string { return string(text[offsets[i]:offsets[i+1]]) }
+		highlight := ""
+
+		for i := 0; i < n; i++ {
+			name, lang, indent, ok := rstDirective(line(i))
+			switch {
+			case ok && name == "highlight":
+				highlight = lang
+				continue
+			case ok && (name == "code-block" || name == "code" || name == "sourcecode"):
+				if lang == "" {
+					lang = highlight
+				}
+			case !ok && highlight != "" && strings.HasSuffix(strings.TrimRight(line(i), " \t\r\n"), "::"):
+				lang, indent = highlight, indentation(line(i))
+			default:
+				continue
+			}
+			// Skip the directive's options, then take the lines indented more than it.
+			j := i + 1
+			for ; j < n && indentation(line(j)) > indent && strings.HasPrefix(strings.TrimSpace(line(j)), ":"); j++ {
+			}
+			for ; j < n && strings.TrimSpace(line(j)) == ""; j++ {
+			}
+			end := j
+			for k := j; k < n && (strings.TrimSpace(line(k)) == "" || indentation(line(k)) > indent); k++ {
+				if strings.TrimSpace(line(k)) != "" {
+					end = k + 1
+				}
+			}
+			if end > j {
+				blocks = append(blocks, codeBlock{offsets[j], offsets[end], lang})
+				i = end - 1
+			}
+		}
+		return codeBlockSegments(text, registry, doc, blocks)
+	}
+}
+
+// rstDirective returns the name and argument of the reStructuredText directive on line, such as
+// ".. code-block:: python", and its indentation, or ok false if line isn't a directive.
+func rstDirective(line string) (name, arg string, indent int, ok bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	rest, found := strings.CutPrefix(trimmed, ".. ")
+	if !found {
+		return "", "", 0, false
+	}
+	name, arg, found = strings.Cut(strings.TrimLeft(rest, " \t"), "::")
+	if !found || name == "" || strings.ContainsAny(name, " \t|") {
+		return "", "", 0, false
+	}
+	return name, strings.TrimSpace(arg), len(line) - len(trimmed), true
+}
+
+// indentation returns the number of spaces and tabs at the start of line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// OrgSegmenter returns a Segmenter for Org documents, which lexes the source blocks between #+BEGIN_SRC and
+// #+END_SRC lines whose language, the first word after #+BEGIN_SRC, names a lexer in the registry with that
+// lexer, and the rest of the document with the lexer called doc. The block lines are matched regardless of case.
+func OrgSegmenter(doc string) Segmenter {
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		var blocks []codeBlock
+		offsets := lines(text)
+		n := len(offsets) - 1
+		line := func(i int) string { return string(text[offsets[i]:offsets[i+1]]) }
+
+		for i := 0; i < n; i++ {
+			fields := strings.Fields(line(i))
+			if len(fields) < 2 || !strings.EqualFold(fields[0], "#+begin_src") {
+				continue
+			}
+			j := i + 1
+			for ; j < n; j++ {
+				if f := strings.Fields(line(j)); len(f) > 0 && strings.EqualFold(f[0], "#+end_src") {
+					break
+				}
+			}
+			blocks = append(blocks, codeBlock{offsets[i+1], offsets[j], fields[1]})
+			i = j
+		}
+		return codeBlockSegments(text, registry, doc, blocks)
+	}
+}
+
+// PercentCellSegmenter returns a Segmenter for scripts divided into notebook cells by lines starting with a
+// comment followed by %%, such as "# %%", as used by Jupytext, VS Code and Spyder. The cell markers are returned
+// as CommentSpecial tokens. Code cells are lexed with the lexer called code, each cell separately. The lines of
+// Markdown cells, whose marker contains [markdown] or [md], are comments: the comment prefix of each line is
+// returned as a Comment token and the rest of the cell is lexed as one text with the lexer called markdown.
+// comment is the line comment prefix of the language, such as # or //.
+func PercentCellSegmenter(code, markdown, comment string) Segmenter {
+	marker := comment + " %%"
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		var segs []Segment
+		offsets := lines(text)
+		inMarkdown, cellStart := false, 0
+		endCodeCell := func(end int) {
+			if !inMarkdown {
+				segs = append(segs, lexerSegment(registry, code, cellStart, end, false))
+			}
+		}
+
+		for i := 0; i < len(offsets)-1; i++ {
+			start, end := offsets[i], offsets[i+1]
+			line := string(text[start:end])
+			if strings.HasPrefix(line, marker) || strings.HasPrefix(line, comment+"%%") {
+				endCodeCell(start)
+				segs = append(segs, Segment{Start: start, End: end, Type: CommentSpecial})
+				inMarkdown = strings.Contains(line, "[markdown]") || strings.Contains(line, "[md]")
+				cellStart = end
+				continue
+			}
+			if !inMarkdown {
+				continue
+			}
+			body := start
+			if strings.HasPrefix(line, comment) {
+				body += len([]rune(comment))
+				if strings.HasPrefix(line[len(comment):], " ") {
+					body++
+				}
+				segs = append(segs, Segment{Start: start, End: body, Type: Comment})
+			}
+			segs = append(segs, lexerSegment(registry, markdown, body, end, start > cellStart))
+		}
+		endCodeCell(len(text))
+		return segs
+	}
+}
+
+// CWEBSegmenter returns a Segmenter for CWEB literate programs, which lexes the TeX parts of the sections with
+// the lexer called tex and the definition and code parts with the lexer called code, usually C or C++. Control
+// codes are returned as Keyword tokens, section names such as @<Print the table@> as NameLabel tokens, and
+// index entries in code as Comment tokens. Each code part is lexed separately.
+func CWEBSegmenter(tex, code string) Segmenter {
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		var segs []Segment
+		inCode, partStart, continues := false, 0, false
+		endPart := func(end int) {
+			name := tex
+			if inCode {
+				name = code
+			}
+			segs = append(segs, lexerSegment(registry, name, partStart, end, continues))
+		}
+		// control ends the current part at a control code, which is followed by code if codeAfter is set.
+		// interrupts is set if the control code is within code, which continues after it.
+		control := func(start, end int, typ TokenType, codeAfter, interrupts bool) {
+			endPart(start)
+			segs = append(segs, Segment{Start: start, End: end, Type: typ})
+			inCode, partStart, continues = codeAfter, end, interrupts
+		}
+
+		for i := 0; i+1 < len(text); i++ {
+			if text[i] != '@' {
+				continue
+			}
+			switch c := text[i+1]; {
+			case c == '@':
+				i++
+			case c == ' ' || c == '\t' || c == '\n' || c == '*':
+				control(i, i+2, Keyword, false, false)
+				i++
+			case strings.ContainsRune("cdfpsCDFPS", c):
+				control(i, i+2, Keyword, true, false)
+				i++
+			case c == '<':
+				end := indexFrom(text, i+2, "@>")
+				if end < 0 {
+					continue
+				}
+				end += 2
+				definition := end < len(text) && text[end] == '='
+				if definition {
+					end++
+				} else if !inCode {
+					// A reference to a section name in TeX text is left to the TeX lexer.
+					continue
+				}
+				control(i, end, NameLabel, true, !definition)
+				i = end - 1
+			case inCode && (c == '^' || c == '.' || c == ':'):
+				end := indexFrom(text, i+2, "@>")
+				if end < 0 {
+					continue
+				}
+				control(i, end+2, Comment, true, true)
+				i = end + 1
+			case inCode:
+				control(i, i+2, Keyword, true, true)
+				i++
+			}
+		}
+		endPart(len(text))
+		return segs
+	}
+}
+
+// indexFrom returns the index of the first occurrence of s in text at or after start, or -1.
+func indexFrom(text []rune, start int, s string) int {
+	r := []rune(s)
+	for i := start; i+len(r) <= len(text); i++ {
+		if slices.Equal(text[i:i+len(r)], r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// LiterateSegmenter returns a Segmenter for literate programs in the style of literate Haskell, which lexes the
+// code with the lexer called code as one text. If a line starts with \begin{code} the program is in LaTeX style:
+// the code is in the lines between \begin{code} and \end{code}, and the rest of the document is lexed as one
+// text with the lexer called tex. Otherwise the program uses Bird tracks: the lines starting with > are code,
+// with the > returned as a CommentSpecial token, and the other lines are returned as Text tokens.
+func LiterateSegmenter(code, tex string) Segmenter {
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		offsets := lines(text)
+		for i := 0; i < len(offsets)-1; i++ {
+			if isCodeDelimiter(text[offsets[i]:offsets[i+1]], `\begin{code}`) {
+				return latexSegments(text, offsets, registry, code, tex)
+			}
+		}
+		return birdTrackSegments(text, offsets, registry, code)
+	}
+}
+
+// isCodeDelimiter returns whether line starts with delim, such as \begin{code}.
+func isCodeDelimiter(line []rune, delim string) bool {
+	return strings.HasPrefix(string(line), delim)
+}
+
+// latexSegments divides a LaTeX-style literate program, given the offsets of its lines, between the lexers
+// called code and tex.
+func latexSegments(text []rune, offsets []int, registry *LexerRegistry, code, tex string) []Segment {
+	var segs []Segment
+	inCode, partStart, codeSeen := false, 0, false
+	for i := 0; i < len(offsets)-1; i++ {
+		start, end := offsets[i], offsets[i+1]
+		switch line := text[start:end]; {
+		case !inCode && isCodeDelimiter(line, `\begin{code}`):
+			segs = append(segs, lexerSegment(registry, tex, partStart, end, len(segs) > 0))
+			inCode, partStart = true, end
+		case inCode && isCodeDelimiter(line, `\end{code}`):
+			segs = append(segs, lexerSegment(registry, code, partStart, start, codeSeen))
+			inCode, partStart, codeSeen = false, start, true
+		}
+	}
+	if inCode {
+		return append(segs, lexerSegment(registry, code, partStart, len(text), codeSeen))
+	}
+	return append(segs, lexerSegment(registry, tex, partStart, len(text), len(segs) > 0))
+}
+
+// birdTrackSegments divides a literate program with Bird tracks, given the offsets of its lines, between the
+// lexer called code and Text tokens for the prose.
+func birdTrackSegments(text []rune, offsets []int, registry *LexerRegistry, code string) []Segment {
+	var segs []Segment
+	proseStart, codeSeen := -1, false
+	for i := 0; i < len(offsets)-1; i++ {
+		start, end := offsets[i], offsets[i+1]
+		if start == end || text[start] != '>' {
+			if proseStart < 0 {
+				proseStart = start
+			}
+			continue
+		}
+		if proseStart >= 0 {
+			segs = append(segs, Segment{Start: proseStart, End: start, Type: Text})
+			proseStart = -1
+		}
+		segs = append(segs, Segment{Start: start, End: start + 1, Type: CommentSpecial})
+		segs = append(segs, lexerSegment(registry, code, start+1, end, codeSeen))
+		codeSeen = true
+	}
+	if proseStart >= 0 {
+		segs = append(segs, Segment{Start: proseStart, End: len(text), Type: Text})
+	}
+	return segs
+}
+
+// FixedFormSegmenter returns a Segmenter for fixed-form Fortran, which lexes the statements in columns 7 to 72
+// of each line with the lexer called code as one text. Lines with C, c, * or ! in column 1, or ! after blanks in
+// the label columns, are returned as Comment tokens and lines starting with # as CommentPreproc tokens. The
+// label in columns 1 to 5 is returned as a NameLabel token, a continuation character in column 6 as a
+// GenericStrong token, and the sequence field after column 72 as a Comment token. A tab in the first six columns
+// ends the label, and a nonzero digit after it marks a continuation line.
+func FixedFormSegmenter(code string) Segmenter {
+	return func(text []rune, registry *LexerRegistry) []Segment {
+		var segs []Segment
+		codeSeen := false
+		add := func(start, end int, typ TokenType) {
+			if start < end {
+				segs = append(segs, Segment{Start: start, End: end, Type: typ})
+			}
+		}
+		addCode := func(start, end int) {
+			if start < end {
+				segs = append(segs, lexerSegment(registry, code, start, end, codeSeen))
+				codeSeen = true
+			}
+		}
+
+		offsets := lines(text)
+		for i := 0; i < len(offsets)-1; i++ {
+			start, end := offsets[i], offsets[i+1]
+			eol := end
+			if eol > start && text[eol-1] == '\n' {
+				eol--
+			}
+			line := text[start:eol]
+			if isFixedFormComment(line) {
+				add(start, end, Comment)
+				continue
+			}
+			if len(line) > 0 && line[0] == '#' {
+				add(start, end, CommentPreproc)
+				continue
+			}
+
+			labelEnd, contStart, contEnd := min(5, len(line)), min(5, len(line)), min(6, len(line))
+			if tab := slices.Index(line[:contEnd], '\t'); tab >= 0 {
+				labelEnd, contStart, contEnd = tab, tab+1, tab+1
+				if contEnd < len(line) && line[contEnd] >= '1' && line[contEnd] <= '9' {
+					contEnd++
+				}
+			}
+			labelType := Text
+			if strings.TrimSpace(string(line[:labelEnd])) != "" {
+				labelType = NameLabel
+			}
+			add(start, start+labelEnd, labelType)
+			add(start+labelEnd, start+contStart, Text)
+			contType := Text
+			if contStart < contEnd && line[contStart] != ' ' && line[contStart] != '0' {
+				contType = GenericStrong
+			}
+			add(start+contStart, start+contEnd, contType)
+
+			// Statements end at column 72, which is 66 characters after the end of the continuation field.
+			limit := start + contEnd + 66
+			if eol <= limit {
+				addCode(start+contEnd, end)
+				continue
+			}
+			addCode(start+contEnd, limit)
+			add(limit, eol, Comment)
+			addCode(eol, end)
+		}
+		return segs
+	}
+}
+
+// isFixedFormComment returns whether line is a comment line in fixed-form Fortran.
+func isFixedFormComment(line []rune) bool {
+	if len(line) > 0 && strings.ContainsRune("Cc*!", line[0]) {
+		return true
+	}
+	for i := 0; i < len(line) && i < 5; i++ {
+		if line[i] == '!' {
+			return true
+		}
+		if line[i] != ' ' {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package syn
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MaxRewind is the maximum number of tokens that an Iterator can be moved back by using Rewind.
+const MaxRewind = 256
+
+// lookahead is an Iterator decorator that implements Peek and Rewind by buffering tokens
+// read from the underlying tokenSource.
+type lookahead struct {
+	it tokenSource
+	// ahead holds tokens that have been read from it but not yet returned by Next, either
+	// because they were peeked at or because they were rewound.
+	ahead []Token
+	// history holds the most recent tokens returned by Next, oldest first, so that they can be rewound.
+	history []Token
+	diags   *diagnostics
+}
+
+func newLookahead(it tokenSource, diags *diagnostics) Iterator {
+	return &lookahead{it: it, diags: diags}
+}
+
+func (l *lookahead) Next() (tok Token, err error) {
+	if len(l.ahead) > 0 {
+		tok = l.ahead[0]
+		l.ahead = l.ahead[1:]
+	} else {
+		next, nextErr := l.it.Next()
+		if nextErr != nil {
+			return Token{}, nextErr
+		}
+		tok = next
+	}
+
+	if tok.Type != EOFType {
+		l.remember(tok)
+	}
+	return
+}
+
+func (l *lookahead) remember(tok Token) {
+	if len(l.history) == MaxRewind {
+		copy(l.history, l.history[1:])
+		l.history = l.history[:len(l.history)-1]
+	}
+	l.history = append(l.history, tok)
+}
+
+// Peek returns the token n positions after the next token without consuming any tokens. Peek(0) returns the
+// token that the next call to Next will return. If the end of the input is reached a token of type EOFType
+// is returned.
+func (l *lookahead) Peek(n int) (Token, error) {
+	if n < 0 {
+		return Token{}, fmt.Errorf("syn.Iterator.Peek: invalid negative position %d", n)
+	}
+
+	for len(l.ahead) <= n {
+		if len(l.ahead) > 0 && l.ahead[len(l.ahead)-1].Type == EOFType {
+			return l.ahead[len(l.ahead)-1], nil
+		}
+
+		tok, nextErr := l.it.Next()
+		if nextErr != nil {
+			return Token{}, nextErr
+		}
+
+		l.ahead = append(l.ahead, tok)
+	}
+
+	return l.ahead[n], nil
+}
+
+// Rewind moves the iterator back by n tokens, so that the next n calls to Next return the last n tokens
+// returned again. At most MaxRewind tokens can be rewound, and tokens returned before the last call to
+// SetState cannot be rewound.
+func (l *lookahead) Rewind(n int) error {
+	if n < 0 || n > len(l.history) {
+		return fmt.Errorf("syn.Iterator.Rewind: can't rewind %d tokens; only %d can be rewound", n, len(l.history))
+	}
+
+	i := len(l.history) - n
+	ahead := make([]Token, 0, n+len(l.ahead))
+	ahead = append(ahead, l.history[i:]...)
+	l.ahead = append(ahead, l.ahead...)
+	l.history = l.history[:i]
+	return nil
+}
+
+func (l *lookahead) Collect() (tokens []Token, err error) {
+	tokens = []Token{}
+	for {
+		tok, nextErr := l.Next()
+		if nextErr != nil {
+			return tokens, nextErr
+		}
+		if tok.Type == EOFType {
+			return tokens, nil
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func (l *lookahead) Diagnostics() []Diagnostic {
+	return l.diags.get()
+}
+
+func (l *lookahead) State() IteratorState {
+	return &lookaheadState{
+		ahead:     append([]Token(nil), l.ahead...),
+		iterState: l.it.State(),
+	}
+}
+
+func (l *lookahead) SetState(s IteratorState) {
+	state := s.(*lookaheadState)
+
+	l.ahead = append([]Token(nil), state.ahead...)
+	l.history = nil
+	l.it.SetState(state.iterState)
+}
+
+// lookaheadState is the state of a lookahead. Since the underlying iterator may have been advanced past
+// the next token by Peek or Rewind, the state includes the tokens that were buffered.
+type lookaheadState struct {
+	ahead     []Token
+	iterState IteratorState
+}
+
+func (s lookaheadState) Equal(o IteratorState) bool {
+	other, ok := o.(*lookaheadState)
+	if !ok || len(s.ahead) != len(other.ahead) {
+		return false
+	}
+
+	for i, tok := range s.ahead {
+		o := other.ahead[i]
+		if tok.Type != o.Type || tok.Start != o.Start || tok.End != o.End {
+			return false
+		}
+	}
+
+	return s.iterState.Equal(other.iterState)
+}
+
+func (s *lookaheadState) SetIndex(i int) {
+	if len(s.ahead) == 0 {
+		s.iterState.SetIndex(i)
+		return
+	}
+	s.AddToIndex(i - s.ahead[0].Start)
+}
+
+func (s *lookaheadState) AddToIndex(delta int) {
+	for i := range s.ahead {
+		s.ahead[i].Start += delta
+		s.ahead[i].End += delta
+	}
+	s.iterState.AddToIndex(delta)
+}
+
+func (s lookaheadState) String() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "lookaheadState: \n")
+	fmt.Fprintf(&buf, "  ahead: %v\n", s.ahead)
+
+	st, ok := s.iterState.(fmt.Stringer)
+	if ok {
+		fmt.Fprintf(&buf, "  iterState:\n%s", st.String())
+	}
+
+	return buf.String()
+}
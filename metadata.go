@@ -0,0 +1,69 @@
+package syn
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+
+	"github.com/ddkwork/golibrary/mylog"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+// LexerMetadata is the part of the definition of a lexer that a LexerRegistry uses to find it, with the path of
+// the XML file the lexer is defined in. An index of the lexers defined in a set of files can be written once, by
+// WriteLexerMetadata, and read when a program starts, by ReadLexerMetadata, so that the program can register the
+// lexers with RegisterLazy and only read and build the lexers it uses.
+type LexerMetadata struct {
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Filenames []string `json:"filenames,omitempty"`
+	MimeTypes []string `json:"mime_types,omitempty"`
+	Priority  float32  `json:"priority,omitempty"`
+	Analyse   *Analyse `json:"analyse,omitempty"`
+	Path      string   `json:"path"`
+}
+
+// LexerMetadataFromXMLFS returns the metadata of the lexer defined in the XML file at path in fsys.
+func LexerMetadataFromXMLFS(fsys fs.FS, path string) (LexerMetadata, error) {
+	f := mylog.Check2(fsys.Open(path))
+	defer f.Close()
+	lex := mylog.Check2(config.DecodeLexer(f))
+
+	c := lex.Config
+	return LexerMetadata{
+		Name:      c.Name,
+		Aliases:   c.Aliases,
+		Filenames: c.Filenames,
+		MimeTypes: c.MimeTypes,
+		Priority:  c.Priority,
+		Analyse:   analyseFromConfig(c.Analyse),
+		Path:      path,
+	}, nil
+}
+
+// LexerConfig returns the LexerConfig that finds the lexer described by m in a LexerRegistry.
+func (m LexerMetadata) LexerConfig() LexerConfig {
+	return LexerConfig{
+		Name:      m.Name,
+		Aliases:   m.Aliases,
+		Filenames: m.Filenames,
+		MimeTypes: m.MimeTypes,
+		Priority:  m.Priority,
+		Analyse:   m.Analyse,
+	}
+}
+
+// WriteLexerMetadata writes index to w as JSON.
+func WriteLexerMetadata(w io.Writer, index []LexerMetadata) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(index)
+}
+
+// ReadLexerMetadata reads an index written by WriteLexerMetadata.
+func ReadLexerMetadata(r io.Reader) ([]LexerMetadata, error) {
+	var index []LexerMetadata
+	mylog.Check(json.NewDecoder(r).Decode(&index))
+	return index, nil
+}
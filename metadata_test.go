@@ -0,0 +1,122 @@
+package syn
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexerMetadataIsCurrent(t *testing.T) {
+	fsys := os.DirFS("lexers/embedded")
+	paths := mylog.Check2(fs.Glob(fsys, "*.xml"))
+
+	var want []LexerMetadata
+	for _, path := range paths {
+		want = append(want, mylog.Check2(LexerMetadataFromXMLFS(fsys, path)))
+	}
+
+	f := mylog.Check2(os.Open("lexers/metadata.json"))
+	defer f.Close()
+	got := mylog.Check2(ReadLexerMetadata(f))
+
+	assert.Equal(t, want, got, "lexers/metadata.json is out of date; run go generate in the lexers directory")
+}
+
+func TestRegisterLazy(t *testing.T) {
+	assert := assert.New(t)
+
+	m := mylog.Check2(LexerMetadataFromXMLFS(os.DirFS("lexers/embedded"), "c.xml"))
+	loads := 0
+	reg := NewLexerRegistry()
+	reg.RegisterLazy(m.LexerConfig(), func() (*Lexer, error) {
+		loads++
+		return NewLexerFromXMLFile("lexers/embedded/" + m.Path)
+	})
+
+	assert.Contains(reg.Names(false), "C")
+	assert.Equal(0, loads)
+
+	lex := reg.Match("main.c")
+	assert.NotNil(lex)
+	assert.Equal(1, loads)
+	assert.Same(lex, reg.Get("c"))
+	assert.Same(lex, reg.MatchMimeType("text/x-chdr"))
+	assert.Same(lex, reg.Lexers[0])
+	assert.Equal(1, loads)
+
+	toks := mylog.Check2(tokenize(lex.Tokenise([]rune("int x;"))))
+	assert.Equal(Token{Type: KeywordType, Value: []rune("int"), Start: 0, End: 3}, toks[0])
+}
+
+func TestMatchDirectoryGlob(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := newTestRegistry("nginx.xml")
+	lex := reg.Get("nginx")
+	assert.Same(lex, reg.Match("nginx.conf"))
+	assert.Same(lex, reg.Match("/etc/nginx/nginx.conf"))
+	assert.Same(lex, reg.Match("/etc/nginx/conf.d/stock.conf"))
+	assert.Same(lex, reg.Match("/etc/nginx/sites-enabled/stock"))
+	assert.Nil(reg.Match("/etc/httpd/conf.d/stock.conf"))
+	assert.Nil(reg.Match("conf.d/stock.conf"))
+	assert.Nil(reg.Match("stock.conf"))
+}
+
+func TestAnalyse(t *testing.T) {
+	assert := assert.New(t)
+
+	reg := newTestRegistry("diff.xml", "go.xml")
+	diff := reg.Get("diff")
+	patch := "diff --git a/main.go b/main.go\nindex 1b2c3d4..5e6f708 100644\n--- a/main.go\n+++ b/main.go\n" +
+		"@@ -1,3 +1,3 @@\n package main\n-var x = 1\n+var x = 2\n"
+	assert.Same(diff, reg.Analyse(patch))
+	assert.Same(diff, reg.Analyse("@@ -4 +4,2 @@\n-a\n+b\n+c\n"))
+	assert.Equal(float32(1), diff.AnalyseText(patch))
+	assert.Equal(float32(0), reg.Get("go").AnalyseText(patch))
+	assert.Nil(reg.Analyse("package main\n\nfunc main() {}\n"))
+
+	m := mylog.Check2(LexerMetadataFromXMLFS(os.DirFS("lexers/embedded"), "diff.xml"))
+	lazy := NewLexerRegistry()
+	lazy.RegisterLazy(m.LexerConfig(), func() (*Lexer, error) {
+		return NewLexerFromXMLFile("lexers/embedded/" + m.Path)
+	})
+	assert.Equal("Diff", lazy.Analyse(patch).Config().Name)
+}
+
+func analyseLexer(pattern string) string {
+	return `<lexer>
+  <config>
+    <name>Analysed</name>
+    <analyse>
+      <regex pattern="` + pattern + `" score="1.0"/>
+    </analyse>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern=".">
+        <token type="Text"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>`
+}
+
+func TestAnalyseBadPattern(t *testing.T) {
+	_, buildErr := NewLexerFromXML(strings.NewReader(analyseLexer(`(a`)))
+	assert.ErrorContains(t, buildErr, "analyse pattern 0")
+}
+
+func TestAnalyseTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXML(strings.NewReader(analyseLexer(`^(a+)+$`))))
+	assert.Equal(float32(1), lex.AnalyseText("aaaa"))
+
+	text := strings.Repeat("a", 40) + "b"
+	assert.Equal(float32(0), lex.With(WithMatchTimeout(time.Millisecond)).AnalyseText(text))
+}
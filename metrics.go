@@ -0,0 +1,186 @@
+package syn
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Metrics receives measurements of lexing, so that services highlighting user content can monitor which
+// grammars are slow or failing. It is set on a LexerRegistry with SetMetrics, which applies to all of the
+// lexers obtained from the registry, or on a single Lexer with WithMetrics. ExpvarMetrics publishes the
+// measurements using the expvar package; other monitoring systems, such as Prometheus, can be supported
+// by implementing Metrics. Its methods may be called concurrently.
+type Metrics interface {
+	// Lexed is called when an Iterator returned by Tokenise has returned its EOFType token, or failed.
+	Lexed(stats LexStats)
+	// CacheLookup is called when the compiled rules of the lexer for language are looked up: when the lexer is
+	// returned by the registry, or when Tokenise is given options. hit is false if the rules had to be built, as
+	// they are the first time a lexer registered with RegisterLazy is returned.
+	CacheLookup(language string, hit bool)
+}
+
+// LexStats holds the measurements of lexing one text.
+type LexStats struct {
+	// Language is the name of the lexer.
+	Language string
+	// Duration is the time spent producing tokens. It doesn't include the time the caller spent between
+	// calls to the Iterator.
+	Duration time.Duration
+	// Bytes is the length of the text in UTF-8.
+	Bytes int
+	// Tokens is the number of tokens produced, not including the EOFType token.
+	Tokens int
+	// Timeouts is the number of times matching a pattern took longer than the match timeout.
+	Timeouts int
+	// Err is the error that stopped lexing, or nil if the end of the text was reached.
+	Err error
+}
+
+// WithMetrics sets the Metrics that the lexer reports to, in place of those of the registry it was obtained from.
+func WithMetrics(m Metrics) Option {
+	return func(o *lexerOptions) {
+		o.iterOpts.metrics = m
+	}
+}
+
+// SetMetrics sets the Metrics that the registry and the lexers obtained from it report to. A nil m stops
+// reporting.
+func (l *LexerRegistry) SetMetrics(m Metrics) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.metrics = m
+}
+
+func (l *LexerRegistry) getMetrics() Metrics {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.metrics
+}
+
+// metrics returns the Metrics the lexer reports to, or nil.
+func (l *Lexer) metrics() Metrics {
+	if l.iterOpts.metrics != nil {
+		return l.iterOpts.metrics
+	}
+	return l.registry.getMetrics()
+}
+
+func (l *Lexer) reportCacheLookup(hit bool) {
+	if m := l.metrics(); m != nil {
+		m.CacheLookup(l.config.Config.Name, hit)
+	}
+}
+
+// meter wraps it so that the measurements of lexing text are reported to m.
+func meter(it tokenSource, m Metrics, language string, text []rune, diags *diagnostics) tokenSource {
+	bytes := 0
+	for _, r := range text {
+		bytes += utf8.RuneLen(r)
+	}
+	return &meteredSource{it: it, metrics: m, diags: diags, stats: LexStats{Language: language, Bytes: bytes}}
+}
+
+type meteredSource struct {
+	it      tokenSource
+	metrics Metrics
+	diags   *diagnostics
+	stats   LexStats
+	done    bool
+}
+
+func (s *meteredSource) Next() (tok Token, err error) {
+	start := time.Now()
+	defer func() {
+		s.stats.Duration += time.Since(start)
+		if r := recover(); r != nil {
+			s.finish(fmt.Errorf("%v", r))
+			panic(r)
+		}
+		switch {
+		case err != nil:
+			s.finish(err)
+		case tok.Type == EOFType:
+			s.finish(nil)
+		default:
+			s.stats.Tokens++
+		}
+	}()
+	return s.it.Next()
+}
+
+// finish reports the measurements the first time the end of the text is reached or lexing fails.
+func (s *meteredSource) finish(err error) {
+	if s.done {
+		return
+	}
+	s.done = true
+	s.stats.Err = err
+	for _, d := range s.diags.get() {
+		if d.Kind == DiagnosticTimeout {
+			s.stats.Timeouts++
+		}
+	}
+	s.metrics.Lexed(s.stats)
+}
+
+func (s *meteredSource) State() IteratorState {
+	return s.it.State()
+}
+
+func (s *meteredSource) SetState(state IteratorState) {
+	s.it.SetState(state)
+}
+
+// ExpvarMetrics is a Metrics that publishes counters for each language in an expvar.Map. For each language name
+// the map holds a map with the counters lexes, errors, nanoseconds, bytes, tokens, timeouts, cache_hits and
+// cache_misses.
+type ExpvarMetrics struct {
+	mu        sync.Mutex
+	vars      *expvar.Map
+	languages map[string]*expvar.Map
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics publishing its counters as the expvar variable name. Like
+// expvar.Publish, it panics if the name is already in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{vars: expvar.NewMap(name), languages: map[string]*expvar.Map{}}
+}
+
+func (e *ExpvarMetrics) language(name string) *expvar.Map {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m := e.languages[name]
+	if m == nil {
+		m = new(expvar.Map).Init()
+		e.languages[name] = m
+		e.vars.Set(name, m)
+	}
+	return m
+}
+
+func (e *ExpvarMetrics) Lexed(stats LexStats) {
+	m := e.language(stats.Language)
+	m.Add("lexes", 1)
+	if stats.Err != nil {
+		m.Add("errors", 1)
+	}
+	m.Add("nanoseconds", int64(stats.Duration))
+	m.Add("bytes", int64(stats.Bytes))
+	m.Add("tokens", int64(stats.Tokens))
+	m.Add("timeouts", int64(stats.Timeouts))
+}
+
+func (e *ExpvarMetrics) CacheLookup(language string, hit bool) {
+	if hit {
+		e.language(language).Add("cache_hits", 1)
+	} else {
+		e.language(language).Add("cache_misses", 1)
+	}
+}
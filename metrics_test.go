@@ -0,0 +1,84 @@
+package syn
+
+import (
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMetrics struct {
+	mu     sync.Mutex
+	lexed  []LexStats
+	hits   int
+	misses int
+}
+
+func (m *testMetrics) Lexed(stats LexStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lexed = append(m.lexed, stats)
+}
+
+func (m *testMetrics) CacheLookup(language string, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &testMetrics{}
+	reg := NewLexerRegistry()
+	reg.SetMetrics(m)
+	reg.RegisterLazy(LexerConfig{Name: "C", Filenames: []string{"*.c"}}, func() (*Lexer, error) {
+		return NewLexerFromXMLFile("lexers/embedded/c.xml")
+	})
+
+	lex := reg.Match("x.c")
+	reg.Get("C")
+	assert.Equal(1, m.misses)
+	assert.Equal(1, m.hits)
+
+	toks := mylog.Check2(lex.TokensAll([]rune("int é;")))
+	if assert.Len(m.lexed, 1) {
+		stats := m.lexed[0]
+		assert.Equal("C", stats.Language)
+		assert.Equal(7, stats.Bytes)
+		assert.Equal(len(toks), stats.Tokens)
+		assert.Equal(0, stats.Timeouts)
+		assert.NoError(stats.Err)
+		assert.Greater(stats.Duration, time.Duration(0))
+	}
+
+	// Call options that change how the rules are compiled rebuild them; others reuse them.
+	mylog.Check2(lex.TokensAll([]rune("int x;"), WithEOLTokens(true)))
+	mylog.Check2(lex.TokensAll([]rune("int x;"), WithCaseInsensitive(true)))
+	assert.Equal(2, m.hits)
+	assert.Equal(2, m.misses)
+	assert.Len(m.lexed, 3)
+
+	// A lexer's own Metrics replace the registry's.
+	own := &testMetrics{}
+	mylog.Check2(lex.With(WithMetrics(own)).TokensAll([]rune("x")))
+	assert.Len(own.lexed, 1)
+	assert.Len(m.lexed, 3)
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	e := NewExpvarMetrics("syn_test")
+	e.Lexed(LexStats{Language: "Go", Duration: 5, Bytes: 10, Tokens: 3, Timeouts: 1})
+	e.CacheLookup("Go", true)
+	e.CacheLookup("Go", false)
+
+	assert.JSONEq(t, `{"Go": {"lexes": 1, "nanoseconds": 5, "bytes": 10, "tokens": 3, "timeouts": 1, "cache_hits": 1, "cache_misses": 1}}`,
+		expvar.Get("syn_test").String())
+}
@@ -0,0 +1,141 @@
+package syn
+
+import (
+	"slices"
+	"strings"
+)
+
+// NewNumberFilter returns a Filter that classifies the tokens that a grammar gives the type LiteralNumber,
+// without a more specific type, as LiteralNumberHex, LiteralNumberOct, LiteralNumberBin, LiteralNumberFloat,
+// LiteralNumberInteger or LiteralNumberIntegerLong, so that styles can distinguish them without each grammar
+// having to. The digits may be separated by _ or ', as in 1_000_000, 0x_FF and 1'000, and may be followed by a
+// type suffix such as u, L, f or i32. Tokens that are not recognized as one of these forms, such as version
+// numbers or ratios, keep the type LiteralNumber.
+//
+// The filter isn't used by default. lexers.ClassifyNumbers adds it to a lexer of the registry of the lexers
+// package, keeping the lexer's default filters.
+func NewNumberFilter() Filter {
+	return FilterFunc(filterNumber)
+}
+
+func filterNumber(tok Token) []Token {
+	if tok.Type == LiteralNumber {
+		if typ, ok := classifyNumber(string(tok.Value)); ok {
+			tok.Type = typ
+		}
+	}
+	return []Token{tok}
+}
+
+var (
+	integerSuffixes = []string{
+		"", "u", "l", "ul", "lu", "ll", "ull", "llu", "z", "uz", "n",
+		"i8", "i16", "i32", "i64", "i128", "isize", "u8", "u16", "u32", "u64", "u128", "usize",
+	}
+	floatSuffixes = []string{"f", "d", "m", "f16", "f32", "f64", "f128"}
+)
+
+// classifyNumber returns the type of the number literal s.
+func classifyNumber(s string) (TokenType, bool) {
+	s = strings.ToLower(strings.TrimLeft(s, "+-"))
+
+	base, typ := 10, LiteralNumberInteger
+	switch {
+	case strings.HasPrefix(s, "0x"):
+		base, typ = 16, LiteralNumberHex
+	case strings.HasPrefix(s, "0o"):
+		base, typ = 8, LiteralNumberOct
+	case strings.HasPrefix(s, "0b"):
+		base, typ = 2, LiteralNumberBin
+	}
+	prefixed := base != 10
+	if prefixed {
+		s = s[2:]
+	}
+
+	digits, s := scanDigits(s, base, prefixed)
+	float := false
+	if s != "" && s[0] == '.' && (base == 10 || base == 16) {
+		var frac string
+		frac, s = scanDigits(s[1:], base, false)
+		if digits == "" && frac == "" {
+			return 0, false
+		}
+		float = true
+	} else if digits == "" {
+		return 0, false
+	}
+
+	exp := byte('e')
+	if base == 16 {
+		exp = 'p'
+	}
+	hasExp := false
+	if s != "" && s[0] == exp && (base == 10 || base == 16) {
+		e := s[1:]
+		if e != "" && (e[0] == '+' || e[0] == '-') {
+			e = e[1:]
+		}
+		var d string
+		if d, s = scanDigits(e, 10, false); d == "" {
+			return 0, false
+		}
+		float, hasExp = true, true
+	}
+	if base == 16 && float && !hasExp {
+		// A hexadecimal float must have an exponent, since e is a digit.
+		return 0, false
+	}
+
+	suffix := s
+	switch {
+	case float && (suffix == "" || suffix == "l" || slices.Contains(floatSuffixes, suffix)):
+		return LiteralNumberFloat, true
+	case float:
+		return 0, false
+	case base == 10 && slices.Contains(floatSuffixes, suffix):
+		return LiteralNumberFloat, true
+	case !slices.Contains(integerSuffixes, suffix):
+		return 0, false
+	}
+
+	if base == 10 && len(digits) > 1 && digits[0] == '0' && strings.Trim(digits, "01234567") == "" {
+		typ = LiteralNumberOct
+	}
+	if typ == LiteralNumberInteger && strings.Contains(suffix, "l") {
+		typ = LiteralNumberIntegerLong
+	}
+	return typ, true
+}
+
+// scanDigits returns the digits of the given base at the start of s, with any separators removed, and the rest
+// of s. Separators are allowed between digits, and before the first digit if leading is set because it follows
+// a base prefix such as 0x.
+func scanDigits(s string, base int, leading bool) (digits, rest string) {
+	var b strings.Builder
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || c == '\'' {
+			if (b.Len() == 0 && !leading) || i+1 == len(s) || digitValue(s[i+1]) >= base {
+				break
+			}
+			continue
+		}
+		if digitValue(c) >= base {
+			break
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), s[i:]
+}
+
+func digitValue(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	}
+	return 16
+}
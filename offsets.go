@@ -3,8 +3,6 @@ package syn
 import (
 	"bytes"
 	"fmt"
-
-	"github.com/ddkwork/golibrary/mylog"
 )
 
 type offsetMap struct {
@@ -110,7 +108,7 @@ func (iter offsetIterator) transitionsEqual(o offsetIterator) bool {
 // adjustForLF is an Iterator decorator that adjusts the values of the token's Start, End and Value
 // to account for the modifications done by the function ensureLF; namely the conversion of
 // \r\n to \n.
-func adjustForLF(text []rune, it Iterator, offIt offsetIterator) Iterator {
+func adjustForLF(text []rune, it tokenSource, offIt offsetIterator) tokenSource {
 	return &offsetAdjuster{
 		text:       text,
 		it:         it,
@@ -120,31 +118,28 @@ func adjustForLF(text []rune, it Iterator, offIt offsetIterator) Iterator {
 
 type offsetAdjuster struct {
 	text       []rune
-	it         Iterator
+	it         tokenSource
 	offsetIter offsetIterator
 }
 
 func (a *offsetAdjuster) Next() (tok Token, err error) {
-	tok = mylog.Check2(a.it.Next())
-
-	if tok.Type == EOFType {
-		return
+	tok, nextErr := a.it.Next()
+	if nextErr != nil || tok.Type == EOFType {
+		return tok, nextErr
 	}
 
 	l := tok.Length()
 	tok.Start = a.offsetIter.Offset()
 	a.offsetIter.Advance(l)
 	if a.offsetIter.Offset() < tok.Start {
-		mylog.Check(fmt.Errorf("*offsetAdjuster.Next: offsetIter returned an offset that is invalid: offset is before token start. "+
+		return tok, fmt.Errorf("*offsetAdjuster.Next: offsetIter returned an offset that is invalid: offset is before token start. "+
 			"offset: %d tok: %s",
-			a.offsetIter.Offset(), tok))
-		return
+			a.offsetIter.Offset(), tok)
 	}
 	if a.offsetIter.Offset() > len(a.text) {
-		mylog.Check(fmt.Errorf("*offsetAdjuster.Next: offsetIter returned an offset that is invalid: offset is >= text length. "+
+		return tok, fmt.Errorf("*offsetAdjuster.Next: offsetIter returned an offset that is invalid: offset is >= text length. "+
 			"offset: %d tok: '%s' tok length: %d text length: %d. Transitions: %v. Text: '%s'",
-			a.offsetIter.Offset(), tok, tok.Length(), len(a.text), a.offsetIter.transitions, string(a.text)))
-		return
+			a.offsetIter.Offset(), tok, tok.Length(), len(a.text), a.offsetIter.transitions, string(a.text))
 	}
 	tok.End = a.offsetIter.Offset()
 	tok.Value = a.text[tok.Start:tok.End]
@@ -0,0 +1,25 @@
+package syn
+
+// valueDropper is a tokenSource decorator that clears the Value of the tokens read from the
+// underlying tokenSource, for the WithOffsetsOnly option.
+type valueDropper struct {
+	it tokenSource
+}
+
+func dropValues(it tokenSource) tokenSource {
+	return valueDropper{it: it}
+}
+
+func (d valueDropper) Next() (tok Token, err error) {
+	tok, nextErr := d.it.Next()
+	tok.Value = nil
+	return tok, nextErr
+}
+
+func (d valueDropper) State() IteratorState {
+	return d.it.State()
+}
+
+func (d valueDropper) SetState(s IteratorState) {
+	d.it.SetState(s)
+}
@@ -0,0 +1,170 @@
+package syn
+
+import (
+	"time"
+
+	"github.com/ddkwork/golibrary/mylog"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+// Option changes a runtime option of a Lexer created by Lexer.With.
+type Option func(o *lexerOptions)
+
+type lexerOptions struct {
+	cfg          config.Config
+	matchTimeout time.Duration
+	iterOpts     iteratorOptions
+}
+
+// WithCaseInsensitive overrides the case_insensitive flag of the lexer definition, which controls
+// whether the rules' patterns (and so keywords) are matched regardless of case.
+func WithCaseInsensitive(b bool) Option {
+	return func(o *lexerOptions) {
+		o.cfg.CaseInsensitive = b
+	}
+}
+
+// WithDotAll overrides the dot_all flag of the lexer definition, which controls whether . in the
+// rules' patterns matches newlines.
+func WithDotAll(b bool) Option {
+	return func(o *lexerOptions) {
+		o.cfg.DotAll = b
+	}
+}
+
+// WithNotMultiline overrides the not_multiline flag of the lexer definition, which controls whether
+// ^ and $ in the rules' patterns match only at the start and end of the text rather than of each line.
+func WithNotMultiline(b bool) Option {
+	return func(o *lexerOptions) {
+		o.cfg.NotMultiline = b
+	}
+}
+
+// WithMatchTimeout sets the maximum time a rule's pattern may spend matching. The default is
+// DefaultMatchTimeout.
+func WithMatchTimeout(d time.Duration) Option {
+	return func(o *lexerOptions) {
+		o.matchTimeout = d
+	}
+}
+
+// WithMaxStackDepth sets the maximum depth of the state stack. If a rule would push a state when
+// the stack is already this deep, the token produced is returned as an Error token and the stack is
+// reset to the root state, so that malformed input can't grow the stack without bound. Zero means no
+// limit. The default is DefaultMaxStackDepth.
+func WithMaxStackDepth(depth int) Option {
+	return func(o *lexerOptions) {
+		o.iterOpts.maxStackDepth = depth
+	}
+}
+
+// WithRecovery selects how the lexer recovers when no rule matches the input. The default is RecoverSkipRune.
+func WithRecovery(r Recovery) Option {
+	return func(o *lexerOptions) {
+		o.iterOpts.recovery = r
+	}
+}
+
+// WithEOLTokens controls whether each line ending (\n or \r\n) in the text is returned as a separate token
+// of type TextEOL instead of being part of the token it was matched by. This allows renderers that work a line at
+// a time to split the token stream into lines without scanning the tokens' values.
+func WithEOLTokens(b bool) Option {
+	return func(o *lexerOptions) {
+		o.iterOpts.eolTokens = b
+	}
+}
+
+// WithOffsetsOnly controls whether the tokens returned omit their Value, leaving only the Type, Start and End.
+// Consumers that only render the text can use this to avoid handling the values of tokens they don't need, and
+// use Token.ValueIn to get the value of a token from the text when it is needed.
+func WithOffsetsOnly(b bool) Option {
+	return func(o *lexerOptions) {
+		o.iterOpts.offsetsOnly = b
+	}
+}
+
+// Clone returns a copy of the lexer. The copy may be used independently of the original.
+func (l *Lexer) Clone() *Lexer {
+	return l.With()
+}
+
+// With returns a copy of the lexer with some of its runtime options changed. The receiver is not
+// modified, so this can be used to use a lexer obtained from a LexerRegistry with different options
+// without affecting other users of the registry. For delegating lexers the options are applied to both
+// of the lexers being combined, and for composite lexers to the lexers of the segments. Lexers referred to by using rules are still found in the registry
+// and so are not affected.
+func (l *Lexer) With(opts ...Option) *Lexer {
+	if l.delegation != nil {
+		return &Lexer{
+			config:   l.config,
+			registry: l.registry,
+			delegation: &delegation{
+				root:     l.delegation.root.With(opts...),
+				language: l.delegation.language.With(opts...),
+			},
+			matchTimeout: l.matchTimeout,
+			iterOpts:     l.options(opts).iterOpts,
+		}
+	}
+	if l.composition != nil {
+		return l.withComposition(opts)
+	}
+
+	o := l.options(opts)
+
+	cfg := *l.config
+	cfg.Config = o.cfg
+
+	bld := newLexerBuilder(&cfg)
+	bld.lexer.matchTimeout = o.matchTimeout
+	bld.lexer.iterOpts = o.iterOpts
+	lex := mylog.Check2(bld.Build())
+	lex.registry = l.registry
+	return lex
+}
+
+// withCallOptions returns a lexer that uses the options opts for a single call to Tokenise. If only
+// options that don't affect how the rules are built are changed, the rules are shared rather than rebuilt.
+func (l *Lexer) withCallOptions(opts []Option) *Lexer {
+	if l.delegation != nil {
+		c := *l
+		c.delegation = &delegation{
+			root:     l.delegation.root.withCallOptions(opts),
+			language: l.delegation.language.withCallOptions(opts),
+		}
+		c.iterOpts = l.options(opts).iterOpts
+		return &c
+	}
+	if l.composition != nil {
+		return l.withComposition(opts)
+	}
+
+	o := l.options(opts)
+
+	cfg := l.config.Config
+	if o.matchTimeout != l.matchTimeout || o.cfg.CaseInsensitive != cfg.CaseInsensitive ||
+		o.cfg.DotAll != cfg.DotAll || o.cfg.NotMultiline != cfg.NotMultiline {
+		c := l.With(opts...)
+		c.reportCacheLookup(false)
+		return c
+	}
+
+	c := *l
+	c.iterOpts = o.iterOpts
+	c.reportCacheLookup(true)
+	return &c
+}
+
+// options returns the lexer's options with opts applied.
+func (l *Lexer) options(opts []Option) lexerOptions {
+	o := lexerOptions{
+		cfg:          l.config.Config,
+		matchTimeout: l.matchTimeout,
+		iterOpts:     l.iterOpts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
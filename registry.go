@@ -1,11 +1,16 @@
 package syn
 
 import (
+	"fmt"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/ddkwork/golibrary/mylog"
+
+	"github.com/jeffwilliams/syn/internal/config"
 )
 
 var ignoredSuffixes = [...]string{
@@ -19,11 +24,16 @@ var ignoredSuffixes = [...]string{
 	".in",
 }
 
-// LexerRegistry is a registry of Lexers.
+// LexerRegistry is a registry of Lexers. Its methods may be called concurrently, but Lexers must not be
+// accessed directly while the registry is being changed by a Watcher or by loading a lexer registered with
+// RegisterLazy. Lexers may hold placeholders for lexers registered with RegisterLazy that have not yet been
+// loaded; these can only be used to read their Config, and Get returns the loaded lexer.
 type LexerRegistry struct {
 	Lexers  []*Lexer
+	mu      sync.RWMutex
 	byName  map[string]*Lexer
 	byAlias map[string]*Lexer
+	metrics Metrics
 }
 
 // NewLexerRegistry creates a new LexerRegistry of Lexers.
@@ -36,6 +46,9 @@ func NewLexerRegistry() *LexerRegistry {
 
 // Names of all lexers, optionally including aliases.
 func (l *LexerRegistry) Names(withAliases bool) []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
 	out := []string{}
 	for _, lexer := range l.Lexers {
 		config := lexer.cfg().Config
@@ -50,6 +63,13 @@ func (l *LexerRegistry) Names(withAliases bool) []string {
 
 // Get a Lexer by name, alias or file extension.
 func (l *LexerRegistry) Get(name string) *Lexer {
+	l.mu.RLock()
+	lexer := l.get(name)
+	l.mu.RUnlock()
+	return l.load(lexer)
+}
+
+func (l *LexerRegistry) get(name string) *Lexer {
 	if lexer := l.byName[name]; lexer != nil {
 		return lexer
 	}
@@ -65,11 +85,11 @@ func (l *LexerRegistry) Get(name string) *Lexer {
 
 	candidates := prioritisedLexers{}
 	// Try file extension.
-	if lexer := l.Match("filename." + name); lexer != nil {
+	if lexer := l.match("filename." + name); lexer != nil {
 		candidates = append(candidates, lexer)
 	}
 	// Try exact filename.
-	if lexer := l.Match(name); lexer != nil {
+	if lexer := l.match(name); lexer != nil {
 		candidates = append(candidates, lexer)
 	}
 	if len(candidates) == 0 {
@@ -81,6 +101,13 @@ func (l *LexerRegistry) Get(name string) *Lexer {
 
 // MatchMimeType attempts to find a lexer for the given MIME type.
 func (l *LexerRegistry) MatchMimeType(mimeType string) *Lexer {
+	l.mu.RLock()
+	lexer := l.matchMimeType(mimeType)
+	l.mu.RUnlock()
+	return l.load(lexer)
+}
+
+func (l *LexerRegistry) matchMimeType(mimeType string) *Lexer {
 	matched := prioritisedLexers{}
 	for _, l := range l.Lexers {
 		for _, lmt := range l.cfg().Config.MimeTypes {
@@ -96,16 +123,27 @@ func (l *LexerRegistry) MatchMimeType(mimeType string) *Lexer {
 	return nil
 }
 
-// Match returns the first lexer matching filename.
+// Match returns the first lexer matching filename, which may be a path. Filename globs containing slashes, such
+// as "nginx/conf.d/*.conf", are matched against its trailing elements and other globs against its last element.
 func (l *LexerRegistry) Match(filename string) *Lexer {
-	filename = filepath.Base(filename)
+	return l.load(l.matchLocked(filename))
+}
+
+func (l *LexerRegistry) matchLocked(filename string) *Lexer {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.match(filename)
+}
+
+func (l *LexerRegistry) match(filename string) *Lexer {
 	matched := prioritisedLexers{}
 	// First, try primary filename matches.
 	for _, lexer := range l.Lexers {
 		config := lexer.cfg().Config
 		for _, glob := range config.Filenames {
-			mylog.Check2(filepath.Match(glob, filename))
-			// nolint
+			if matchFilename(glob, filename) {
+				matched = append(matched, lexer)
+			}
 		}
 	}
 	if len(matched) > 0 {
@@ -115,9 +153,42 @@ func (l *LexerRegistry) Match(filename string) *Lexer {
 	return nil
 }
 
+// matchFilename reports whether filename matches glob, comparing as many trailing elements of filename as glob
+// has. A malformed glob matches nothing.
+func matchFilename(glob, filename string) bool {
+	if !strings.Contains(glob, "/") {
+		matched, _ := filepath.Match(glob, filepath.Base(filename))
+		return matched
+	}
+	elems := strings.Split(filepath.ToSlash(filename), "/")
+	n := strings.Count(glob, "/") + 1
+	if len(elems) < n {
+		return false
+	}
+	matched, _ := path.Match(glob, strings.Join(elems[len(elems)-n:], "/"))
+	return matched
+}
+
+// checkFilenames returns an error if one of the filename globs of cfg is malformed.
+func checkFilenames(cfg config.Config) error {
+	for _, glob := range cfg.Filenames {
+		if _, matchErr := path.Match(glob, ""); matchErr != nil {
+			return fmt.Errorf("lexer %s: malformed filename glob %q: %v", cfg.Name, glob, matchErr)
+		}
+	}
+	return nil
+}
+
 // Register a Lexer with the LexerRegistry.
 func (l *LexerRegistry) Register(lexer *Lexer) *Lexer {
-	// lexer.SetRegistry(l)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.register(lexer)
+}
+
+func (l *LexerRegistry) register(lexer *Lexer) *Lexer {
+	lexer.registry = l
 
 	config := lexer.cfg().Config
 	l.byName[config.Name] = lexer
@@ -129,3 +200,106 @@ func (l *LexerRegistry) Register(lexer *Lexer) *Lexer {
 	l.Lexers = append(l.Lexers, lexer)
 	return lexer
 }
+
+// lookup returns the lexer registered with exactly the given name.
+func (l *LexerRegistry) lookup(name string) *Lexer {
+	l.mu.RLock()
+	lexer := l.byName[name]
+	l.mu.RUnlock()
+	return l.load(lexer)
+}
+
+// replace registers lexer in place of the lexer with the same name, or as a new lexer if there is none. It
+// returns the lexer replaced.
+func (l *LexerRegistry) replace(lexer *Lexer) (old *Lexer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name := lexer.cfg().Config.Name
+	i := 0
+	for ; i < len(l.Lexers); i++ {
+		if l.Lexers[i].cfg().Config.Name == name {
+			old = l.Lexers[i]
+			break
+		}
+	}
+	if old == nil {
+		l.register(lexer)
+		return nil
+	}
+
+	for key, v := range l.byName {
+		if v == old {
+			delete(l.byName, key)
+		}
+	}
+	for key, v := range l.byAlias {
+		if v == old {
+			delete(l.byAlias, key)
+		}
+	}
+	l.register(lexer)
+	// register appended the lexer; move it to where the old one was so that the order is kept.
+	l.Lexers[i] = lexer
+	l.Lexers = l.Lexers[:len(l.Lexers)-1]
+	return old
+}
+
+// lazyLoad is set in the placeholder Lexer registered by RegisterLazy.
+type lazyLoad struct {
+	once  sync.Once
+	load  func() (*Lexer, error)
+	lexer *Lexer
+}
+
+// RegisterLazy registers a lexer that is only created, by calling load, when it is first returned by one of the
+// registry's methods. The lexer is found using cfg until then, so cfg must have the same name, aliases, filenames,
+// MIME types and priority as the lexer that load returns. This allows a program to start without building the
+// rules of all of its lexers; see LexerMetadata for a way of getting cfg for lexers defined in XML.
+func (l *LexerRegistry) RegisterLazy(cfg LexerConfig, load func() (*Lexer, error)) {
+	l.Register(&Lexer{config: cfg.toConfig(), lazy: &lazyLoad{load: load}})
+}
+
+// load returns lexer, or if it is a placeholder registered by RegisterLazy, the lexer it stands for, which is
+// created the first time and replaces the placeholder in the registry.
+func (l *LexerRegistry) load(lexer *Lexer) *Lexer {
+	if lexer == nil {
+		return nil
+	}
+	if lexer.lazy == nil {
+		lexer.reportCacheLookup(true)
+		return lexer
+	}
+
+	lz := lexer.lazy
+	built := false
+	defer func() {
+		if lz.lexer != nil {
+			lz.lexer.reportCacheLookup(!built)
+		}
+	}()
+	lz.once.Do(func() {
+		built = true
+		lz.lexer = mylog.Check2(lz.load())
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lz.lexer.registry = l
+		for key, v := range l.byName {
+			if v == lexer {
+				l.byName[key] = lz.lexer
+			}
+		}
+		for key, v := range l.byAlias {
+			if v == lexer {
+				l.byAlias[key] = lz.lexer
+			}
+		}
+		for i, v := range l.Lexers {
+			if v == lexer {
+				l.Lexers[i] = lz.lexer
+			}
+		}
+	})
+	return lz.lexer
+}
@@ -3,6 +3,7 @@ package syn
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/ddkwork/golibrary/mylog"
 	"github.com/dlclark/regexp2"
@@ -16,20 +17,23 @@ type state struct {
 	rules []rule
 }
 
-func (r state) match(text []rune) (*regexp2.Match, *rule) {
+// match returns the first rule of the state that matches text, and the match. If attempting to match
+// one of the rules fails, for example because it timed out, that rule is returned with a nil match and
+// the error.
+func (r state) match(text []rune) (*regexp2.Match, *rule, error) {
 	for i, rule := range r.rules {
 		debugf("State.match: for state %s trying rule %d /%s/\n", r.name, i, rule.pattern)
 		res, e := rule.match(text)
 		mylog.CheckIgnore(e)
 		if e != nil {
-			return nil, nil
+			return nil, &r.rules[i], e
 		}
 		if res != nil {
 			debugf("State.match: rule %d matched\n", i)
-			return res, &r.rules[i]
+			return res, &r.rules[i], nil
 		}
 	}
-	return nil, nil
+	return nil, nil, nil
 }
 
 func (s state) String() string {
@@ -86,6 +90,18 @@ type rule struct {
 	byGroups     []byGroupElement
 	include      string
 	useSelfState string
+	usingLexer   string
+	// origin identifies the rule in the lexer definition. It is kept when the rule is copied into other
+	// states by includes and combined states.
+	origin RuleRef
+}
+
+// patternString returns the rule's pattern as written in the lexer definition.
+func (r rule) patternString() string {
+	if r.pattern == nil {
+		return ""
+	}
+	return strings.TrimPrefix(r.pattern.String(), `\A`)
 }
 
 func (r rule) String() string {
@@ -104,6 +120,9 @@ func (r rule) String() string {
 	if r.useSelfState != "" {
 		fmt.Fprintf(&buf, "  usingself: %s", r.useSelfState)
 	}
+	if r.usingLexer != "" {
+		fmt.Fprintf(&buf, "  using: %s", r.usingLexer)
+	}
 	fmt.Fprintf(&buf, ")")
 	return buf.String()
 }
@@ -114,6 +133,12 @@ func (r rule) IsUseSelf() bool {
 	return r.useSelfState != ""
 }
 
+// IsUsing returns true if the Rule specifies that the matched text should be handled by lexing
+// it with a different lexer.
+func (r rule) IsUsing() bool {
+	return r.usingLexer != ""
+}
+
 // Match attempts to match the rule. If it succeeds it returns a slice
 // holding the index pairs identifying the
 // leftmost match of the regular expression in b and the matches, if any, of
@@ -130,6 +155,7 @@ func (r rule) match(text []rune) (*regexp2.Match, error) {
 type byGroupElement struct {
 	tok          TokenType
 	useSelfState string
+	usingLexer   string
 }
 
 // IsUseSelf returns true if the Rule specifies that the group should be handled by lexing
@@ -137,3 +163,9 @@ type byGroupElement struct {
 func (b byGroupElement) IsUseSelf() bool {
 	return b.useSelfState != ""
 }
+
+// IsUsing returns true if the Rule specifies that the group should be handled by lexing
+// the group text with a different lexer.
+func (b byGroupElement) IsUsing() bool {
+	return b.usingLexer != ""
+}
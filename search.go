@@ -0,0 +1,94 @@
+package syn
+
+import "github.com/dlclark/regexp2"
+
+// Scope selects the kinds of tokens that Search looks in. Scopes can be combined using |.
+type Scope int
+
+const (
+	// ScopeCode is the tokens that are not strings or comments, such as keywords, names and operators.
+	ScopeCode Scope = 1 << iota
+	// ScopeStrings is the String tokens, including docstrings.
+	ScopeStrings
+	// ScopeComments is the Comment tokens, including preprocessor directives.
+	ScopeComments
+
+	ScopeAll = ScopeCode | ScopeStrings | ScopeComments
+)
+
+func (s Scope) contains(t TokenType) bool {
+	switch {
+	case t.InCategory(Comment):
+		return s&ScopeComments != 0
+	case t.InSubCategory(LiteralString):
+		return s&ScopeStrings != 0
+	default:
+		return s&ScopeCode != 0
+	}
+}
+
+// Search lexes text and returns the ranges where needle occurs within the tokens selected by scope; see
+// the function Search.
+func (l *Lexer) Search(text []rune, needle string, scope Scope) ([]Range, error) {
+	tokens, lexErr := l.TokensAll(text)
+	if lexErr != nil {
+		return nil, lexErr
+	}
+	return Search(text, tokens, needle, scope), nil
+}
+
+// Search returns the ranges, in order, where needle occurs in text within the tokens selected by scope. For
+// example, with the scope ScopeStrings it finds only the occurrences of needle in string literals. tokens must
+// be the tokens produced by lexing text. A match may span several tokens, such as the parts of a string that
+// contains escapes, as long as all of those tokens are in the scope.
+func Search(text []rune, tokens []Token, needle string, scope Scope) []Range {
+	n := []rune(needle)
+	if len(n) == 0 {
+		return nil
+	}
+
+	var matches []Range
+	for _, run := range scopeRuns(tokens, scope) {
+		for i := run.Start; i+len(n) <= run.End; i++ {
+			if string(text[i:i+len(n)]) == needle {
+				matches = append(matches, Range{i, i + len(n)})
+				i += len(n) - 1
+			}
+		}
+	}
+	return matches
+}
+
+// SearchRegexp is like Search, but returns the ranges matched by the regular expression re. An error is
+// returned if matching fails, which happens only if re has a MatchTimeout and it was exceeded.
+func SearchRegexp(text []rune, tokens []Token, re *regexp2.Regexp, scope Scope) ([]Range, error) {
+	var matches []Range
+	for _, run := range scopeRuns(tokens, scope) {
+		m, matchErr := re.FindRunesMatch(text[run.Start:run.End])
+		for ; m != nil && matchErr == nil; m, matchErr = re.FindNextMatch(m) {
+			if m.Length > 0 {
+				matches = append(matches, Range{run.Start + m.Index, run.Start + m.Index + m.Length})
+			}
+		}
+		if matchErr != nil {
+			return matches, matchErr
+		}
+	}
+	return matches, nil
+}
+
+// scopeRuns returns the ranges of text covered by runs of adjacent tokens that are in scope.
+func scopeRuns(tokens []Token, scope Scope) []Range {
+	var runs []Range
+	for _, tok := range tokens {
+		if tok.Length() == 0 || !scope.contains(tok.Type) {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1].End == tok.Start {
+			runs[n-1].End = tok.End
+			continue
+		}
+		runs = append(runs, Range{tok.Start, tok.End})
+	}
+	return runs
+}
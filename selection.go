@@ -0,0 +1,123 @@
+package syn
+
+import "sort"
+
+// Range is a range of text, from Start up to but not including End.
+type Range struct {
+	Start, End int
+}
+
+func (r Range) contains(o Range) bool {
+	return r.Start <= o.Start && o.End <= r.End
+}
+
+// SelectionRanges lexes text and returns progressively larger ranges of it around offset, for use by an
+// editor's expand selection command; see the function SelectionRanges.
+func (l *Lexer) SelectionRanges(text []rune, offset int) ([]Range, error) {
+	tokens, lexErr := l.TokensAll(text)
+	if lexErr != nil {
+		return nil, lexErr
+	}
+	return SelectionRanges(text, tokens, offset), nil
+}
+
+// SelectionRanges returns progressively larger ranges of text around offset, each containing the one before
+// it: the token at offset, the whole of the string or comment the token is part of, the contents and then the
+// whole of each bracketed block that encloses it, and finally all of the text. tokens must be all of the
+// tokens produced by lexing text. Brackets are recognized only in Punctuation and Operator tokens, so brackets
+// within strings and comments are not counted.
+func SelectionRanges(text []rune, tokens []Token, offset int) []Range {
+	var ranges []Range
+	add := func(r Range) {
+		if len(ranges) == 0 || (r.contains(ranges[len(ranges)-1]) && r != ranges[len(ranges)-1]) {
+			ranges = append(ranges, r)
+		}
+	}
+
+	i := sort.Search(len(tokens), func(i int) bool {
+		return tokens[i].End > offset
+	})
+	cur := Range{offset, offset}
+	if i < len(tokens) && tokens[i].Start <= offset {
+		tok := tokens[i]
+		if !tok.Type.InCategory(Text) {
+			add(Range{tok.Start, tok.End})
+		}
+		if ext, ok := extent(tokens, i); ok {
+			add(ext)
+		}
+		cur = Range{tok.Start, tok.End}
+	}
+
+	for _, p := range bracketPairs(text, tokens) {
+		outer := Range{p.Start, p.End + 1}
+		if !outer.contains(cur) {
+			continue
+		}
+		if inner := (Range{p.Start + 1, p.End}); inner.contains(cur) {
+			add(inner)
+		}
+		add(outer)
+	}
+
+	add(Range{0, len(text)})
+	return ranges
+}
+
+// extent returns the range of the string or comment that the token at index i is part of, which may be made
+// of several tokens such as delimiters, escapes and interpolations.
+func extent(tokens []Token, i int) (Range, bool) {
+	var sameAs func(t TokenType) bool
+	switch t := tokens[i].Type; {
+	case t.InCategory(Comment):
+		sameAs = func(t TokenType) bool { return t.InCategory(Comment) }
+	case t.InSubCategory(LiteralString):
+		sameAs = func(t TokenType) bool { return t.InSubCategory(LiteralString) }
+	default:
+		return Range{}, false
+	}
+
+	first, last := i, i
+	for first > 0 && sameAs(tokens[first-1].Type) {
+		first--
+	}
+	for last < len(tokens)-1 && sameAs(tokens[last+1].Type) {
+		last++
+	}
+	return Range{tokens[first].Start, tokens[last].End}, true
+}
+
+var openingBrackets = map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+// bracketPairs returns the positions of the matching brackets in text, with Start being the position of the
+// opening bracket and End that of the closing bracket. The pairs are sorted from the innermost outwards.
+func bracketPairs(text []rune, tokens []Token) []Range {
+	type bracket struct {
+		c   rune
+		pos int
+	}
+
+	var pairs []Range
+	var open []bracket
+	for _, tok := range tokens {
+		if !tok.Type.InCategory(Punctuation) && !tok.Type.InCategory(Operator) {
+			continue
+		}
+		for j := tok.Start; j < tok.End; j++ {
+			switch c := text[j]; c {
+			case '(', '[', '{':
+				open = append(open, bracket{c, j})
+			case ')', ']', '}':
+				if len(open) > 0 && open[len(open)-1].c == openingBrackets[c] {
+					pairs = append(pairs, Range{open[len(open)-1].pos, j})
+					open = open[:len(open)-1]
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].End-pairs[i].Start < pairs[j].End-pairs[j].Start
+	})
+	return pairs
+}
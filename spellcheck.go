@@ -0,0 +1,65 @@
+package syn
+
+// Misspelling is a range of text that a spell checker reports as misspelled. It is the Meta of the tokens
+// split out by the filter returned by NewSpellCheckFilter.
+type Misspelling struct {
+	// Start and End are the offsets in the lexed text of the misspelled word.
+	Start, End int
+	// Suggestions optionally holds replacements for the word.
+	Suggestions []string
+}
+
+// SpellChecker checks the text of a token for misspellings. text is the value of the token and start is the
+// offset of the token in the lexed text. It returns the misspellings found, with offsets in the lexed text,
+// in order and not overlapping.
+type SpellChecker func(text []rune, start int) []Misspelling
+
+// NewSpellCheckFilter returns a Filter that passes the text of Comment and String tokens, including
+// docstrings, to check, and splits each misspelling check reports out into a token of its own. The misspelt
+// tokens keep the type of the token they were part of so that they are still colored the same, and have their
+// Meta set to the Misspelling so that a renderer can underline them. Preprocessor directives are not checked.
+func NewSpellCheckFilter(check SpellChecker) Filter {
+	return FilterFunc(func(tok Token) []Token {
+		if !spellChecked(tok.Type) || tok.Length() == 0 {
+			return []Token{tok}
+		}
+
+		var toks []Token
+		piece := func(start, end int, meta any) {
+			toks = append(toks, Token{
+				Type:  tok.Type,
+				Value: tok.Value[start-tok.Start : end-tok.Start],
+				Start: start,
+				End:   end,
+				Meta:  meta,
+			})
+		}
+
+		last := tok.Start
+		for _, m := range check(tok.Value, tok.Start) {
+			if m.Start < last || m.End > tok.End || m.Start >= m.End {
+				continue
+			}
+			if m.Start > last {
+				piece(last, m.Start, tok.Meta)
+			}
+			piece(m.Start, m.End, m)
+			last = m.End
+		}
+
+		if last == tok.Start {
+			return []Token{tok}
+		}
+		if last < tok.End {
+			piece(last, tok.End, tok.Meta)
+		}
+		return toks
+	})
+}
+
+func spellChecked(t TokenType) bool {
+	if t.InCategory(Comment) {
+		return !t.InSubCategory(CommentPreproc)
+	}
+	return t.InSubCategory(LiteralString)
+}
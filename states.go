@@ -0,0 +1,131 @@
+package syn
+
+import (
+	"sort"
+)
+
+// StateInfo describes a state of a compiled lexer, as returned by Lexer.States.
+type StateInfo struct {
+	Name string
+	// Combined is set for a state made by combining the rules of the states in Includes, for a rule with a
+	// combined element.
+	Combined bool
+	// Includes are the states whose rules are included in the state, in the order they are included, or for a
+	// combined state the states it combines.
+	Includes []string
+	// Rules are tried in order, those from included states in place of the include.
+	Rules []RuleInfo
+}
+
+// RuleInfo describes a rule of a compiled lexer.
+type RuleInfo struct {
+	// RuleRef identifies the rule where it is defined, which is another state if it was included.
+	RuleRef
+	// Pattern is the rule's pattern as written in the lexer definition.
+	Pattern string
+	// Token is the type of the token produced for the whole match, if the rule produces one.
+	Token TokenType
+	// Groups describe the tokens produced for the groups of the match by a bygroups rule.
+	Groups []GroupInfo
+	// Using is the name of the lexer the match is lexed with, and UsingSelf the state of this lexer the match is
+	// lexed from, if the rule lexes the match again.
+	Using     string
+	UsingSelf string
+	// Push is the state pushed after the rule matches, and Pop the number of states popped.
+	Push string
+	Pop  int
+}
+
+// GroupInfo describes what a bygroups rule produces for one group of the match: a token of type Token, or the
+// tokens of lexing the group with the lexer called Using or from the state UsingSelf of this lexer.
+type GroupInfo struct {
+	Token     TokenType
+	Using     string
+	UsingSelf string
+}
+
+// TokenTypes returns the types of the tokens the rule can produce itself, not counting those of lexing the match
+// again.
+func (r RuleInfo) TokenTypes() []TokenType {
+	var types []TokenType
+	if r.Token != 0 {
+		types = append(types, r.Token)
+	}
+	for _, g := range r.Groups {
+		if g.Token != 0 {
+			types = append(types, g.Token)
+		}
+	}
+	return types
+}
+
+// States describes the states of the compiled lexer, with the rules of included states in place, for
+// documentation and tools. The root state comes first, followed by the other states in the order they are
+// defined and then the combined states. Delegating and composite lexers have no states of their own, and nor
+// do the placeholders of lexers registered with RegisterLazy that haven't been built.
+func (l *Lexer) States() []StateInfo {
+	if l.config == nil || l.rules.rules == nil {
+		return nil
+	}
+
+	var names []string
+	includes := map[string][]string{}
+	for _, s := range l.config.Rules.States {
+		if s.Name == "root" {
+			names = append([]string{s.Name}, names...)
+		} else {
+			names = append(names, s.Name)
+		}
+		for _, r := range s.Rules {
+			if r.Include != nil {
+				includes[s.Name] = append(includes[s.Name], r.Include.State)
+			}
+		}
+	}
+	combined := map[string][]string{}
+	for _, s := range l.config.Rules.States {
+		for _, r := range s.Rules {
+			if r.Combined != nil {
+				combined[combinedStateName(r.Combined)] = r.Combined.States
+			}
+		}
+	}
+	var combinedNames []string
+	for name := range combined {
+		combinedNames = append(combinedNames, name)
+	}
+	sort.Strings(combinedNames)
+
+	var states []StateInfo
+	for _, name := range append(names, combinedNames...) {
+		st, ok := l.rules.Get(name)
+		if !ok {
+			continue
+		}
+		info := StateInfo{Name: name, Includes: includes[name]}
+		if c, ok := combined[name]; ok {
+			info.Combined, info.Includes = true, c
+		}
+		for _, r := range st.rules {
+			info.Rules = append(info.Rules, r.info())
+		}
+		states = append(states, info)
+	}
+	return states
+}
+
+func (r rule) info() RuleInfo {
+	info := RuleInfo{
+		RuleRef:   r.origin,
+		Pattern:   r.patternString(),
+		Token:     r.tok,
+		Using:     r.usingLexer,
+		UsingSelf: r.useSelfState,
+		Push:      r.pushState,
+		Pop:       r.popDepth,
+	}
+	for _, g := range r.byGroups {
+		info.Groups = append(info.Groups, GroupInfo{Token: g.tok, Using: g.usingLexer, UsingSelf: g.useSelfState})
+	}
+	return info
+}
@@ -0,0 +1,56 @@
+package syn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStates(t *testing.T) {
+	assert := assert.New(t)
+
+	lex := mylog.Check2(NewLexerFromXML(strings.NewReader(coverageLexer)))
+	states := lex.States()
+	assert.Equal([]string{"root", "words", "string"}, []string{states[0].Name, states[1].Name, states[2].Name})
+
+	root := states[0]
+	assert.Equal([]string{"words"}, root.Includes)
+	assert.False(root.Combined)
+	assert.Equal([]RuleInfo{
+		{RuleRef: RuleRef{"cover", "root", 0}, Pattern: `"`, Token: LiteralString, Push: "string"},
+		{RuleRef: RuleRef{"cover", "words", 0}, Pattern: `\d+`, Token: LiteralNumber},
+		{RuleRef: RuleRef{"cover", "words", 1}, Pattern: `\w+`, Token: Name},
+		{RuleRef: RuleRef{"cover", "root", 2}, Pattern: `\s+`, Token: Text},
+	}, root.Rules)
+	assert.Equal(RuleInfo{RuleRef: RuleRef{"cover", "string", 0}, Pattern: `"`, Token: LiteralString, Pop: 1}, states[2].Rules[0])
+
+	lex = mylog.Check2(NewLexerFromXML(strings.NewReader(`<lexer>
+  <config><name>comb</name></config>
+  <rules>
+    <state name="a"><rule pattern="a"><token type="Name"/></rule></state>
+    <state name="root">
+      <rule pattern="(x)(y)"><bygroups><token type="Keyword"/><usingself state="a"/></bygroups><combined state="a" state="b"/></rule>
+    </state>
+    <state name="b"><rule pattern="b"><token type="Text"/></rule></state>
+  </rules>
+</lexer>`)))
+	states = lex.States()
+	if assert.Len(states, 4) {
+		assert.Equal("root", states[0].Name)
+		assert.Equal(StateInfo{
+			Name:     "__combined_a__b",
+			Combined: true,
+			Includes: []string{"a", "b"},
+			Rules: []RuleInfo{
+				{RuleRef: RuleRef{"comb", "a", 0}, Pattern: "a", Token: Name},
+				{RuleRef: RuleRef{"comb", "b", 0}, Pattern: "b", Token: Text},
+			},
+		}, states[3])
+		rule := states[0].Rules[0]
+		assert.Equal([]GroupInfo{{Token: Keyword}, {UsingSelf: "a"}}, rule.Groups)
+		assert.Equal("__combined_a__b", rule.Push)
+		assert.Equal([]TokenType{Keyword}, rule.TokenTypes())
+	}
+}
@@ -7,6 +7,9 @@ type Token struct {
 	Type       TokenType
 	Value      []rune
 	Start, End int
+	// Meta holds additional information about the token added by a Filter, such as the nesting depth
+	// of a bracket. It is nil for the tokens produced by lexers.
+	Meta any
 }
 
 // String returns a textual description of the fields of the token. To get the text of the token use Value instead.
@@ -19,3 +22,9 @@ func (t Token) String() string {
 func (t Token) Length() int {
 	return t.End - t.Start
 }
+
+// ValueIn returns the value of the token as a slice of text, which must be the text that was lexed to produce
+// the token. It is used to get the value of tokens returned when the WithOffsetsOnly option is set. No copy is made.
+func (t Token) ValueIn(text []rune) []rune {
+	return text[t.Start:t.End]
+}
@@ -10,8 +10,8 @@ import (
 )
 
 const (
-	_TokenTypeName      = "NoneOtherErrorCodeLineLineLinkLineTableTDLineTableLineHighlightLineNumbersTableLineNumbersLinePreWrapperBackgroundEOFTypeKeywordKeywordConstantKeywordDeclarationKeywordNamespaceKeywordPseudoKeywordReservedKeywordTypeNameNameAttributeNameBuiltinNameBuiltinPseudoNameClassNameConstantNameDecoratorNameEntityNameExceptionNameFunctionNameFunctionMagicNameKeywordNameLabelNameNamespaceNameOperatorNameOtherNamePseudoNamePropertyNameTagNameVariableNameVariableAnonymousNameVariableClassNameVariableGlobalNameVariableInstanceNameVariableMagicLiteralLiteralDateLiteralOtherLiteralStringLiteralStringAffixLiteralStringAtomLiteralStringBacktickLiteralStringBooleanLiteralStringCharLiteralStringDelimiterLiteralStringDocLiteralStringDoubleLiteralStringEscapeLiteralStringHeredocLiteralStringInterpolLiteralStringNameLiteralStringOtherLiteralStringRegexLiteralStringSingleLiteralStringSymbolLiteralNumberLiteralNumberBinLiteralNumberFloatLiteralNumberHexLiteralNumberIntegerLiteralNumberIntegerLongLiteralNumberOctOperatorOperatorWordPunctuationCommentCommentHashbangCommentMultilineCommentSingleCommentSpecialCommentPreprocCommentPreprocFileGenericGenericDeletedGenericEmphGenericErrorGenericHeadingGenericInsertedGenericOutputGenericPromptGenericStrongGenericSubheadingGenericTracebackGenericUnderlineTextTextWhitespaceTextSymbolTextPunctuation"
-	_TokenTypeLowerName = "noneothererrorcodelinelinelinklinetabletdlinetablelinehighlightlinenumberstablelinenumberslineprewrapperbackgroundeoftypekeywordkeywordconstantkeyworddeclarationkeywordnamespacekeywordpseudokeywordreservedkeywordtypenamenameattributenamebuiltinnamebuiltinpseudonameclassnameconstantnamedecoratornameentitynameexceptionnamefunctionnamefunctionmagicnamekeywordnamelabelnamenamespacenameoperatornameothernamepseudonamepropertynametagnamevariablenamevariableanonymousnamevariableclassnamevariableglobalnamevariableinstancenamevariablemagicliteralliteraldateliteralotherliteralstringliteralstringaffixliteralstringatomliteralstringbacktickliteralstringbooleanliteralstringcharliteralstringdelimiterliteralstringdocliteralstringdoubleliteralstringescapeliteralstringheredocliteralstringinterpolliteralstringnameliteralstringotherliteralstringregexliteralstringsingleliteralstringsymbolliteralnumberliteralnumberbinliteralnumberfloatliteralnumberhexliteralnumberintegerliteralnumberintegerlongliteralnumberoctoperatoroperatorwordpunctuationcommentcommenthashbangcommentmultilinecommentsinglecommentspecialcommentpreproccommentpreprocfilegenericgenericdeletedgenericemphgenericerrorgenericheadinggenericinsertedgenericoutputgenericpromptgenericstronggenericsubheadinggenerictracebackgenericunderlinetexttextwhitespacetextsymboltextpunctuation"
+	_TokenTypeName      = "NoneOtherErrorCodeLineLineLinkLineTableTDLineTableLineHighlightLineNumbersTableLineNumbersLinePreWrapperBackgroundEOFTypeKeywordKeywordConstantKeywordDeclarationKeywordNamespaceKeywordPseudoKeywordReservedKeywordTypeNameNameAttributeNameBuiltinNameBuiltinPseudoNameClassNameConstantNameDecoratorNameEntityNameExceptionNameFunctionNameFunctionMagicNameKeywordNameLabelNameNamespaceNameOperatorNameOtherNamePseudoNamePropertyNameTagNameVariableNameVariableAnonymousNameVariableClassNameVariableGlobalNameVariableInstanceNameVariableMagicLiteralLiteralDateLiteralOtherLiteralColorLiteralStringLiteralStringAffixLiteralStringAtomLiteralStringBacktickLiteralStringBooleanLiteralStringCharLiteralStringDelimiterLiteralStringDocLiteralStringDoubleLiteralStringEscapeLiteralStringHeredocLiteralStringInterpolLiteralStringNameLiteralStringOtherLiteralStringRegexLiteralStringSingleLiteralStringSymbolLiteralStringFormatLiteralNumberLiteralNumberBinLiteralNumberFloatLiteralNumberHexLiteralNumberIntegerLiteralNumberIntegerLongLiteralNumberOctOperatorOperatorWordPunctuationCommentCommentHashbangCommentMultilineCommentSingleCommentSpecialCommentDocCommentPreprocCommentPreprocFileGenericGenericDeletedGenericEmphGenericErrorGenericHeadingGenericInsertedGenericOutputGenericPromptGenericStrongGenericSubheadingGenericTracebackGenericUnderlineTextTextWhitespaceTextSymbolTextPunctuationTextEOLTextLink"
+	_TokenTypeLowerName = "noneothererrorcodelinelinelinklinetabletdlinetablelinehighlightlinenumberstablelinenumberslineprewrapperbackgroundeoftypekeywordkeywordconstantkeyworddeclarationkeywordnamespacekeywordpseudokeywordreservedkeywordtypenamenameattributenamebuiltinnamebuiltinpseudonameclassnameconstantnamedecoratornameentitynameexceptionnamefunctionnamefunctionmagicnamekeywordnamelabelnamenamespacenameoperatornameothernamepseudonamepropertynametagnamevariablenamevariableanonymousnamevariableclassnamevariableglobalnamevariableinstancenamevariablemagicliteralliteraldateliteralotherliteralcolorliteralstringliteralstringaffixliteralstringatomliteralstringbacktickliteralstringbooleanliteralstringcharliteralstringdelimiterliteralstringdocliteralstringdoubleliteralstringescapeliteralstringheredocliteralstringinterpolliteralstringnameliteralstringotherliteralstringregexliteralstringsingleliteralstringsymbolliteralstringformatliteralnumberliteralnumberbinliteralnumberfloatliteralnumberhexliteralnumberintegerliteralnumberintegerlongliteralnumberoctoperatoroperatorwordpunctuationcommentcommenthashbangcommentmultilinecommentsinglecommentspecialcommentdoccommentpreproccommentpreprocfilegenericgenericdeletedgenericemphgenericerrorgenericheadinggenericinsertedgenericoutputgenericpromptgenericstronggenericsubheadinggenerictracebackgenericunderlinetexttextwhitespacetextsymboltextpunctuationtexteoltextlink"
 )
 
 var _TokenTypeMap = map[TokenType]string{
@@ -64,56 +64,61 @@ var _TokenTypeMap = map[TokenType]string{
 	3000: _TokenTypeName[535:542],
 	3001: _TokenTypeName[542:553],
 	3002: _TokenTypeName[553:565],
-	3100: _TokenTypeName[565:578],
-	3101: _TokenTypeName[578:596],
-	3102: _TokenTypeName[596:613],
-	3103: _TokenTypeName[613:634],
-	3104: _TokenTypeName[634:654],
-	3105: _TokenTypeName[654:671],
-	3106: _TokenTypeName[671:693],
-	3107: _TokenTypeName[693:709],
-	3108: _TokenTypeName[709:728],
-	3109: _TokenTypeName[728:747],
-	3110: _TokenTypeName[747:767],
-	3111: _TokenTypeName[767:788],
-	3112: _TokenTypeName[788:805],
-	3113: _TokenTypeName[805:823],
-	3114: _TokenTypeName[823:841],
-	3115: _TokenTypeName[841:860],
-	3116: _TokenTypeName[860:879],
-	3200: _TokenTypeName[879:892],
-	3201: _TokenTypeName[892:908],
-	3202: _TokenTypeName[908:926],
-	3203: _TokenTypeName[926:942],
-	3204: _TokenTypeName[942:962],
-	3205: _TokenTypeName[962:986],
-	3206: _TokenTypeName[986:1002],
-	4000: _TokenTypeName[1002:1010],
-	4001: _TokenTypeName[1010:1022],
-	5000: _TokenTypeName[1022:1033],
-	6000: _TokenTypeName[1033:1040],
-	6001: _TokenTypeName[1040:1055],
-	6002: _TokenTypeName[1055:1071],
-	6003: _TokenTypeName[1071:1084],
-	6004: _TokenTypeName[1084:1098],
-	6100: _TokenTypeName[1098:1112],
-	6101: _TokenTypeName[1112:1130],
-	7000: _TokenTypeName[1130:1137],
-	7001: _TokenTypeName[1137:1151],
-	7002: _TokenTypeName[1151:1162],
-	7003: _TokenTypeName[1162:1174],
-	7004: _TokenTypeName[1174:1188],
-	7005: _TokenTypeName[1188:1203],
-	7006: _TokenTypeName[1203:1216],
-	7007: _TokenTypeName[1216:1229],
-	7008: _TokenTypeName[1229:1242],
-	7009: _TokenTypeName[1242:1259],
-	7010: _TokenTypeName[1259:1275],
-	7011: _TokenTypeName[1275:1291],
-	8000: _TokenTypeName[1291:1295],
-	8001: _TokenTypeName[1295:1309],
-	8002: _TokenTypeName[1309:1319],
-	8003: _TokenTypeName[1319:1334],
+	3003: _TokenTypeName[565:577],
+	3100: _TokenTypeName[577:590],
+	3101: _TokenTypeName[590:608],
+	3102: _TokenTypeName[608:625],
+	3103: _TokenTypeName[625:646],
+	3104: _TokenTypeName[646:666],
+	3105: _TokenTypeName[666:683],
+	3106: _TokenTypeName[683:705],
+	3107: _TokenTypeName[705:721],
+	3108: _TokenTypeName[721:740],
+	3109: _TokenTypeName[740:759],
+	3110: _TokenTypeName[759:779],
+	3111: _TokenTypeName[779:800],
+	3112: _TokenTypeName[800:817],
+	3113: _TokenTypeName[817:835],
+	3114: _TokenTypeName[835:853],
+	3115: _TokenTypeName[853:872],
+	3116: _TokenTypeName[872:891],
+	3117: _TokenTypeName[891:910],
+	3200: _TokenTypeName[910:923],
+	3201: _TokenTypeName[923:939],
+	3202: _TokenTypeName[939:957],
+	3203: _TokenTypeName[957:973],
+	3204: _TokenTypeName[973:993],
+	3205: _TokenTypeName[993:1017],
+	3206: _TokenTypeName[1017:1033],
+	4000: _TokenTypeName[1033:1041],
+	4001: _TokenTypeName[1041:1053],
+	5000: _TokenTypeName[1053:1064],
+	6000: _TokenTypeName[1064:1071],
+	6001: _TokenTypeName[1071:1086],
+	6002: _TokenTypeName[1086:1102],
+	6003: _TokenTypeName[1102:1115],
+	6004: _TokenTypeName[1115:1129],
+	6005: _TokenTypeName[1129:1139],
+	6100: _TokenTypeName[1139:1153],
+	6101: _TokenTypeName[1153:1171],
+	7000: _TokenTypeName[1171:1178],
+	7001: _TokenTypeName[1178:1192],
+	7002: _TokenTypeName[1192:1203],
+	7003: _TokenTypeName[1203:1215],
+	7004: _TokenTypeName[1215:1229],
+	7005: _TokenTypeName[1229:1244],
+	7006: _TokenTypeName[1244:1257],
+	7007: _TokenTypeName[1257:1270],
+	7008: _TokenTypeName[1270:1283],
+	7009: _TokenTypeName[1283:1300],
+	7010: _TokenTypeName[1300:1316],
+	7011: _TokenTypeName[1316:1332],
+	8000: _TokenTypeName[1332:1336],
+	8001: _TokenTypeName[1336:1350],
+	8002: _TokenTypeName[1350:1360],
+	8003: _TokenTypeName[1360:1375],
+	8004: _TokenTypeName[1375:1382],
+	8005: _TokenTypeName[1382:1390],
 }
 
 func (i TokenType) String() string {
@@ -176,6 +181,7 @@ func _TokenTypeNoOp() {
 	_ = x[Literal-(3000)]
 	_ = x[LiteralDate-(3001)]
 	_ = x[LiteralOther-(3002)]
+	_ = x[LiteralColor-(3003)]
 	_ = x[LiteralString-(3100)]
 	_ = x[LiteralStringAffix-(3101)]
 	_ = x[LiteralStringAtom-(3102)]
@@ -193,6 +199,7 @@ func _TokenTypeNoOp() {
 	_ = x[LiteralStringRegex-(3114)]
 	_ = x[LiteralStringSingle-(3115)]
 	_ = x[LiteralStringSymbol-(3116)]
+	_ = x[LiteralStringFormat-(3117)]
 	_ = x[LiteralNumber-(3200)]
 	_ = x[LiteralNumberBin-(3201)]
 	_ = x[LiteralNumberFloat-(3202)]
@@ -208,6 +215,7 @@ func _TokenTypeNoOp() {
 	_ = x[CommentMultiline-(6002)]
 	_ = x[CommentSingle-(6003)]
 	_ = x[CommentSpecial-(6004)]
+	_ = x[CommentDoc-(6005)]
 	_ = x[CommentPreproc-(6100)]
 	_ = x[CommentPreprocFile-(6101)]
 	_ = x[Generic-(7000)]
@@ -226,9 +234,11 @@ func _TokenTypeNoOp() {
 	_ = x[TextWhitespace-(8001)]
 	_ = x[TextSymbol-(8002)]
 	_ = x[TextPunctuation-(8003)]
+	_ = x[TextEOL-(8004)]
+	_ = x[TextLink-(8005)]
 }
 
-var _TokenTypeValues = []TokenType{None, Other, Error, CodeLine, LineLink, LineTableTD, LineTable, LineHighlight, LineNumbersTable, LineNumbers, Line, PreWrapper, Background, EOFType, Keyword, KeywordConstant, KeywordDeclaration, KeywordNamespace, KeywordPseudo, KeywordReserved, KeywordType, Name, NameAttribute, NameBuiltin, NameBuiltinPseudo, NameClass, NameConstant, NameDecorator, NameEntity, NameException, NameFunction, NameFunctionMagic, NameKeyword, NameLabel, NameNamespace, NameOperator, NameOther, NamePseudo, NameProperty, NameTag, NameVariable, NameVariableAnonymous, NameVariableClass, NameVariableGlobal, NameVariableInstance, NameVariableMagic, Literal, LiteralDate, LiteralOther, LiteralString, LiteralStringAffix, LiteralStringAtom, LiteralStringBacktick, LiteralStringBoolean, LiteralStringChar, LiteralStringDelimiter, LiteralStringDoc, LiteralStringDouble, LiteralStringEscape, LiteralStringHeredoc, LiteralStringInterpol, LiteralStringName, LiteralStringOther, LiteralStringRegex, LiteralStringSingle, LiteralStringSymbol, LiteralNumber, LiteralNumberBin, LiteralNumberFloat, LiteralNumberHex, LiteralNumberInteger, LiteralNumberIntegerLong, LiteralNumberOct, Operator, OperatorWord, Punctuation, Comment, CommentHashbang, CommentMultiline, CommentSingle, CommentSpecial, CommentPreproc, CommentPreprocFile, Generic, GenericDeleted, GenericEmph, GenericError, GenericHeading, GenericInserted, GenericOutput, GenericPrompt, GenericStrong, GenericSubheading, GenericTraceback, GenericUnderline, Text, TextWhitespace, TextSymbol, TextPunctuation}
+var _TokenTypeValues = []TokenType{None, Other, Error, CodeLine, LineLink, LineTableTD, LineTable, LineHighlight, LineNumbersTable, LineNumbers, Line, PreWrapper, Background, EOFType, Keyword, KeywordConstant, KeywordDeclaration, KeywordNamespace, KeywordPseudo, KeywordReserved, KeywordType, Name, NameAttribute, NameBuiltin, NameBuiltinPseudo, NameClass, NameConstant, NameDecorator, NameEntity, NameException, NameFunction, NameFunctionMagic, NameKeyword, NameLabel, NameNamespace, NameOperator, NameOther, NamePseudo, NameProperty, NameTag, NameVariable, NameVariableAnonymous, NameVariableClass, NameVariableGlobal, NameVariableInstance, NameVariableMagic, Literal, LiteralDate, LiteralOther, LiteralColor, LiteralString, LiteralStringAffix, LiteralStringAtom, LiteralStringBacktick, LiteralStringBoolean, LiteralStringChar, LiteralStringDelimiter, LiteralStringDoc, LiteralStringDouble, LiteralStringEscape, LiteralStringHeredoc, LiteralStringInterpol, LiteralStringName, LiteralStringOther, LiteralStringRegex, LiteralStringSingle, LiteralStringSymbol, LiteralStringFormat, LiteralNumber, LiteralNumberBin, LiteralNumberFloat, LiteralNumberHex, LiteralNumberInteger, LiteralNumberIntegerLong, LiteralNumberOct, Operator, OperatorWord, Punctuation, Comment, CommentHashbang, CommentMultiline, CommentSingle, CommentSpecial, CommentDoc, CommentPreproc, CommentPreprocFile, Generic, GenericDeleted, GenericEmph, GenericError, GenericHeading, GenericInserted, GenericOutput, GenericPrompt, GenericStrong, GenericSubheading, GenericTraceback, GenericUnderline, Text, TextWhitespace, TextSymbol, TextPunctuation, TextEOL, TextLink}
 
 var _TokenTypeNameToValueMap = map[string]TokenType{
 	_TokenTypeName[0:4]:            None,
@@ -329,106 +339,116 @@ var _TokenTypeNameToValueMap = map[string]TokenType{
 	_TokenTypeLowerName[542:553]:   LiteralDate,
 	_TokenTypeName[553:565]:        LiteralOther,
 	_TokenTypeLowerName[553:565]:   LiteralOther,
-	_TokenTypeName[565:578]:        LiteralString,
-	_TokenTypeLowerName[565:578]:   LiteralString,
-	_TokenTypeName[578:596]:        LiteralStringAffix,
-	_TokenTypeLowerName[578:596]:   LiteralStringAffix,
-	_TokenTypeName[596:613]:        LiteralStringAtom,
-	_TokenTypeLowerName[596:613]:   LiteralStringAtom,
-	_TokenTypeName[613:634]:        LiteralStringBacktick,
-	_TokenTypeLowerName[613:634]:   LiteralStringBacktick,
-	_TokenTypeName[634:654]:        LiteralStringBoolean,
-	_TokenTypeLowerName[634:654]:   LiteralStringBoolean,
-	_TokenTypeName[654:671]:        LiteralStringChar,
-	_TokenTypeLowerName[654:671]:   LiteralStringChar,
-	_TokenTypeName[671:693]:        LiteralStringDelimiter,
-	_TokenTypeLowerName[671:693]:   LiteralStringDelimiter,
-	_TokenTypeName[693:709]:        LiteralStringDoc,
-	_TokenTypeLowerName[693:709]:   LiteralStringDoc,
-	_TokenTypeName[709:728]:        LiteralStringDouble,
-	_TokenTypeLowerName[709:728]:   LiteralStringDouble,
-	_TokenTypeName[728:747]:        LiteralStringEscape,
-	_TokenTypeLowerName[728:747]:   LiteralStringEscape,
-	_TokenTypeName[747:767]:        LiteralStringHeredoc,
-	_TokenTypeLowerName[747:767]:   LiteralStringHeredoc,
-	_TokenTypeName[767:788]:        LiteralStringInterpol,
-	_TokenTypeLowerName[767:788]:   LiteralStringInterpol,
-	_TokenTypeName[788:805]:        LiteralStringName,
-	_TokenTypeLowerName[788:805]:   LiteralStringName,
-	_TokenTypeName[805:823]:        LiteralStringOther,
-	_TokenTypeLowerName[805:823]:   LiteralStringOther,
-	_TokenTypeName[823:841]:        LiteralStringRegex,
-	_TokenTypeLowerName[823:841]:   LiteralStringRegex,
-	_TokenTypeName[841:860]:        LiteralStringSingle,
-	_TokenTypeLowerName[841:860]:   LiteralStringSingle,
-	_TokenTypeName[860:879]:        LiteralStringSymbol,
-	_TokenTypeLowerName[860:879]:   LiteralStringSymbol,
-	_TokenTypeName[879:892]:        LiteralNumber,
-	_TokenTypeLowerName[879:892]:   LiteralNumber,
-	_TokenTypeName[892:908]:        LiteralNumberBin,
-	_TokenTypeLowerName[892:908]:   LiteralNumberBin,
-	_TokenTypeName[908:926]:        LiteralNumberFloat,
-	_TokenTypeLowerName[908:926]:   LiteralNumberFloat,
-	_TokenTypeName[926:942]:        LiteralNumberHex,
-	_TokenTypeLowerName[926:942]:   LiteralNumberHex,
-	_TokenTypeName[942:962]:        LiteralNumberInteger,
-	_TokenTypeLowerName[942:962]:   LiteralNumberInteger,
-	_TokenTypeName[962:986]:        LiteralNumberIntegerLong,
-	_TokenTypeLowerName[962:986]:   LiteralNumberIntegerLong,
-	_TokenTypeName[986:1002]:       LiteralNumberOct,
-	_TokenTypeLowerName[986:1002]:  LiteralNumberOct,
-	_TokenTypeName[1002:1010]:      Operator,
-	_TokenTypeLowerName[1002:1010]: Operator,
-	_TokenTypeName[1010:1022]:      OperatorWord,
-	_TokenTypeLowerName[1010:1022]: OperatorWord,
-	_TokenTypeName[1022:1033]:      Punctuation,
-	_TokenTypeLowerName[1022:1033]: Punctuation,
-	_TokenTypeName[1033:1040]:      Comment,
-	_TokenTypeLowerName[1033:1040]: Comment,
-	_TokenTypeName[1040:1055]:      CommentHashbang,
-	_TokenTypeLowerName[1040:1055]: CommentHashbang,
-	_TokenTypeName[1055:1071]:      CommentMultiline,
-	_TokenTypeLowerName[1055:1071]: CommentMultiline,
-	_TokenTypeName[1071:1084]:      CommentSingle,
-	_TokenTypeLowerName[1071:1084]: CommentSingle,
-	_TokenTypeName[1084:1098]:      CommentSpecial,
-	_TokenTypeLowerName[1084:1098]: CommentSpecial,
-	_TokenTypeName[1098:1112]:      CommentPreproc,
-	_TokenTypeLowerName[1098:1112]: CommentPreproc,
-	_TokenTypeName[1112:1130]:      CommentPreprocFile,
-	_TokenTypeLowerName[1112:1130]: CommentPreprocFile,
-	_TokenTypeName[1130:1137]:      Generic,
-	_TokenTypeLowerName[1130:1137]: Generic,
-	_TokenTypeName[1137:1151]:      GenericDeleted,
-	_TokenTypeLowerName[1137:1151]: GenericDeleted,
-	_TokenTypeName[1151:1162]:      GenericEmph,
-	_TokenTypeLowerName[1151:1162]: GenericEmph,
-	_TokenTypeName[1162:1174]:      GenericError,
-	_TokenTypeLowerName[1162:1174]: GenericError,
-	_TokenTypeName[1174:1188]:      GenericHeading,
-	_TokenTypeLowerName[1174:1188]: GenericHeading,
-	_TokenTypeName[1188:1203]:      GenericInserted,
-	_TokenTypeLowerName[1188:1203]: GenericInserted,
-	_TokenTypeName[1203:1216]:      GenericOutput,
-	_TokenTypeLowerName[1203:1216]: GenericOutput,
-	_TokenTypeName[1216:1229]:      GenericPrompt,
-	_TokenTypeLowerName[1216:1229]: GenericPrompt,
-	_TokenTypeName[1229:1242]:      GenericStrong,
-	_TokenTypeLowerName[1229:1242]: GenericStrong,
-	_TokenTypeName[1242:1259]:      GenericSubheading,
-	_TokenTypeLowerName[1242:1259]: GenericSubheading,
-	_TokenTypeName[1259:1275]:      GenericTraceback,
-	_TokenTypeLowerName[1259:1275]: GenericTraceback,
-	_TokenTypeName[1275:1291]:      GenericUnderline,
-	_TokenTypeLowerName[1275:1291]: GenericUnderline,
-	_TokenTypeName[1291:1295]:      Text,
-	_TokenTypeLowerName[1291:1295]: Text,
-	_TokenTypeName[1295:1309]:      TextWhitespace,
-	_TokenTypeLowerName[1295:1309]: TextWhitespace,
-	_TokenTypeName[1309:1319]:      TextSymbol,
-	_TokenTypeLowerName[1309:1319]: TextSymbol,
-	_TokenTypeName[1319:1334]:      TextPunctuation,
-	_TokenTypeLowerName[1319:1334]: TextPunctuation,
+	_TokenTypeName[565:577]:        LiteralColor,
+	_TokenTypeLowerName[565:577]:   LiteralColor,
+	_TokenTypeName[577:590]:        LiteralString,
+	_TokenTypeLowerName[577:590]:   LiteralString,
+	_TokenTypeName[590:608]:        LiteralStringAffix,
+	_TokenTypeLowerName[590:608]:   LiteralStringAffix,
+	_TokenTypeName[608:625]:        LiteralStringAtom,
+	_TokenTypeLowerName[608:625]:   LiteralStringAtom,
+	_TokenTypeName[625:646]:        LiteralStringBacktick,
+	_TokenTypeLowerName[625:646]:   LiteralStringBacktick,
+	_TokenTypeName[646:666]:        LiteralStringBoolean,
+	_TokenTypeLowerName[646:666]:   LiteralStringBoolean,
+	_TokenTypeName[666:683]:        LiteralStringChar,
+	_TokenTypeLowerName[666:683]:   LiteralStringChar,
+	_TokenTypeName[683:705]:        LiteralStringDelimiter,
+	_TokenTypeLowerName[683:705]:   LiteralStringDelimiter,
+	_TokenTypeName[705:721]:        LiteralStringDoc,
+	_TokenTypeLowerName[705:721]:   LiteralStringDoc,
+	_TokenTypeName[721:740]:        LiteralStringDouble,
+	_TokenTypeLowerName[721:740]:   LiteralStringDouble,
+	_TokenTypeName[740:759]:        LiteralStringEscape,
+	_TokenTypeLowerName[740:759]:   LiteralStringEscape,
+	_TokenTypeName[759:779]:        LiteralStringHeredoc,
+	_TokenTypeLowerName[759:779]:   LiteralStringHeredoc,
+	_TokenTypeName[779:800]:        LiteralStringInterpol,
+	_TokenTypeLowerName[779:800]:   LiteralStringInterpol,
+	_TokenTypeName[800:817]:        LiteralStringName,
+	_TokenTypeLowerName[800:817]:   LiteralStringName,
+	_TokenTypeName[817:835]:        LiteralStringOther,
+	_TokenTypeLowerName[817:835]:   LiteralStringOther,
+	_TokenTypeName[835:853]:        LiteralStringRegex,
+	_TokenTypeLowerName[835:853]:   LiteralStringRegex,
+	_TokenTypeName[853:872]:        LiteralStringSingle,
+	_TokenTypeLowerName[853:872]:   LiteralStringSingle,
+	_TokenTypeName[872:891]:        LiteralStringSymbol,
+	_TokenTypeLowerName[872:891]:   LiteralStringSymbol,
+	_TokenTypeName[891:910]:        LiteralStringFormat,
+	_TokenTypeLowerName[891:910]:   LiteralStringFormat,
+	_TokenTypeName[910:923]:        LiteralNumber,
+	_TokenTypeLowerName[910:923]:   LiteralNumber,
+	_TokenTypeName[923:939]:        LiteralNumberBin,
+	_TokenTypeLowerName[923:939]:   LiteralNumberBin,
+	_TokenTypeName[939:957]:        LiteralNumberFloat,
+	_TokenTypeLowerName[939:957]:   LiteralNumberFloat,
+	_TokenTypeName[957:973]:        LiteralNumberHex,
+	_TokenTypeLowerName[957:973]:   LiteralNumberHex,
+	_TokenTypeName[973:993]:        LiteralNumberInteger,
+	_TokenTypeLowerName[973:993]:   LiteralNumberInteger,
+	_TokenTypeName[993:1017]:       LiteralNumberIntegerLong,
+	_TokenTypeLowerName[993:1017]:  LiteralNumberIntegerLong,
+	_TokenTypeName[1017:1033]:      LiteralNumberOct,
+	_TokenTypeLowerName[1017:1033]: LiteralNumberOct,
+	_TokenTypeName[1033:1041]:      Operator,
+	_TokenTypeLowerName[1033:1041]: Operator,
+	_TokenTypeName[1041:1053]:      OperatorWord,
+	_TokenTypeLowerName[1041:1053]: OperatorWord,
+	_TokenTypeName[1053:1064]:      Punctuation,
+	_TokenTypeLowerName[1053:1064]: Punctuation,
+	_TokenTypeName[1064:1071]:      Comment,
+	_TokenTypeLowerName[1064:1071]: Comment,
+	_TokenTypeName[1071:1086]:      CommentHashbang,
+	_TokenTypeLowerName[1071:1086]: CommentHashbang,
+	_TokenTypeName[1086:1102]:      CommentMultiline,
+	_TokenTypeLowerName[1086:1102]: CommentMultiline,
+	_TokenTypeName[1102:1115]:      CommentSingle,
+	_TokenTypeLowerName[1102:1115]: CommentSingle,
+	_TokenTypeName[1115:1129]:      CommentSpecial,
+	_TokenTypeLowerName[1115:1129]: CommentSpecial,
+	_TokenTypeName[1129:1139]:      CommentDoc,
+	_TokenTypeLowerName[1129:1139]: CommentDoc,
+	_TokenTypeName[1139:1153]:      CommentPreproc,
+	_TokenTypeLowerName[1139:1153]: CommentPreproc,
+	_TokenTypeName[1153:1171]:      CommentPreprocFile,
+	_TokenTypeLowerName[1153:1171]: CommentPreprocFile,
+	_TokenTypeName[1171:1178]:      Generic,
+	_TokenTypeLowerName[1171:1178]: Generic,
+	_TokenTypeName[1178:1192]:      GenericDeleted,
+	_TokenTypeLowerName[1178:1192]: GenericDeleted,
+	_TokenTypeName[1192:1203]:      GenericEmph,
+	_TokenTypeLowerName[1192:1203]: GenericEmph,
+	_TokenTypeName[1203:1215]:      GenericError,
+	_TokenTypeLowerName[1203:1215]: GenericError,
+	_TokenTypeName[1215:1229]:      GenericHeading,
+	_TokenTypeLowerName[1215:1229]: GenericHeading,
+	_TokenTypeName[1229:1244]:      GenericInserted,
+	_TokenTypeLowerName[1229:1244]: GenericInserted,
+	_TokenTypeName[1244:1257]:      GenericOutput,
+	_TokenTypeLowerName[1244:1257]: GenericOutput,
+	_TokenTypeName[1257:1270]:      GenericPrompt,
+	_TokenTypeLowerName[1257:1270]: GenericPrompt,
+	_TokenTypeName[1270:1283]:      GenericStrong,
+	_TokenTypeLowerName[1270:1283]: GenericStrong,
+	_TokenTypeName[1283:1300]:      GenericSubheading,
+	_TokenTypeLowerName[1283:1300]: GenericSubheading,
+	_TokenTypeName[1300:1316]:      GenericTraceback,
+	_TokenTypeLowerName[1300:1316]: GenericTraceback,
+	_TokenTypeName[1316:1332]:      GenericUnderline,
+	_TokenTypeLowerName[1316:1332]: GenericUnderline,
+	_TokenTypeName[1332:1336]:      Text,
+	_TokenTypeLowerName[1332:1336]: Text,
+	_TokenTypeName[1336:1350]:      TextWhitespace,
+	_TokenTypeLowerName[1336:1350]: TextWhitespace,
+	_TokenTypeName[1350:1360]:      TextSymbol,
+	_TokenTypeLowerName[1350:1360]: TextSymbol,
+	_TokenTypeName[1360:1375]:      TextPunctuation,
+	_TokenTypeLowerName[1360:1375]: TextPunctuation,
+	_TokenTypeName[1375:1382]:      TextEOL,
+	_TokenTypeLowerName[1375:1382]: TextEOL,
+	_TokenTypeName[1382:1390]:      TextLink,
+	_TokenTypeLowerName[1382:1390]: TextLink,
 }
 
 var _TokenTypeNames = []string{
@@ -481,56 +501,61 @@ var _TokenTypeNames = []string{
 	_TokenTypeName[535:542],
 	_TokenTypeName[542:553],
 	_TokenTypeName[553:565],
-	_TokenTypeName[565:578],
-	_TokenTypeName[578:596],
-	_TokenTypeName[596:613],
-	_TokenTypeName[613:634],
-	_TokenTypeName[634:654],
-	_TokenTypeName[654:671],
-	_TokenTypeName[671:693],
-	_TokenTypeName[693:709],
-	_TokenTypeName[709:728],
-	_TokenTypeName[728:747],
-	_TokenTypeName[747:767],
-	_TokenTypeName[767:788],
-	_TokenTypeName[788:805],
-	_TokenTypeName[805:823],
-	_TokenTypeName[823:841],
-	_TokenTypeName[841:860],
-	_TokenTypeName[860:879],
-	_TokenTypeName[879:892],
-	_TokenTypeName[892:908],
-	_TokenTypeName[908:926],
-	_TokenTypeName[926:942],
-	_TokenTypeName[942:962],
-	_TokenTypeName[962:986],
-	_TokenTypeName[986:1002],
-	_TokenTypeName[1002:1010],
-	_TokenTypeName[1010:1022],
-	_TokenTypeName[1022:1033],
-	_TokenTypeName[1033:1040],
-	_TokenTypeName[1040:1055],
-	_TokenTypeName[1055:1071],
-	_TokenTypeName[1071:1084],
-	_TokenTypeName[1084:1098],
-	_TokenTypeName[1098:1112],
-	_TokenTypeName[1112:1130],
-	_TokenTypeName[1130:1137],
-	_TokenTypeName[1137:1151],
-	_TokenTypeName[1151:1162],
-	_TokenTypeName[1162:1174],
-	_TokenTypeName[1174:1188],
-	_TokenTypeName[1188:1203],
-	_TokenTypeName[1203:1216],
-	_TokenTypeName[1216:1229],
-	_TokenTypeName[1229:1242],
-	_TokenTypeName[1242:1259],
-	_TokenTypeName[1259:1275],
-	_TokenTypeName[1275:1291],
-	_TokenTypeName[1291:1295],
-	_TokenTypeName[1295:1309],
-	_TokenTypeName[1309:1319],
-	_TokenTypeName[1319:1334],
+	_TokenTypeName[565:577],
+	_TokenTypeName[577:590],
+	_TokenTypeName[590:608],
+	_TokenTypeName[608:625],
+	_TokenTypeName[625:646],
+	_TokenTypeName[646:666],
+	_TokenTypeName[666:683],
+	_TokenTypeName[683:705],
+	_TokenTypeName[705:721],
+	_TokenTypeName[721:740],
+	_TokenTypeName[740:759],
+	_TokenTypeName[759:779],
+	_TokenTypeName[779:800],
+	_TokenTypeName[800:817],
+	_TokenTypeName[817:835],
+	_TokenTypeName[835:853],
+	_TokenTypeName[853:872],
+	_TokenTypeName[872:891],
+	_TokenTypeName[891:910],
+	_TokenTypeName[910:923],
+	_TokenTypeName[923:939],
+	_TokenTypeName[939:957],
+	_TokenTypeName[957:973],
+	_TokenTypeName[973:993],
+	_TokenTypeName[993:1017],
+	_TokenTypeName[1017:1033],
+	_TokenTypeName[1033:1041],
+	_TokenTypeName[1041:1053],
+	_TokenTypeName[1053:1064],
+	_TokenTypeName[1064:1071],
+	_TokenTypeName[1071:1086],
+	_TokenTypeName[1086:1102],
+	_TokenTypeName[1102:1115],
+	_TokenTypeName[1115:1129],
+	_TokenTypeName[1129:1139],
+	_TokenTypeName[1139:1153],
+	_TokenTypeName[1153:1171],
+	_TokenTypeName[1171:1178],
+	_TokenTypeName[1178:1192],
+	_TokenTypeName[1192:1203],
+	_TokenTypeName[1203:1215],
+	_TokenTypeName[1215:1229],
+	_TokenTypeName[1229:1244],
+	_TokenTypeName[1244:1257],
+	_TokenTypeName[1257:1270],
+	_TokenTypeName[1270:1283],
+	_TokenTypeName[1283:1300],
+	_TokenTypeName[1300:1316],
+	_TokenTypeName[1316:1332],
+	_TokenTypeName[1332:1336],
+	_TokenTypeName[1336:1350],
+	_TokenTypeName[1350:1360],
+	_TokenTypeName[1360:1375],
+	_TokenTypeName[1375:1382],
+	_TokenTypeName[1382:1390],
 }
 
 // TokenTypeString retrieves an enum value from the enum constants string name.
@@ -0,0 +1,250 @@
+// Package tui renders the tokens produced by syn lexers as lines of text styled with lipgloss
+// (github.com/charmbracelet/lipgloss), for terminal user interfaces such as those built with Bubble Tea.
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/jeffwilliams/syn"
+)
+
+// Style maps token types to the lipgloss styles used to render the tokens.
+type Style struct {
+	// Default is used for tokens whose type, and the parents of whose type, are not in Tokens.
+	Default lipgloss.Style
+	Tokens  map[syn.TokenType]lipgloss.Style
+}
+
+// Get returns the lipgloss style for tokens of type t. If t is not in s.Tokens, the style of its closest
+// parent type that is is returned, or Default if none are.
+func (s Style) Get(t syn.TokenType) lipgloss.Style {
+	for {
+		if ls, ok := s.Tokens[t]; ok {
+			return ls
+		}
+		if t == 0 {
+			return s.Default
+		}
+		t = t.Parent()
+	}
+}
+
+func fg(c string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+}
+
+// DefaultStyle is a style using the 256 colour palette that can be used when the application doesn't provide
+// its own.
+var DefaultStyle = Style{
+	Default: lipgloss.NewStyle(),
+	Tokens: map[syn.TokenType]lipgloss.Style{
+		syn.Error:           fg("196"),
+		syn.Keyword:         fg("204").Bold(true),
+		syn.KeywordType:     fg("141"),
+		syn.NameBuiltin:     fg("81"),
+		syn.NameClass:       fg("141"),
+		syn.NameFunction:    fg("149"),
+		syn.NameTag:         fg("204"),
+		syn.NameAttribute:   fg("149"),
+		syn.NameDecorator:   fg("149"),
+		syn.LiteralString:   fg("186"),
+		syn.LiteralNumber:   fg("141"),
+		syn.Operator:        fg("204"),
+		syn.Comment:         fg("243").Italic(true),
+		syn.CommentPreproc:  fg("204"),
+		syn.GenericDeleted:  fg("196"),
+		syn.GenericInserted: fg("149"),
+		syn.GenericHeading:  fg("81").Bold(true),
+		syn.GenericEmph:     lipgloss.NewStyle().Italic(true),
+		syn.GenericStrong:   lipgloss.NewStyle().Bold(true),
+	},
+}
+
+// Lines renders tokens, which were produced by lexing text, as one styled string per line of text. The line
+// endings are not included in the strings. Tokens need not have their Value set, so tokens produced using the
+// option syn.WithOffsetsOnly can be used.
+func Lines(text []rune, tokens []syn.Token, style Style) []string {
+	var lines []string
+	var line strings.Builder
+	for _, tok := range tokens {
+		value := tok.ValueIn(text)
+		for {
+			i := indexRune(value, '\n')
+			if i < 0 {
+				renderTo(&line, value, tok.Type, style)
+				break
+			}
+			renderTo(&line, value[:i], tok.Type, style)
+			lines = append(lines, line.String())
+			line.Reset()
+			value = value[i+1:]
+		}
+	}
+	if line.Len() > 0 || len(lines) == 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+func indexRune(s []rune, r rune) int {
+	for i, c := range s {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func renderTo(b *strings.Builder, value []rune, t syn.TokenType, style Style) {
+	value = trimCR(value)
+	if len(value) == 0 {
+		return
+	}
+	b.WriteString(style.Get(t).Render(string(value)))
+}
+
+// trimCR removes a \r that is the end of a \r\n line ending.
+func trimCR(value []rune) []rune {
+	if len(value) > 0 && value[len(value)-1] == '\r' {
+		return value[:len(value)-1]
+	}
+	return value
+}
+
+// Renderer renders text highlighted using a syn Lexer a line at a time. The text is lexed only as far as is
+// needed for the lines requested, and lexing continues from where it stopped on later calls, so that a pager
+// or editor can display the first screen of a long text without lexing all of it. Changing the text with
+// SetText starts lexing again.
+type Renderer struct {
+	Lexer *syn.Lexer
+	Style Style
+
+	text []rune
+	it   syn.Iterator
+	// lines holds the tokens of each of the complete lines lexed so far.
+	lines [][]syn.Token
+	// line holds the tokens lexed so far of the line after the last complete line.
+	line []syn.Token
+	// end is the index in text of the rune after the last token lexed.
+	end  int
+	done bool
+	err  error
+}
+
+// NewRenderer returns a Renderer that lexes using lexer and uses the style DefaultStyle.
+func NewRenderer(lexer *syn.Lexer) *Renderer {
+	return &Renderer{
+		Lexer: lexer,
+		Style: DefaultStyle,
+	}
+}
+
+// SetText sets the text to be rendered. If it is unchanged the tokens already produced are kept.
+func (r *Renderer) SetText(text []rune) {
+	if r.it != nil && string(text) == string(r.text) {
+		return
+	}
+	r.text = text
+	r.it = nil
+	r.lines = nil
+	r.line = nil
+	r.end = 0
+	r.done = false
+	r.err = nil
+}
+
+// Err returns the error that lexing the text failed with, if any. The lines after the point where lexing
+// stopped are rendered unstyled.
+func (r *Renderer) Err() error {
+	return r.err
+}
+
+// lexLines lexes until at least n lines are complete or the end of the text is reached.
+func (r *Renderer) lexLines(n int) {
+	if r.it == nil {
+		r.it = r.Lexer.Tokenise(r.text, syn.WithEOLTokens(true), syn.WithOffsetsOnly(true))
+	}
+
+	for !r.done && len(r.lines) < n {
+		tok, nextErr := r.it.Next()
+		if nextErr != nil {
+			r.stop(nextErr)
+			break
+		}
+		if tok.Type == syn.EOFType {
+			r.done = true
+			if len(r.line) > 0 {
+				r.lines = append(r.lines, r.line)
+				r.line = nil
+			}
+			break
+		}
+
+		r.end = tok.End
+		if tok.Type == syn.TextEOL {
+			r.lines = append(r.lines, r.line)
+			r.line = nil
+			continue
+		}
+		r.line = append(r.line, tok)
+	}
+}
+
+// stop stops lexing after the error err, adding the rest of the text as Text tokens.
+func (r *Renderer) stop(err error) {
+	r.err = err
+	r.done = true
+
+	start := r.end
+	for {
+		i := indexRune(r.text[start:], '\n')
+		if i < 0 {
+			break
+		}
+		r.lines = append(r.lines, append(r.line, syn.Token{Type: syn.Text, Start: start, End: start + i}))
+		r.line = nil
+		start += i + 1
+	}
+	if start < len(r.text) {
+		r.line = append(r.line, syn.Token{Type: syn.Text, Start: start, End: len(r.text)})
+	}
+	if len(r.line) > 0 {
+		r.lines = append(r.lines, r.line)
+		r.line = nil
+	}
+}
+
+// LineCount returns the number of lines in the text. This requires lexing all of the text.
+func (r *Renderer) LineCount() int {
+	r.lexLines(len(r.text) + 1)
+	return len(r.lines)
+}
+
+// Lines returns the styled strings for at most n lines starting with the line first, which is zero-based.
+func (r *Renderer) Lines(first, n int) []string {
+	r.lexLines(first + n)
+
+	if first >= len(r.lines) {
+		return nil
+	}
+	end := min(first+n, len(r.lines))
+
+	out := make([]string, 0, end-first)
+	for _, toks := range r.lines[first:end] {
+		var line strings.Builder
+		for _, tok := range toks {
+			renderTo(&line, tok.ValueIn(r.text), tok.Type, r.Style)
+		}
+		out = append(out, line.String())
+	}
+	return out
+}
+
+// View returns the lines visible in a viewport of height lines that is scrolled down by yOffset lines, joined
+// by newlines. It can be returned from the View method of a Bubble Tea model, or passed to the SetContent
+// method of a viewport.
+func (r *Renderer) View(yOffset, height int) string {
+	return strings.Join(r.Lines(yOffset, height), "\n")
+}
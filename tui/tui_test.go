@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jeffwilliams/syn"
+)
+
+func TestRenderer(t *testing.T) {
+	prog := []rune("int x;\r\n/* a\nb */\n\nreturn;")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(syn.NewLexerFromXMLFile("../lexers/embedded/c.xml"))
+
+	r := NewRenderer(lex)
+	r.Style = Style{}
+	r.SetText(prog)
+
+	assert.Equal([]string{"/* a", "b */"}, r.Lines(1, 2))
+	assert.Equal("int x;\n/* a", r.View(0, 2))
+	assert.Equal(5, r.LineCount())
+	assert.Equal([]string{"", "return;"}, r.Lines(3, 10))
+	assert.Nil(r.Lines(5, 1))
+
+	tokens := mylog.Check2(lex.TokensAll(prog))
+	assert.Equal(r.Lines(0, 5), Lines(prog, tokens, Style{}))
+}
+
+const brokenLexer = `<lexer>
+  <config>
+    <name>broken</name>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern="\(">
+        <token type="Punctuation"/>
+        <push state="missing"/>
+      </rule>
+      <rule pattern="\w+">
+        <token type="Keyword"/>
+      </rule>
+      <rule pattern="\s+">
+        <token type="Text"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>`
+
+func TestRendererError(t *testing.T) {
+	prog := []rune("a b\nc (d\n\ne")
+
+	assert := assert.New(t)
+
+	lex := mylog.Check2(syn.NewLexerFromXML(strings.NewReader(brokenLexer)))
+
+	r := NewRenderer(lex)
+	r.Style = Style{}
+	r.SetText(prog)
+
+	assert.Equal([]string{"a b"}, r.Lines(0, 1))
+	assert.NoError(r.Err())
+	assert.Equal([]string{"c (d", "", "e"}, r.Lines(1, 10))
+	assert.EqualError(r.Err(), "syn.iterator: a rule refers to a state missing that doesn't exist")
+	assert.Equal(4, r.LineCount())
+
+	r.SetText([]rune("a b"))
+	assert.Equal(1, r.LineCount())
+	assert.NoError(r.Err())
+}
@@ -88,6 +88,8 @@ const (
 	Literal TokenType = 3000 + iota
 	LiteralDate
 	LiteralOther
+	// LiteralColor is the type of the color literals found by the filter returned by NewColorFilter.
+	LiteralColor
 )
 
 // Strings.
@@ -109,6 +111,9 @@ const (
 	LiteralStringRegex
 	LiteralStringSingle
 	LiteralStringSymbol
+	// LiteralStringFormat is the type of the format directives, such as %d, found by the filter returned by
+	// NewEscapeFilter.
+	LiteralStringFormat
 )
 
 // Literals.
@@ -140,6 +145,9 @@ const (
 	CommentMultiline
 	CommentSingle
 	CommentSpecial
+	// CommentDoc is the type of documentation comments, such as /** ... */ in Java and /// in Rust.
+	// Docstrings, which are strings rather than comments, have the type LiteralStringDoc.
+	CommentDoc
 )
 
 // Preprocessor "comments".
@@ -170,6 +178,11 @@ const (
 	TextWhitespace
 	TextSymbol
 	TextPunctuation
+	// TextEOL is the type of the end-of-line tokens emitted when the WithEOLTokens option is set.
+	TextEOL
+	// TextLink is the type of the URLs and email addresses split out of comments and strings by the filter
+	// returned by NewLinkFilter.
+	TextLink
 )
 
 // Aliases.
@@ -192,6 +205,7 @@ const (
 	StringRegex     = LiteralStringRegex
 	StringSingle    = LiteralStringSingle
 	StringSymbol    = LiteralStringSymbol
+	StringFormat    = LiteralStringFormat
 
 	Number            = LiteralNumber
 	NumberBin         = LiteralNumberBin
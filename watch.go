@@ -0,0 +1,180 @@
+package syn
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/jeffwilliams/syn/internal/config"
+)
+
+// reloadDelay is how long a Watcher waits after a definition changes before reloading it, so that the
+// several events produced by an editor saving a file cause a single reload.
+const reloadDelay = 100 * time.Millisecond
+
+// Watcher watches a directory of XML lexer definitions and reloads them into a LexerRegistry as they
+// change. It is created by LexerRegistry.Watch.
+type Watcher struct {
+	registry *LexerRegistry
+	watcher  *fsnotify.Watcher
+	onReload func(path string, lexer *Lexer, err error)
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+	closed bool
+	// reloading counts the reloads started by timers that haven't finished.
+	reloading sync.WaitGroup
+	done      chan struct{}
+}
+
+// Watch loads the XML lexer definitions (files ending in .xml) in dir into the registry and then watches dir,
+// reloading each definition when it is created or changed. This lets grammar authors see their changes in a
+// running editor without restarting it.
+//
+// A loaded lexer replaces the lexer in the registry with the same name, if there is one, and has the runtime
+// options, such as filters, of the lexer it replaces. Later calls to Get and Match return the new lexer; lexers
+// already obtained from the registry are not changed, so a program should get the lexer again when onReload is
+// called. If a definition can't be loaded, the lexer it would have replaced is kept. Removing a definition does
+// not remove its lexer.
+//
+// onReload, if not nil, is called after each definition is loaded, with the new lexer or the error that
+// prevented it being loaded. It is called from the Watcher's goroutine.
+func (l *LexerRegistry) Watch(dir string, onReload func(path string, lexer *Lexer, err error)) (*Watcher, error) {
+	fw, watchErr := fsnotify.NewWatcher()
+	if watchErr != nil {
+		return nil, watchErr
+	}
+
+	w := &Watcher{
+		registry: l,
+		watcher:  fw,
+		onReload: onReload,
+		timers:   map[string]*time.Timer{},
+		done:     make(chan struct{}),
+	}
+	if addErr := fw.Add(dir); addErr != nil {
+		fw.Close()
+		return nil, addErr
+	}
+
+	paths, globErr := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if globErr != nil {
+		fw.Close()
+		return nil, globErr
+	}
+	for _, path := range paths {
+		w.reload(path)
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching for changes. onReload isn't called after Close returns.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	for _, t := range w.timers {
+		t.Stop()
+	}
+	w.timers = nil
+	w.mu.Unlock()
+
+	closeErr := w.watcher.Close()
+	<-w.done
+	w.reloading.Wait()
+	return closeErr
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	for {
+		select {
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if strings.HasSuffix(ev.Name, ".xml") && ev.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+				w.schedule(ev.Name)
+			}
+		case watchErr, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			if w.onReload != nil {
+				w.onReload("", nil, watchErr)
+			}
+		}
+	}
+}
+
+// schedule reloads the definition at path after reloadDelay, unless it changes again before then.
+func (w *Watcher) schedule(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+	if t := w.timers[path]; t != nil {
+		t.Reset(reloadDelay)
+		return
+	}
+	w.timers[path] = time.AfterFunc(reloadDelay, func() {
+		w.mu.Lock()
+		if w.closed {
+			w.mu.Unlock()
+			return
+		}
+		delete(w.timers, path)
+		w.reloading.Add(1)
+		w.mu.Unlock()
+
+		defer w.reloading.Done()
+		w.reload(path)
+	})
+}
+
+func (w *Watcher) reload(path string) {
+	lexer, loadErr := w.load(path)
+	if loadErr == nil {
+		w.registry.replace(lexer)
+	}
+	if w.onReload != nil {
+		w.onReload(path, lexer, loadErr)
+	}
+}
+
+// load builds the lexer defined at path, with the runtime options of the lexer of the same name in the registry.
+func (w *Watcher) load(path string) (lexer *Lexer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			lexer, err = nil, fmt.Errorf("syn: loading %s failed: %v", path, r)
+		}
+	}()
+
+	f, openErr := os.Open(path)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer f.Close()
+	cfg, decodeErr := config.DecodeLexer(f)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	if globErr := checkFilenames(cfg.Config); globErr != nil {
+		return nil, globErr
+	}
+
+	bld := newLexerBuilder(cfg)
+	if old := w.registry.lookup(cfg.Config.Name); old != nil && old.delegation == nil && old.composition == nil {
+		bld.lexer.matchTimeout = old.matchTimeout
+		bld.lexer.iterOpts = old.iterOpts
+	}
+	return bld.Build()
+}
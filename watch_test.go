@@ -0,0 +1,121 @@
+package syn
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ddkwork/golibrary/mylog"
+	"github.com/stretchr/testify/assert"
+)
+
+const watchTestLexer = `<lexer>
+  <config>
+    <name>Watched</name>
+    <filename>*.watched</filename>
+  </config>
+  <rules>
+    <state name="root">
+      <rule pattern="\w+">
+        <token type="%s"/>
+      </rule>
+      <rule pattern="\s+">
+        <token type="Text"/>
+      </rule>
+    </state>
+  </rules>
+</lexer>
+`
+
+func TestWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "watched.xml")
+	write := func(typ string) {
+		mylog.Check(os.WriteFile(path, []byte(fmt.Sprintf(watchTestLexer, typ)), 0o644))
+	}
+	write("Name")
+
+	reloads := make(chan error, 10)
+	reg := NewLexerRegistry()
+	reg.Register(mylog.Check2(NewLexerFromXMLFile(path)).With(WithFilters(NewNumberFilter())))
+	w := mylog.Check2(reg.Watch(dir, func(path string, lexer *Lexer, err error) {
+		reloads <- err
+	}))
+	defer w.Close()
+
+	assert.NoError(<-reloads)
+	assert.Same(reg.Get("Watched"), reg.Match("a.watched"))
+
+	tokens := mylog.Check2(reg.Get("Watched").TokensAll([]rune("word")))
+	assert.Equal([]Token{{Type: Name, Value: []rune("word"), Start: 0, End: 4}}, tokens)
+
+	write("Keyword")
+	select {
+	case err := <-reloads:
+		assert.NoError(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the definition was not reloaded")
+	}
+
+	tokens = mylog.Check2(reg.Get("Watched").TokensAll([]rune("word")))
+	assert.Equal([]Token{{Type: Keyword, Value: []rune("word"), Start: 0, End: 4}}, tokens)
+	assert.Equal([]string{"Watched"}, reg.Names(false))
+	assert.NotEmpty(reg.Get("Watched").iterOpts.filters, "the runtime options of the replaced lexer are kept")
+
+	// A definition that doesn't load leaves the previous lexer in place.
+	mylog.Check(os.WriteFile(path, []byte("<lexer>"), 0o644))
+	select {
+	case err := <-reloads:
+		assert.Error(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the definition was not reloaded")
+	}
+	assert.NotNil(reg.Get("Watched"))
+}
+
+func TestMalformedFilenameGlob(t *testing.T) {
+	assert := assert.New(t)
+
+	bad := strings.Replace(fmt.Sprintf(watchTestLexer, "Name"), "*.watched", "*.[ch", 1)
+	_, buildErr := NewLexerFromXML(strings.NewReader(bad))
+	assert.ErrorContains(buildErr, `malformed filename glob "*.[ch"`)
+
+	// Lexers registered without being built, such as by RegisterLazy, may still have one; they match nothing and
+	// the registry stays usable.
+	reg := NewLexerRegistry()
+	lexer := mylog.Check2(NewLexerFromXML(strings.NewReader(fmt.Sprintf(watchTestLexer, "Name"))))
+	lexer.config.Config.Filenames = []string{"*.[ch"}
+	reg.Register(lexer)
+	assert.Nil(reg.Match("a.c"))
+	reg.Register(mylog.Check2(NewLexerFromXMLFile("lexers/embedded/c.xml")))
+	assert.NotNil(reg.Get("C"))
+
+	// The Watcher reports the error and keeps the lexer it has.
+	dir := t.TempDir()
+	mylog.Check(os.WriteFile(filepath.Join(dir, "watched.xml"), []byte(bad), 0o644))
+	reloads := make(chan error, 10)
+	w := mylog.Check2(reg.Watch(dir, func(path string, lexer *Lexer, err error) {
+		reloads <- err
+	}))
+	assert.ErrorContains(<-reloads, "malformed filename glob")
+	assert.NoError(w.Close())
+	assert.Same(lexer, reg.Get("Watched"))
+}
+
+func TestWatchBadDir(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := filepath.Join(t.TempDir(), "a[b")
+	mylog.Check(os.Mkdir(dir, 0o755))
+	w, watchErr := NewLexerRegistry().Watch(dir, nil)
+	assert.Nil(w)
+	assert.ErrorIs(watchErr, filepath.ErrBadPattern)
+
+	_, watchErr = NewLexerRegistry().Watch(filepath.Join(t.TempDir(), "missing"), nil)
+	assert.Error(watchErr)
+}